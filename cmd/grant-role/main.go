@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"beautyessentials.com/internal/bootstrap"
+	"beautyessentials.com/internal/constant"
+	"beautyessentials.com/internal/service/interfaces"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// grant-role assigns a role to an existing user, e.g. to promote the first administrator
+func main() {
+	email := flag.String("email", "", "email of the user to grant the role to")
+	role := flag.String("role", constant.RoleAdmin, "name of the role to grant")
+	flag.Parse()
+
+	if *email == "" {
+		log.Fatal("The -email flag is required")
+	}
+
+	// Build only the dependencies needed to talk to the database
+	var roleService interfaces.RoleService
+	var db *gorm.DB
+	app := fx.New(
+		bootstrap.CoreModule,
+		fx.Populate(&roleService, &db),
+		fx.NopLogger,
+	)
+	if err := app.Err(); err != nil {
+		log.Fatalf("Failed to initialize: %v", err)
+	}
+
+	err := roleService.GrantRoleByEmail(context.Background(), *email, *role)
+
+	// Close database connection
+	if sqlDB, dbErr := db.DB(); dbErr == nil {
+		_ = sqlDB.Close()
+	}
+
+	if err != nil {
+		log.Fatalf("Failed to grant role: %v", err)
+	}
+
+	log.Printf("Granted role %q to %s", *role, *email)
+}
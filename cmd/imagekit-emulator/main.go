@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"beautyessentials.com/internal/emulator/imagekit"
+)
+
+// imagekit-emulator serves a local, disk-backed ImageKit API for development
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	dir := flag.String("dir", "./storage/imagekit", "directory files are stored in")
+	privateKey := flag.String("private-key", "", "private key required on API calls (empty disables the check)")
+	publicURL := flag.String("public-url", "", "externally reachable base URL (defaults to http://localhost<addr>)")
+	flag.Parse()
+
+	if *publicURL == "" {
+		host := *addr
+		if strings.HasPrefix(host, ":") {
+			host = "localhost" + host
+		}
+		*publicURL = "http://" + host
+	}
+
+	server, err := imagekit.NewServer(*dir, imagekit.Options{
+		PublicURL:  *publicURL,
+		PrivateKey: *privateKey,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize emulator: %v", err)
+	}
+
+	log.Printf("ImageKit emulator listening on %s", *addr)
+	log.Printf("Set IMAGEKIT_UPLOAD_BASE_URL=%s IMAGEKIT_API_BASE_URL=%s IMAGEKIT_URL_ENDPOINT=%s",
+		*publicURL, *publicURL, server.URLEndpoint())
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		log.Fatalf("Emulator stopped: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+// Command openapi writes the OpenAPI document of the API and verifies that it
+// documents exactly the routes the router registers.
+//
+// Usage:
+//
+//	go run ./cmd/openapi -check            # fail when routes and spec drift apart
+//	go run ./cmd/openapi -out openapi.json # write the document
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"beautyessentials.com/internal/api/handlers"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/router"
+	"beautyessentials.com/internal/utils/ratelimit"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func main() {
+	check := flag.Bool("check", false, "Exit with an error when registered routes and the spec differ")
+	out := flag.String("out", "", "Write the document to this file instead of stdout")
+	flag.Parse()
+
+	if *check {
+		// Handlers are only referenced while registering routes, so zero values suffice
+		gin.SetMode(gin.ReleaseMode)
+		engine := router.NewRouter(
+			responses.NewResponseHelper(),
+			&handlers.HealthHandler{},
+			&handlers.BrandHandler{},
+			&handlers.CategoryHandler{},
+			&handlers.MediaHandler{},
+			&handlers.MediaFolderHandler{},
+			&handlers.AuthHandler{},
+			&handlers.RoleHandler{},
+			&handlers.ApiKeyHandler{},
+			nil,
+			nil,
+			nil,
+			ratelimit.NewMemoryStore(),
+			&config.Config{},
+			zap.NewNop(),
+		)
+		if err := router.CheckAPIDocument(engine); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("OpenAPI spec documents every route")
+		return
+	}
+
+	// The document marks the routes deprecated by the configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	payload, err := json.MarshalIndent(router.APIDocument(cfg.Versioning()), "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode document: %v", err)
+	}
+	payload = append(payload, '\n')
+
+	if *out == "" {
+		_, _ = os.Stdout.Write(payload)
+		return
+	}
+	if err := os.WriteFile(*out, payload, 0o644); err != nil {
+		log.Fatalf("Failed to write document: %v", err)
+	}
+}
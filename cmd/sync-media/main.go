@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"beautyessentials.com/internal/bootstrap"
+	"beautyessentials.com/internal/service/interfaces"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// sync-media imports files that exist in ImageKit but have no media record
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be imported without writing anything")
+	verbose := flag.Bool("verbose", false, "print every imported media in the report")
+	flag.Parse()
+
+	// Build only the dependencies needed to talk to the database and ImageKit
+	var mediaService interfaces.MediaService
+	var db *gorm.DB
+	app := fx.New(
+		bootstrap.CoreModule,
+		fx.Populate(&mediaService, &db),
+		fx.NopLogger,
+	)
+	if err := app.Err(); err != nil {
+		log.Fatalf("Failed to initialize: %v", err)
+	}
+
+	report, err := mediaService.SyncRemoteMedia(context.Background(), *dryRun)
+
+	// Close database connection
+	if sqlDB, dbErr := db.DB(); dbErr == nil {
+		_ = sqlDB.Close()
+	}
+
+	if err != nil {
+		log.Fatalf("Failed to sync media: %v", err)
+	}
+
+	if !*verbose {
+		report.Media = nil
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
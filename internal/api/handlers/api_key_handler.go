@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/validators"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ApiKeyHandler handles API key management requests
+type ApiKeyHandler struct {
+	apiKeyService interfaces.ApiKeyService
+	respHelper    *responses.ResponseHelper
+	validator     *validators.Validator
+}
+
+// NewApiKeyHandler creates a new instance of ApiKeyHandler
+func NewApiKeyHandler(
+	apiKeyService interfaces.ApiKeyService,
+	respHelper *responses.ResponseHelper,
+) *ApiKeyHandler {
+	return &ApiKeyHandler{
+		apiKeyService: apiKeyService,
+		respHelper:    respHelper,
+		validator:     validators.NewValidator(),
+	}
+}
+
+// GetAllApiKeys handles the request to list API keys
+func (h *ApiKeyHandler) GetAllApiKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.GetAllApiKeys(c)
+	if err != nil {
+		_ = c.Error(middlewares.NewInternalError("Failed to retrieve API keys", err.Error()))
+		return
+	}
+
+	h.respHelper.OkResponse(c, keys, "API keys retrieved successfully")
+}
+
+// GetApiKey handles the request to get an API key
+func (h *ApiKeyHandler) GetApiKey(c *gin.Context) {
+	key, err := h.apiKeyService.FindApiKey(c, c.Param("id"))
+	if err != nil {
+		h.sendApiKeyError(c, "Failed to retrieve API key", err)
+		return
+	}
+
+	h.respHelper.OkResponse(c, key, "API key retrieved successfully")
+}
+
+// CreateApiKey handles the request to create an API key
+func (h *ApiKeyHandler) CreateApiKey(c *gin.Context) {
+	var request requests.ApiKeyCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(middlewares.NewValidationError("Invalid request format", err.Error()))
+		return
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		_ = c.Error(middlewares.NewValidationError("Validation failed", "Request validation failed", validationErrors))
+		return
+	}
+
+	// The new key may only hold scopes the caller holds
+	grantable, _ := middlewares.GrantedPermissions(c)
+	var creatorID *string
+	if user, ok := middlewares.CurrentUser(c); ok {
+		creatorID = &user.ID
+	}
+
+	key, err := h.apiKeyService.CreateApiKey(c, request, creatorID, grantable)
+	if err != nil {
+		h.sendApiKeyError(c, "Failed to create API key", err)
+		return
+	}
+
+	h.respHelper.CreatedResponse(c, key, "API key created successfully; store the key now, it will not be shown again")
+}
+
+// RevokeApiKey handles the request to revoke an API key
+func (h *ApiKeyHandler) RevokeApiKey(c *gin.Context) {
+	if err := h.apiKeyService.RevokeApiKey(c, c.Param("id")); err != nil {
+		h.sendApiKeyError(c, "Failed to revoke API key", err)
+		return
+	}
+
+	h.respHelper.SendSuccess(c, "API key revoked successfully", http.StatusOK)
+}
+
+// sendApiKeyError maps API key service errors to responses
+func (h *ApiKeyHandler) sendApiKeyError(c *gin.Context, message string, err error) {
+	var unknownErr *interfaces.UnknownPermissionError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		_ = c.Error(middlewares.NewNotFoundError(message, "API key not found"))
+	case errors.As(err, &unknownErr):
+		_ = c.Error(middlewares.NewValidationError(message, err.Error(), unknownErr.Permissions))
+	case errors.Is(err, interfaces.ErrScopeNotGrantable):
+		_ = c.Error(middlewares.NewForbiddenError(message, err.Error()))
+	default:
+		_ = c.Error(middlewares.NewServiceError(message, err))
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/validators"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler handles registration, login and token requests
+type AuthHandler struct {
+	authService interfaces.AuthService
+	respHelper  *responses.ResponseHelper
+	validator   *validators.Validator
+}
+
+// NewAuthHandler creates a new instance of AuthHandler
+func NewAuthHandler(
+	authService interfaces.AuthService,
+	respHelper *responses.ResponseHelper,
+) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+		respHelper:  respHelper,
+		validator:   validators.NewValidator(),
+	}
+}
+
+// Register handles the request to create a user account
+func (h *AuthHandler) Register(c *gin.Context) {
+	var request requests.RegisterRequest
+	if !h.bind(c, &request) {
+		return
+	}
+
+	tokens, err := h.authService.Register(c, request)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to register", err))
+		return
+	}
+
+	h.respHelper.CreatedResponse(c, tokens, "Registered successfully")
+}
+
+// Login handles the request to sign in with email and password
+func (h *AuthHandler) Login(c *gin.Context) {
+	var request requests.LoginRequest
+	if !h.bind(c, &request) {
+		return
+	}
+
+	tokens, err := h.authService.Login(c, request)
+	if err != nil {
+		if errors.Is(err, interfaces.ErrInvalidCredentials) {
+			_ = c.Error(middlewares.NewUnauthorizedError("Invalid credentials", err.Error()))
+			return
+		}
+		_ = c.Error(middlewares.NewInternalError("Failed to log in", err.Error()))
+		return
+	}
+
+	h.respHelper.OkResponse(c, tokens, "Logged in successfully")
+}
+
+// Refresh handles the request to rotate a refresh token
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var request requests.RefreshTokenRequest
+	if !h.bind(c, &request) {
+		return
+	}
+
+	tokens, err := h.authService.RefreshTokens(c, request.RefreshToken)
+	if err != nil {
+		h.sendTokenError(c, "Failed to refresh token", err)
+		return
+	}
+
+	h.respHelper.OkResponse(c, tokens, "Token refreshed successfully")
+}
+
+// Logout handles the request to revoke a refresh token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var request requests.RefreshTokenRequest
+	if !h.bind(c, &request) {
+		return
+	}
+
+	if err := h.authService.Logout(c, request.RefreshToken); err != nil {
+		h.sendTokenError(c, "Failed to log out", err)
+		return
+	}
+
+	h.respHelper.SendSuccess(c, "Logged out successfully", http.StatusOK)
+}
+
+// LogoutAll handles the request to revoke every refresh token of the current user
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	user, _ := middlewares.CurrentUser(c)
+	if err := h.authService.LogoutAll(c, user.ID); err != nil {
+		_ = c.Error(middlewares.NewInternalError("Failed to log out", err.Error()))
+		return
+	}
+
+	h.respHelper.SendSuccess(c, "Logged out of all sessions successfully", http.StatusOK)
+}
+
+// Me handles the request to get the current user
+func (h *AuthHandler) Me(c *gin.Context) {
+	user, _ := middlewares.CurrentUser(c)
+	h.respHelper.OkResponse(c, user, "User retrieved successfully")
+}
+
+// bind parses and validates a JSON request, reporting failures through the error handler
+func (h *AuthHandler) bind(c *gin.Context, request interface{}) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		_ = c.Error(middlewares.NewValidationError("Invalid request format", err.Error()))
+		return false
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		_ = c.Error(middlewares.NewValidationError("Validation failed", "Request validation failed", validationErrors))
+		return false
+	}
+
+	return true
+}
+
+// sendTokenError reports invalid refresh tokens as 401 and anything else as 500
+func (h *AuthHandler) sendTokenError(c *gin.Context, message string, err error) {
+	if errors.Is(err, interfaces.ErrInvalidToken) {
+		_ = c.Error(middlewares.NewUnauthorizedError(message, err.Error()))
+		return
+	}
+	_ = c.Error(middlewares.NewInternalError(message, err.Error()))
+}
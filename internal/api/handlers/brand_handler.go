@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"beautyessentials.com/internal/api/middlewares"
 	"beautyessentials.com/internal/api/responses"
@@ -77,10 +79,19 @@ func (h *BrandHandler) GetAllBrands(c *gin.Context) {
 		appends["page"] = pageInt
 	}
 
+	// Add sparse fieldsets
+	query, ok := parseFieldset(c, h.respHelper, appends, brandFields, nil)
+	if !ok {
+		return
+	}
+
 	// Get brands from service
 	brands, err := h.brandService.GetAllBrands(c, filters, appends)
+	if err == nil {
+		brands, err = shapeResponse(brands, query, "brands", nil)
+	}
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to retrieve brands", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve brands", err))
 		return
 	}
 
@@ -97,13 +108,25 @@ func (h *BrandHandler) GetAllBrands(c *gin.Context) {
 // GetBrand handles the request to get a specific brand
 func (h *BrandHandler) GetBrand(c *gin.Context) {
 	id := c.Param("id")
-	brand, err := h.brandService.FindBrand(c, id)
+	appends := make(map[string]interface{})
+	query, ok := parseFieldset(c, h.respHelper, appends, brandFields, nil)
+	if !ok {
+		return
+	}
+
+	brand, err := h.brandService.FindBrand(c, id, appends)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve brand", err))
+		return
+	}
+	shaped, err := shapeResponse(brand, query, "brands", nil)
 	if err != nil {
-		h.respHelper.SendError(c, "Brand not found", err.Error(), http.StatusNotFound)
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve brand", err))
 		return
 	}
 
-	h.respHelper.OkResponse(c, brand, "Brand retrieved successfully")
+	setVersionETag(c, brand.Version)
+	h.respHelper.OkResponse(c, shaped, "Brand retrieved successfully")
 }
 
 // CreateBrand handles the request to create a new brand
@@ -130,16 +153,17 @@ func (h *BrandHandler) CreateBrand(c *gin.Context) {
 	brand, err := h.brandService.CreateBrand(c, request)
 	if err != nil {
 		// Add error to context instead of handling directly
-		appErr := middlewares.NewInternalError("Failed to create brand", err.Error())
+		appErr := middlewares.NewServiceError("Failed to create brand", err)
 		_ = c.Error(appErr)
 		return
 	}
 
 	// Return the created brand
+	setVersionETag(c, brand.Version)
 	h.respHelper.CreatedResponse(c, brand, "Brand created successfully")
 }
 
-// UpdateBrand handles the request to update a brand
+// UpdateBrand handles the request to replace a brand
 func (h *BrandHandler) UpdateBrand(c *gin.Context) {
 	id := c.Param("id")
 
@@ -157,18 +181,62 @@ func (h *BrandHandler) UpdateBrand(c *gin.Context) {
 		return
 	}
 
-	// Convert validated request to map for service
-	data := make(map[string]interface{})
-	if request.Name != "" {
-		data["name"] = request.Name
+	h.replaceBrand(c, id, request, 0)
+}
+
+// PatchBrand handles the request to apply a JSON merge patch to a brand
+func (h *BrandHandler) PatchBrand(c *gin.Context) {
+	id := c.Param("id")
+	current, err := h.brandService.FindBrand(c, id, nil)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve brand", err))
+		return
+	}
+
+	// Merge the patch into the brand's editable fields
+	var request requests.BrandUpdateRequest
+	if !applyMergePatch(c, requests.BrandUpdateRequest{Name: current.Name}, &request) {
+		return
+	}
+
+	// Validate the merged brand
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		h.respHelper.ValidationError(c, validationErrors, "Validation failed")
+		return
+	}
+
+	h.replaceBrand(c, id, request, current.Version)
+}
+
+// replaceBrand writes every field of request to the brand. baseVersion is the
+// version a merged request was built from, so "If-Match: *" still never
+// overwrites changes made since it was read.
+func (h *BrandHandler) replaceBrand(c *gin.Context, id string, request requests.BrandUpdateRequest, baseVersion int64) {
+	data := map[string]interface{}{
+		"name": request.Name,
+	}
+
+	version, ok := expectedVersion(c, request.Version)
+	if !ok {
+		return
+	}
+	if version == 0 {
+		version = baseVersion
 	}
 
-	brand, err := h.brandService.UpdateBrand(c, data, id)
+	brand, err := h.brandService.UpdateBrand(c, data, id, version)
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to update brand", err.Error(), http.StatusInternalServerError)
+		// A conflict is answered with the current record, tagged with its version
+		var conflictErr *interfaces.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			setVersionETag(c, conflictErr.Version)
+		}
+		_ = c.Error(middlewares.NewServiceError("Failed to update brand", err))
 		return
 	}
 
+	setVersionETag(c, brand.Version)
 	h.respHelper.OkResponse(c, brand, "Brand updated successfully")
 }
 
@@ -177,7 +245,7 @@ func (h *BrandHandler) DeleteBrand(c *gin.Context) {
 	id := c.Param("id")
 	err := h.brandService.DeleteBrand(c, id)
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to delete brand", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to delete brand", err))
 		return
 	}
 
@@ -188,11 +256,35 @@ func (h *BrandHandler) DeleteBrand(c *gin.Context) {
 
 // GetGroupedBrands handles the request to get brands grouped by first letter
 func (h *BrandHandler) GetGroupedBrands(c *gin.Context) {
-	brands, err := h.brandService.GetGroupedBrands(c)
+	appends := make(map[string]interface{})
+	query, ok := parseFieldset(c, h.respHelper, appends, brandFields, nil)
+	if !ok {
+		return
+	}
+
+	brands, err := h.brandService.GetGroupedBrands(c, appends)
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to retrieve grouped brands", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve grouped brands", err))
 		return
 	}
 
-	h.respHelper.OkResponse(c, brands, "Grouped brands retrieved successfully")
+	// Shape each group of brands
+	groups := make(map[string]interface{}, len(brands))
+	for letter, group := range brands {
+		if groups[letter], err = shapeResponse(group, query, "brands", nil); err != nil {
+			_ = c.Error(middlewares.NewServiceError("Failed to retrieve grouped brands", err))
+			return
+		}
+	}
+
+	h.respHelper.OkResponse(c, groups, "Grouped brands retrieved successfully")
+}
+
+// BrandsVersion is the conditional request validator of the brands listings
+func (h *BrandHandler) BrandsVersion(c *gin.Context) (string, time.Time, error) {
+	version, err := h.brandService.GetBrandsVersion(c)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return version.Tag(), version.Latest(), nil
 }
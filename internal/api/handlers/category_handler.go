@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"beautyessentials.com/internal/api/middlewares"
 	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/constant"
 	"beautyessentials.com/internal/service/interfaces"
 	"beautyessentials.com/internal/requests"
 	"beautyessentials.com/internal/validators"
@@ -76,10 +80,19 @@ func (h *CategoryHandler) GetAllCategories(c *gin.Context) {
 		appends["page"] = pageInt
 	}
 
+	// Add sparse fieldsets and includes
+	query, ok := parseFieldset(c, h.respHelper, appends, categoryFields, categoryRelationships)
+	if !ok {
+		return
+	}
+
 	// Get categories from service
 	categories, err := h.categoryService.GetAllCategories(c, filters, appends)
+	if err == nil {
+		categories, err = shapeResponse(categories, query, "categories", categoryRelationships)
+	}
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to retrieve categories", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve categories", err))
 		return
 	}
 
@@ -96,13 +109,25 @@ func (h *CategoryHandler) GetAllCategories(c *gin.Context) {
 // GetCategory handles the request to get a specific category
 func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	id := c.Param("id")
-	category, err := h.categoryService.FindCategory(c, id)
+	appends := make(map[string]interface{})
+	query, ok := parseFieldset(c, h.respHelper, appends, categoryFields, categoryRelationships)
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryService.FindCategory(c, id, appends)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve category", err))
+		return
+	}
+	shaped, err := shapeResponse(category, query, "categories", categoryRelationships)
 	if err != nil {
-		h.respHelper.SendError(c, "Category not found", err.Error(), http.StatusNotFound)
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve category", err))
 		return
 	}
 
-	h.respHelper.OkResponse(c, category, "Category retrieved successfully")
+	setVersionETag(c, category.Version)
+	h.respHelper.OkResponse(c, shaped, "Category retrieved successfully")
 }
 
 // CreateCategory handles the request to create a new category
@@ -124,15 +149,16 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	// Create category directly using the request data
 	category, err := h.categoryService.CreateCategory(c, request)
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to create category", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to create category", err))
 		return
 	}
 
 	// Return the created category
+	setVersionETag(c, category.Version)
 	h.respHelper.CreatedResponse(c, category, "Category created successfully")
 }
 
-// UpdateCategory handles the request to update a category
+// UpdateCategory handles the request to replace a category
 func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 	id := c.Param("id")
 
@@ -150,27 +176,75 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	// Convert validated request to map for service
-	data := make(map[string]interface{})
-	if request.Name != "" {
-		data["name"] = request.Name
+	h.replaceCategory(c, id, request, 0)
+}
+
+// PatchCategory handles the request to apply a JSON merge patch to a category
+func (h *CategoryHandler) PatchCategory(c *gin.Context) {
+	id := c.Param("id")
+	current, err := h.categoryService.FindCategory(c, id, nil)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve category", err))
+		return
 	}
-	if request.Description != "" {
-		data["description"] = request.Description
+
+	// Merge the patch into the category's editable fields
+	var request requests.CategoryUpdateRequest
+	document := requests.CategoryUpdateRequest{
+		Name:        current.Name,
+		Description: current.Description,
+		Status:      current.Status,
+		MediaID:     current.MediaID,
 	}
-	if request.Status != "" {
-		data["status"] = request.Status
+	if !applyMergePatch(c, document, &request) {
+		return
 	}
-	if request.MediaID != "" {
-		data["media_id"] = request.MediaID
+
+	// Validate the merged category
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		h.respHelper.ValidationError(c, validationErrors, "Validation failed")
+		return
 	}
 
-	category, err := h.categoryService.UpdateCategory(c, data, id)
+	h.replaceCategory(c, id, request, current.Version)
+}
+
+// replaceCategory writes every field of request to the category, clearing the
+// omitted ones. baseVersion is the version a merged request was built from, so
+// "If-Match: *" still never overwrites changes made since it was read.
+func (h *CategoryHandler) replaceCategory(c *gin.Context, id string, request requests.CategoryUpdateRequest, baseVersion int64) {
+	status := request.Status
+	if status == "" {
+		status = string(constant.StatusActive)
+	}
+	data := map[string]interface{}{
+		"name":        request.Name,
+		"description": request.Description,
+		"status":      status,
+		"media_id":    request.MediaID,
+	}
+
+	version, ok := expectedVersion(c, request.Version)
+	if !ok {
+		return
+	}
+	if version == 0 {
+		version = baseVersion
+	}
+
+	category, err := h.categoryService.UpdateCategory(c, data, id, version)
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to update category", err.Error(), http.StatusInternalServerError)
+		// A conflict is answered with the current record, tagged with its version
+		var conflictErr *interfaces.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			setVersionETag(c, conflictErr.Version)
+		}
+		_ = c.Error(middlewares.NewServiceError("Failed to update category", err))
 		return
 	}
 
+	setVersionETag(c, category.Version)
 	h.respHelper.OkResponse(c, category, "Category updated successfully")
 }
 
@@ -179,7 +253,7 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	id := c.Param("id")
 	err := h.categoryService.DeleteCategory(c, id)
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to delete category", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to delete category", err))
 		return
 	}
 
@@ -188,23 +262,52 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 
 // GetActiveCategories handles the request to get all active categories
 func (h *CategoryHandler) GetActiveCategories(c *gin.Context) {
-	categories, err := h.categoryService.GetActiveCategories(c)
+	appends := make(map[string]interface{})
+	query, ok := parseFieldset(c, h.respHelper, appends, categoryFields, categoryRelationships)
+	if !ok {
+		return
+	}
+
+	categories, err := h.categoryService.GetActiveCategories(c, appends)
+	var shaped interface{}
+	if err == nil {
+		shaped, err = shapeResponse(categories, query, "categories", categoryRelationships)
+	}
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to retrieve active categories", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve active categories", err))
 		return
 	}
 
-	h.respHelper.OkResponse(c, categories, "Active categories retrieved successfully")
+	h.respHelper.OkResponse(c, shaped, "Active categories retrieved successfully")
 }
 
 // FindCategoryBySlug handles the request to find categories by slug
 func (h *CategoryHandler) FindCategoryBySlug(c *gin.Context) {
 	slug := c.Param("slug")
-	categories, err := h.categoryService.FindCategoryBySlug(c, slug)
+	appends := make(map[string]interface{})
+	query, ok := parseFieldset(c, h.respHelper, appends, categoryFields, categoryRelationships)
+	if !ok {
+		return
+	}
+
+	categories, err := h.categoryService.FindCategoryBySlug(c, slug, appends)
+	var shaped interface{}
+	if err == nil {
+		shaped, err = shapeResponse(categories, query, "categories", categoryRelationships)
+	}
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to retrieve categories by slug", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve categories by slug", err))
 		return
 	}
 
-	h.respHelper.OkResponse(c, categories, "Categories retrieved successfully")
-}
\ No newline at end of file
+	h.respHelper.OkResponse(c, shaped, "Categories retrieved successfully")
+}
+
+// CategoriesVersion is the conditional request validator of the categories listings
+func (h *CategoryHandler) CategoriesVersion(c *gin.Context) (string, time.Time, error) {
+	version, err := h.categoryService.GetCategoriesVersion(c)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return version.Tag(), version.Latest(), nil
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/service/interfaces"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const testCategoryID = "01J00000000000000000000CAT"
+
+// fakeCategoryService serves one category and records the updates it receives
+type fakeCategoryService struct {
+	interfaces.CategoryService
+	current dto.CategoryDTO
+
+	updated     bool
+	updateData  map[string]interface{}
+	updateID    string
+	updateBased int64
+}
+
+func (s *fakeCategoryService) FindCategory(ctx context.Context, id string, appends map[string]interface{}) (dto.CategoryDTO, error) {
+	return s.current, nil
+}
+
+func (s *fakeCategoryService) UpdateCategory(ctx context.Context, data map[string]interface{}, id string, version int64) (dto.CategoryDTO, error) {
+	s.updated = true
+	s.updateData = data
+	s.updateID = id
+	s.updateBased = version
+	return dto.CategoryDTO{ID: id, Name: data["name"].(string), Version: version + 1}, nil
+}
+
+// newCategoryTestRouter routes category updates to a handler backed by service
+func newCategoryTestRouter(service interfaces.CategoryService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	respHelper := responses.NewResponseHelper()
+	handler := NewCategoryHandler(service, respHelper)
+
+	router := gin.New()
+	router.Use(middlewares.CaseConverterMiddleware(), middlewares.ErrorHandler(respHelper, zap.NewNop()))
+	router.PUT("/categories/:id", handler.UpdateCategory)
+	router.PATCH("/categories/:id", handler.PatchCategory)
+	return router
+}
+
+func TestCategoryUpdateAndPatch(t *testing.T) {
+	current := dto.CategoryDTO{
+		ID:          testCategoryID,
+		Name:        "Skincare",
+		Status:      "inactive",
+		Description: "Creams and serums",
+		MediaID:     "01J0000000000000000000MED1",
+		Version:     4,
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		ifMatch     string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantData    map[string]interface{}
+		wantVersion int64
+	}{
+		{
+			name:        "put replaces every field and clears omitted ones",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			ifMatch:     `"3"`,
+			body:        `{"name":"Body care"}`,
+			wantStatus:  http.StatusOK,
+			wantData:    map[string]interface{}{"name": "Body care", "description": "", "status": "active", "media_id": ""},
+			wantVersion: 3,
+		},
+		{
+			name:        "put takes the version from the body",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			body:        `{"name":"Body care","status":"inactive","mediaId":"01J0000000000000000000MED2","version":7}`,
+			wantStatus:  http.StatusOK,
+			wantData:    map[string]interface{}{"name": "Body care", "description": "", "status": "inactive", "media_id": "01J0000000000000000000MED2"},
+			wantVersion: 7,
+		},
+		{
+			name:        "put without a version is rejected",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			body:        `{"name":"Body care"}`,
+			wantStatus:  http.StatusPreconditionRequired,
+			wantCode:    "PRECONDITION_REQUIRED",
+		},
+		{
+			name:        "put with a weak If-Match is rejected",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			ifMatch:     `W/"3"`,
+			body:        `{"name":"Body care"}`,
+			wantStatus:  http.StatusPreconditionFailed,
+			wantCode:    "INVALID_IF_MATCH",
+		},
+		{
+			name:        "put without a name fails validation",
+			method:      http.MethodPut,
+			contentType: "application/json",
+			ifMatch:     `"3"`,
+			body:        `{"description":"x"}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "patch keeps the fields it does not mention",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"4"`,
+			body:        `{"name":"Face care"}`,
+			wantStatus:  http.StatusOK,
+			wantData:    map[string]interface{}{"name": "Face care", "description": "Creams and serums", "status": "inactive", "media_id": "01J0000000000000000000MED1"},
+			wantVersion: 4,
+		},
+		{
+			name:        "patch null clears a field",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			body:        `{"description":null,"media_id":null,"version":4}`,
+			wantStatus:  http.StatusOK,
+			wantData:    map[string]interface{}{"name": "Skincare", "description": "", "status": "inactive", "media_id": ""},
+			wantVersion: 4,
+		},
+		{
+			name:        "patch If-Match overrides the version read",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"2"`,
+			body:        `{"status":"active"}`,
+			wantStatus:  http.StatusOK,
+			wantData:    map[string]interface{}{"name": "Skincare", "description": "Creams and serums", "status": "active", "media_id": "01J0000000000000000000MED1"},
+			wantVersion: 2,
+		},
+		{
+			name:        "patch If-Match * is based on the version read",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			ifMatch:     "*",
+			body:        `{"name":"Face care"}`,
+			wantStatus:  http.StatusOK,
+			wantData:    map[string]interface{}{"name": "Face care", "description": "Creams and serums", "status": "inactive", "media_id": "01J0000000000000000000MED1"},
+			wantVersion: 4,
+		},
+		{
+			name:        "patch without a version is rejected",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			body:        `{"name":"Face care"}`,
+			wantStatus:  http.StatusPreconditionRequired,
+			wantCode:    "PRECONDITION_REQUIRED",
+		},
+		{
+			name:        "patch null on a required field fails validation",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"4"`,
+			body:        `{"name":null}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "patch with another media type is rejected",
+			method:      http.MethodPatch,
+			contentType: "text/plain",
+			body:        `{"name":"Face care"}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantCode:    "UNSUPPORTED_MEDIA_TYPE",
+		},
+		{
+			name:        "patch with a malformed document is rejected",
+			method:      http.MethodPatch,
+			contentType: "application/merge-patch+json",
+			body:        `{"name":`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "BAD_REQUEST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeCategoryService{current: current}
+			router := newCategoryTestRouter(service)
+
+			req := httptest.NewRequest(tt.method, "/categories/"+testCategoryID, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode != "" {
+				var body responses.ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != tt.wantCode {
+					t.Errorf("error code = %q, want %q; body %s", body.Code, tt.wantCode, w.Body.String())
+				}
+			}
+			if tt.wantData == nil {
+				if service.updated {
+					t.Errorf("rejected request still updated the category with %v", service.updateData)
+				}
+				return
+			}
+
+			if !service.updated {
+				t.Fatal("category was not updated")
+			}
+			if service.updateID != testCategoryID {
+				t.Errorf("updated id = %q, want %q", service.updateID, testCategoryID)
+			}
+			if !reflect.DeepEqual(service.updateData, tt.wantData) {
+				t.Errorf("update data = %v, want %v", service.updateData, tt.wantData)
+			}
+			if service.updateBased != tt.wantVersion {
+				t.Errorf("update based on version %d, want %d", service.updateBased, tt.wantVersion)
+			}
+			if etag, want := w.Header().Get("ETag"), `"`+strconv.FormatInt(tt.wantVersion+1, 10)+`"`; etag != want {
+				t.Errorf("ETag = %s, want %s", etag, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/utils/fieldset"
+	"github.com/gin-gonic/gin"
+)
+
+// Fields of each resource type that sparse fieldsets may select
+var (
+	brandFields    = map[string][]string{"brands": fieldset.Names(dto.BrandDTO{})}
+	categoryFields = map[string][]string{
+		"categories": fieldset.Names(dto.CategoryDTO{}),
+		"media":      fieldset.Names(dto.MediaDTO{}),
+	}
+	mediaFields = map[string][]string{"media": fieldset.Names(dto.MediaDTO{})}
+)
+
+// categoryRelationships maps the includable members of a category to their resource
+// type. Categories are neither linked to brands nor nested, so includes such as
+// brand or children are rejected as unknown.
+var categoryRelationships = map[string]string{"media": "media"}
+
+// parseFieldset reads the sparse fieldsets and includes of the request into
+// appends. It sends the error response and returns false when they name unknown
+// fields or relationships.
+func parseFieldset(c *gin.Context, respHelper *responses.ResponseHelper, appends map[string]interface{}, allowed map[string][]string, relationships map[string]string) (fieldset.Query, bool) {
+	query := fieldset.Parse(c.Request.URL.Query())
+
+	includes := make([]string, 0, len(relationships))
+	for relationship := range relationships {
+		includes = append(includes, relationship)
+	}
+	if err := query.Validate(allowed, includes...); err != nil {
+		respHelper.SendError(c, "Invalid query", err.Error(), http.StatusBadRequest)
+		return fieldset.Query{}, false
+	}
+
+	appends["fieldset"] = query
+	return query, true
+}
+
+// shapeResponse keeps only the requested members of data, a DTO, a list of
+// them or a page whose data holds them
+func shapeResponse(data interface{}, query fieldset.Query, resourceType string, relationships map[string]string) (interface{}, error) {
+	page, ok := data.(map[string]interface{})
+	if !ok {
+		return fieldset.Shape(data, query, resourceType, relationships)
+	}
+
+	items, err := fieldset.Shape(page["data"], query, resourceType, relationships)
+	if err != nil {
+		return nil, err
+	}
+	shaped := make(map[string]interface{}, len(page))
+	for key, value := range page {
+		shaped[key] = value
+	}
+	shaped["data"] = items
+	return shaped, nil
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/fieldset"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// fakeMediaService lists a fixed set of media and records the query it was given
+type fakeMediaService struct {
+	interfaces.MediaService
+	media   []dto.MediaDTO
+	appends map[string]interface{}
+}
+
+func (s *fakeMediaService) GetAllMedia(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error) {
+	s.appends = appends
+	return s.media, nil
+}
+
+// newCatalogTestRouter routes the catalog reads to handlers backed by the given services;
+// nil services fail the test when a request reaches them
+func newCatalogTestRouter(media interfaces.MediaService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	respHelper := responses.NewResponseHelper()
+	brands := NewBrandHandler(nil, respHelper)
+	categories := NewCategoryHandler(nil, respHelper)
+	mediaHandler := NewMediaHandler(media, respHelper)
+
+	router := gin.New()
+	router.Use(middlewares.CaseConverterMiddleware(), middlewares.ErrorHandler(respHelper, zap.NewNop()))
+	router.GET("/brands", brands.GetAllBrands)
+	router.GET("/brands/grouped", brands.GetGroupedBrands)
+	router.GET("/brands/:id", brands.GetBrand)
+	router.GET("/categories", categories.GetAllCategories)
+	router.GET("/categories/active", categories.GetActiveCategories)
+	router.GET("/categories/slug/:slug", categories.FindCategoryBySlug)
+	router.GET("/categories/:id", categories.GetCategory)
+	router.GET("/media", mediaHandler.GetAllMedia)
+	return router
+}
+
+func TestUnsupportedFieldsetsAreRejected(t *testing.T) {
+	router := newCatalogTestRouter(nil)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"brand list include", "/brands?include=media"},
+		{"brand include", "/brands/" + testCategoryID + "?include=categories"},
+		{"grouped brands include", "/brands/grouped?include=children"},
+		{"brand unknown field", "/brands?fields[brands]=id,colour"},
+		{"brand fields of another type", "/brands?fields[media]=url"},
+		{"category list include brand", "/categories?include=brand"},
+		{"category include children", "/categories/" + testCategoryID + "?include=media,children"},
+		{"active categories unknown media field", "/categories/active?include=media&fields[media]=size"},
+		{"categories by slug unknown type", "/categories/slug/skincare?fields[brands]=name"},
+		{"media include", "/media?include=tags"},
+		{"media unknown field", "/media?fields[media]=id,name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("GET %s status = %d, want %d; body %s", tt.url, w.Code, http.StatusBadRequest, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMediaFieldset(t *testing.T) {
+	folder := "01J00000000000000000FOLDER"
+	service := &fakeMediaService{media: []dto.MediaDTO{{
+		ID:       "01J0000000000000000000MED1",
+		FileID:   "file-1",
+		URL:      "https://ik.imagekit.io/demo/a.png",
+		ThumbURL: "https://ik.imagekit.io/demo/tr:n-ik_ml_thumbnail/a.png",
+		FolderID: &folder,
+	}}}
+	router := newCatalogTestRouter(service)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/media?fields[media]=url,thumbUrl", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if query, _ := service.appends["fieldset"].(fieldset.Query); !reflect.DeepEqual(query.FieldsOf("media"), []string{"url", "thumb_url"}) {
+		t.Errorf("service got fields %v, want [url thumb_url]", query.FieldsOf("media"))
+	}
+
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	if len(body.Data) != 1 {
+		t.Fatalf("response has %d media, want 1: %s", len(body.Data), w.Body.String())
+	}
+	var members []string
+	for key := range body.Data[0] {
+		members = append(members, key)
+	}
+	sort.Strings(members)
+	if want := []string{"id", "thumbUrl", "url"}; !reflect.DeepEqual(members, want) {
+		t.Errorf("media members = %v, want %v", members, want)
+	}
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"log"
 	"time"
 
 	"beautyessentials.com/internal/api/responses"
@@ -28,8 +27,6 @@ func NewHealthHandler(
 
 // HealthCheck handles the health check endpoint
 func (h *HealthHandler) HealthCheck(c *gin.Context) {
-	log.Println("Health check endpoint called")
-	
 	// Call the service layer
 	healthy, err := h.healthService.CheckHealth(c)
 	if err != nil {
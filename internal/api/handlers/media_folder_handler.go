@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/validators"
+	"github.com/gin-gonic/gin"
+)
+
+// MediaFolderHandler handles media folder requests
+type MediaFolderHandler struct {
+	folderService interfaces.MediaFolderService
+	respHelper    *responses.ResponseHelper
+	validator     *validators.Validator
+}
+
+// NewMediaFolderHandler creates a new instance of MediaFolderHandler
+func NewMediaFolderHandler(
+	folderService interfaces.MediaFolderService,
+	respHelper *responses.ResponseHelper,
+) *MediaFolderHandler {
+	return &MediaFolderHandler{
+		folderService: folderService,
+		respHelper:    respHelper,
+		validator:     validators.NewValidator(),
+	}
+}
+
+// GetAllFolders handles the request to list media folders
+func (h *MediaFolderHandler) GetAllFolders(c *gin.Context) {
+	filters := make(map[string]interface{})
+
+	// Restrict to the children of a parent; "root" selects top-level folders
+	if parentID, ok := c.GetQuery("parent_id"); ok {
+		if parentID == "root" {
+			parentID = ""
+		}
+		filters["parent_id"] = parentID
+	}
+
+	folders, err := h.folderService.GetAllFolders(c, filters)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve folders", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, folders, "Folders retrieved successfully")
+}
+
+// CreateFolder handles the request to create a media folder
+func (h *MediaFolderHandler) CreateFolder(c *gin.Context) {
+	// Parse and validate request
+	var request requests.MediaFolderCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.respHelper.SendError(c, "Invalid request format", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		h.respHelper.ValidationError(c, validationErrors, "Validation failed")
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(c, request)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to create folder", err))
+		return
+	}
+
+	h.respHelper.CreatedResponse(c, folder, "Folder created successfully")
+}
+
+// DeleteFolder handles the request to delete an empty media folder
+func (h *MediaFolderHandler) DeleteFolder(c *gin.Context) {
+	id := c.Param("id")
+	err := h.folderService.DeleteFolder(c, id)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to delete folder", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, nil, "Folder deleted successfully")
+}
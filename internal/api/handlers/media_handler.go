@@ -3,7 +3,9 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
+	"beautyessentials.com/internal/api/middlewares"
 	"beautyessentials.com/internal/api/responses"
 	"beautyessentials.com/internal/requests"
 	"beautyessentials.com/internal/service/interfaces"
@@ -41,6 +43,19 @@ func (h *MediaHandler) GetAllMedia(c *gin.Context) {
 		filters["trashed"] = trashed == "true"
 	}
 
+	// Add folder filter if provided; "root" selects media outside any folder
+	if folderID, ok := c.GetQuery("folder_id"); ok {
+		if folderID == "root" {
+			folderID = ""
+		}
+		filters["folder_id"] = folderID
+	}
+
+	// Add tags filter if provided (comma separated)
+	if tags := c.Query("tags"); tags != "" {
+		filters["tags"] = strings.Split(tags, ",")
+	}
+
 	// Add pagination parameters - default to true (matching Laravel)
 	shouldPaginate := true
 	paginateParam := c.DefaultQuery("paginate", "false")
@@ -67,10 +82,19 @@ func (h *MediaHandler) GetAllMedia(c *gin.Context) {
 		appends["page"] = pageInt
 	}
 
+	// Add sparse fieldsets
+	query, ok := parseFieldset(c, h.respHelper, appends, mediaFields, nil)
+	if !ok {
+		return
+	}
+
 	// Get media from service
 	media, err := h.mediaService.GetAllMedia(c, filters, appends)
+	if err == nil {
+		media, err = shapeResponse(media, query, "media", nil)
+	}
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to retrieve media", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve media", err))
 		return
 	}
 
@@ -103,7 +127,7 @@ func (h *MediaHandler) CreateMedia(c *gin.Context) {
 	// Create media directly using the request data
 	media, err := h.mediaService.CreateMedia(c, request)
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to create media", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to create media", err))
 		return
 	}
 
@@ -114,11 +138,207 @@ func (h *MediaHandler) CreateMedia(c *gin.Context) {
 // DeleteMedia handles the request to delete a media
 func (h *MediaHandler) DeleteMedia(c *gin.Context) {
 	id := c.Param("id")
-	err := h.mediaService.DeleteMedia(c, id)
+	force := c.DefaultQuery("force", "false") == "true"
+	err := h.mediaService.DeleteMedia(c, id, force)
 	if err != nil {
-		h.respHelper.SendError(c, "Failed to delete media", err.Error(), http.StatusInternalServerError)
+		_ = c.Error(middlewares.NewServiceError("Failed to delete media", err))
 		return
 	}
 
 	h.respHelper.OkResponse(c, nil, "Media deleted successfully")
 }
+
+// GetMediaUsages handles the request to list the entities a media is attached to
+func (h *MediaHandler) GetMediaUsages(c *gin.Context) {
+	id := c.Param("id")
+	usages, err := h.mediaService.GetMediaUsages(c, id)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve media usages", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, usages, "Media usages retrieved successfully")
+}
+
+// GetUploadAuth handles the request to issue direct upload credentials
+func (h *MediaHandler) GetUploadAuth(c *gin.Context) {
+	// Credentials are bound to the folder the file is uploaded to
+	auth, err := h.mediaService.GetUploadAuth(c, c.Query("folder_id"))
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to generate upload credentials", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, auth, "Upload credentials generated successfully")
+}
+
+// ConfirmUpload handles the request to register a directly uploaded file
+func (h *MediaHandler) ConfirmUpload(c *gin.Context) {
+	// Parse and validate request
+	var request requests.MediaConfirmUploadRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.respHelper.SendError(c, "Invalid request format", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		h.respHelper.ValidationError(c, validationErrors, "Validation failed")
+		return
+	}
+
+	media, duplicate, err := h.mediaService.ConfirmUpload(c, request)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to confirm upload", err))
+		return
+	}
+
+	if duplicate {
+		h.respHelper.OkResponse(c, media, "Media already exists")
+		return
+	}
+	h.respHelper.CreatedResponse(c, media, "Media created successfully")
+}
+
+// UploadMedia handles the request to upload a file through the API
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	// Parse and validate request
+	var request requests.MediaUploadRequest
+	if err := c.ShouldBind(&request); err != nil {
+		h.respHelper.SendError(c, "Invalid request format", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		h.respHelper.ValidationError(c, validationErrors, "Validation failed")
+		return
+	}
+
+	media, duplicate, err := h.mediaService.UploadMedia(c, request)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to upload media", err))
+		return
+	}
+
+	if duplicate {
+		h.respHelper.OkResponse(c, media, "Media already exists")
+		return
+	}
+	h.respHelper.CreatedResponse(c, media, "Media uploaded successfully")
+}
+
+// GetDuplicateReport handles the request to list groups of near-duplicate media
+func (h *MediaHandler) GetDuplicateReport(c *gin.Context) {
+	// Maximum Hamming distance between perceptual hashes
+	threshold := 5
+	if val, err := strconv.Atoi(c.Query("threshold")); err == nil && val >= 0 && val <= 64 {
+		threshold = val
+	}
+
+	groups, err := h.mediaService.GetDuplicateReport(c, threshold)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to build duplicate report", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, groups, "Duplicate report generated successfully")
+}
+
+// MoveMedia handles the request to move media into a folder
+func (h *MediaHandler) MoveMedia(c *gin.Context) {
+	// Parse and validate request
+	var request requests.MediaMoveRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.respHelper.SendError(c, "Invalid request format", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		h.respHelper.ValidationError(c, validationErrors, "Validation failed")
+		return
+	}
+
+	if err := h.mediaService.MoveMedia(c, request); err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to move media", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, nil, "Media moved successfully")
+}
+
+// TagMedia handles the request to tag media in bulk
+func (h *MediaHandler) TagMedia(c *gin.Context) {
+	// Parse and validate request
+	var request requests.MediaTagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.respHelper.SendError(c, "Invalid request format", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		h.respHelper.ValidationError(c, validationErrors, "Validation failed")
+		return
+	}
+
+	if err := h.mediaService.TagMedia(c, request); err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to tag media", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, nil, "Media tagged successfully")
+}
+
+// UntagMedia handles the request to untag media in bulk
+func (h *MediaHandler) UntagMedia(c *gin.Context) {
+	// Parse and validate request
+	var request requests.MediaTagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.respHelper.SendError(c, "Invalid request format", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		h.respHelper.ValidationError(c, validationErrors, "Validation failed")
+		return
+	}
+
+	if err := h.mediaService.UntagMedia(c, request); err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to untag media", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, nil, "Media untagged successfully")
+}
+
+// GetAllTags handles the request to list media tags
+func (h *MediaHandler) GetAllTags(c *gin.Context) {
+	tags, err := h.mediaService.GetAllTags(c)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to retrieve tags", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, tags, "Tags retrieved successfully")
+}
+
+// SyncRemoteMedia handles the request to import remote storage files into the media library
+func (h *MediaHandler) SyncRemoteMedia(c *gin.Context) {
+	dryRun := c.DefaultQuery("dry_run", "false") == "true"
+
+	report, err := h.mediaService.SyncRemoteMedia(c, dryRun)
+	if err != nil {
+		_ = c.Error(middlewares.NewServiceError("Failed to sync media", err))
+		return
+	}
+
+	h.respHelper.OkResponse(c, report, "Media synced successfully")
+}
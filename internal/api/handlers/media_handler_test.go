@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/service/external"
+	"beautyessentials.com/internal/service/interfaces"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// fakeUploadService fails upload confirmations with err
+type fakeUploadService struct {
+	interfaces.MediaService
+	err error
+}
+
+func (s *fakeUploadService) ConfirmUpload(ctx context.Context, request requests.MediaConfirmUploadRequest) (dto.MediaDTO, bool, error) {
+	return dto.MediaDTO{}, false, s.err
+}
+
+func TestConfirmUploadErrorStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantCode       string
+		wantRetryAfter string
+	}{
+		{"credentials not issued by us", interfaces.ErrUploadNotAuthorized, http.StatusUnprocessableEntity, "UPLOAD_NOT_AUTHORIZED", ""},
+		{"file missing from storage", interfaces.ErrUploadNotFound.Wrap(&external.Error{Status: http.StatusNotFound, Message: "File not found"}), http.StatusUnprocessableEntity, "UPLOAD_NOT_FOUND", ""},
+		{"file uploaded elsewhere", interfaces.ErrUploadMismatch, http.StatusUnprocessableEntity, "UPLOAD_MISMATCH", ""},
+		{"storage server error", &external.Error{Status: http.StatusInternalServerError, Message: "Internal error"}, http.StatusBadGateway, "STORAGE_FAILED", ""},
+		{"storage unreachable", &external.Error{Message: "failed to send request", Err: errors.New("connection refused")}, http.StatusBadGateway, "STORAGE_FAILED", ""},
+		{"storage circuit open", external.ErrServiceUnavailable, http.StatusServiceUnavailable, "STORAGE_UNAVAILABLE", ""},
+		{"storage circuit open until the cooldown ends", &external.UnavailableError{RetryAfter: 1500 * time.Millisecond}, http.StatusServiceUnavailable, "STORAGE_UNAVAILABLE", "2"},
+		{"database failure", errors.New("connection reset"), http.StatusInternalServerError, "INTERNAL_ERROR", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			respHelper := responses.NewResponseHelper()
+			handler := NewMediaHandler(&fakeUploadService{err: tt.err}, respHelper)
+			router := gin.New()
+			router.Use(middlewares.CaseConverterMiddleware(), middlewares.ErrorHandler(respHelper, zap.NewNop()))
+			router.POST("/media/confirm", handler.ConfirmUpload)
+
+			body := `{"fileId":"file-1","token":"token","expire":1767225600,"receipt":"ab12"}`
+			req := httptest.NewRequest(http.MethodPost, "/media/confirm", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", responses.ProblemContentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			var problem responses.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != tt.wantCode || problem.Status != tt.wantStatus {
+				t.Errorf("problem = %s, want code %s", w.Body.String(), tt.wantCode)
+			}
+			if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, responses.ProblemContentType) {
+				t.Errorf("Content-Type = %q, want %s", contentType, responses.ProblemContentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/utils/mergepatch"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// applyMergePatch merges the request body, a JSON merge patch, into current and
+// decodes the result into target. It records the error and returns false when
+// the body is not a merge patch applicable to current.
+func applyMergePatch(c *gin.Context, current interface{}, target interface{}) bool {
+	if contentType := c.ContentType(); contentType != mergepatch.ContentType && contentType != binding.MIMEJSON {
+		_ = c.Error(middlewares.NewUnsupportedMediaTypeError("Unsupported media type", "Send the patch as "+mergepatch.ContentType))
+		return false
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		_ = c.Error(middlewares.NewBadRequestError("Invalid request format", err.Error()))
+		return false
+	}
+	document, err := json.Marshal(current)
+	if err != nil {
+		_ = c.Error(middlewares.NewInternalError("Failed to apply patch", err.Error()))
+		return false
+	}
+	merged, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		_ = c.Error(middlewares.NewBadRequestError("Invalid request format", err.Error()))
+		return false
+	}
+	if err := json.Unmarshal(merged, target); err != nil {
+		_ = c.Error(middlewares.NewBadRequestError("Invalid request format", err.Error()))
+		return false
+	}
+	return true
+}
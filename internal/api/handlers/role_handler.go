@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/validators"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RoleHandler handles role, permission and role assignment requests
+type RoleHandler struct {
+	roleService interfaces.RoleService
+	respHelper  *responses.ResponseHelper
+	validator   *validators.Validator
+}
+
+// NewRoleHandler creates a new instance of RoleHandler
+func NewRoleHandler(
+	roleService interfaces.RoleService,
+	respHelper *responses.ResponseHelper,
+) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		respHelper:  respHelper,
+		validator:   validators.NewValidator(),
+	}
+}
+
+// GetAllRoles handles the request to list roles
+func (h *RoleHandler) GetAllRoles(c *gin.Context) {
+	roles, err := h.roleService.GetAllRoles(c)
+	if err != nil {
+		_ = c.Error(middlewares.NewInternalError("Failed to retrieve roles", err.Error()))
+		return
+	}
+
+	h.respHelper.OkResponse(c, roles, "Roles retrieved successfully")
+}
+
+// GetRole handles the request to get a role
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.roleService.FindRole(c, c.Param("id"))
+	if err != nil {
+		h.sendRoleError(c, "Failed to retrieve role", err)
+		return
+	}
+
+	h.respHelper.OkResponse(c, role, "Role retrieved successfully")
+}
+
+// CreateRole handles the request to create a role
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var request requests.RoleCreateRequest
+	if !h.bind(c, &request) {
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c, request)
+	if err != nil {
+		h.sendRoleError(c, "Failed to create role", err)
+		return
+	}
+
+	h.respHelper.CreatedResponse(c, role, "Role created successfully")
+}
+
+// UpdateRole handles the request to update a role
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var request requests.RoleUpdateRequest
+	if !h.bind(c, &request) {
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(c, c.Param("id"), request)
+	if err != nil {
+		h.sendRoleError(c, "Failed to update role", err)
+		return
+	}
+
+	h.respHelper.OkResponse(c, role, "Role updated successfully")
+}
+
+// DeleteRole handles the request to delete a role
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.roleService.DeleteRole(c, c.Param("id")); err != nil {
+		h.sendRoleError(c, "Failed to delete role", err)
+		return
+	}
+
+	h.respHelper.SendSuccess(c, "Role deleted successfully", http.StatusOK)
+}
+
+// GetAllPermissions handles the request to list the permissions that can be granted
+func (h *RoleHandler) GetAllPermissions(c *gin.Context) {
+	h.respHelper.OkResponse(c, h.roleService.GetAllPermissions(c), "Permissions retrieved successfully")
+}
+
+// SetUserRoles handles the request to replace the roles of a user
+func (h *RoleHandler) SetUserRoles(c *gin.Context) {
+	var request requests.UserRolesRequest
+	if !h.bind(c, &request) {
+		return
+	}
+
+	user, err := h.roleService.SetUserRoles(c, c.Param("id"), request)
+	if err != nil {
+		h.sendRoleError(c, "Failed to assign roles", err)
+		return
+	}
+
+	h.respHelper.OkResponse(c, user, "Roles assigned successfully")
+}
+
+// bind parses and validates a JSON request, reporting failures through the error handler
+func (h *RoleHandler) bind(c *gin.Context, request interface{}) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		_ = c.Error(middlewares.NewValidationError("Invalid request format", err.Error()))
+		return false
+	}
+
+	if err := h.validator.Struct(request); err != nil {
+		validationErrors := h.validator.GenerateValidationErrors(err)
+		_ = c.Error(middlewares.NewValidationError("Validation failed", "Request validation failed", validationErrors))
+		return false
+	}
+
+	return true
+}
+
+// sendRoleError maps role service errors to responses
+func (h *RoleHandler) sendRoleError(c *gin.Context, message string, err error) {
+	var unknownErr *interfaces.UnknownPermissionError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		_ = c.Error(middlewares.NewNotFoundError(message, "Role or user not found"))
+	case errors.As(err, &unknownErr):
+		_ = c.Error(middlewares.NewValidationError(message, err.Error(), unknownErr.Permissions))
+	default:
+		_ = c.Error(middlewares.NewServiceError(message, err))
+	}
+}
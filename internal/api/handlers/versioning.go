@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// setVersionETag sends a record version as a strong ETag clients can return in If-Match
+func setVersionETag(c *gin.Context, version int64) {
+	if version > 0 {
+		c.Header("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+	}
+}
+
+// expectedVersion returns the version an update is based on, taken from If-Match
+// or else the request's version field. "If-Match: *" returns zero, which skips
+// the version check. It records a precondition required error and returns false
+// when the version is missing, or a precondition failed error when If-Match
+// holds no version ETag.
+func expectedVersion(c *gin.Context, requestVersion int64) (int64, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	switch {
+	case ifMatch == "*":
+		return 0, true
+	case ifMatch != "":
+		// Weak ETags never match If-Match, so only quoted versions are accepted
+		version, err := strconv.ParseInt(strings.Trim(ifMatch, `"`), 10, 64)
+		if err != nil || version < 1 || !strings.HasPrefix(ifMatch, `"`) {
+			_ = c.Error(domain.PreconditionFailed("INVALID_IF_MATCH", "Precondition failed").WithDetail("If-Match must be the ETag of the record"))
+			return 0, false
+		}
+		return version, true
+	case requestVersion > 0:
+		return requestVersion, true
+	default:
+		_ = c.Error(middlewares.NewPreconditionRequiredError("Version required", "Send the version being updated in the If-Match header or the version field"))
+		return 0, false
+	}
+}
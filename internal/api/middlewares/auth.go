@@ -0,0 +1,101 @@
+package middlewares
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/service/interfaces"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ContextUserKey is the gin context key holding the authenticated user
+	ContextUserKey = "auth_user"
+	// ContextApiKeyKey is the gin context key holding the authenticated API key
+	ContextApiKeyKey = "auth_api_key"
+)
+
+// Authenticate is a middleware that requires either an "Authorization: Bearer" access token
+// or an "Authorization: ApiKey" key, and stores the authenticated user or key in the gin context
+func Authenticate(authService interfaces.AuthService, apiKeyService interfaces.ApiKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, credentials, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		credentials = strings.TrimSpace(credentials)
+		if credentials == "" {
+			scheme = ""
+		}
+
+		switch strings.ToLower(scheme) {
+		case "bearer":
+			user, err := authService.Authenticate(c, credentials)
+			if err != nil {
+				abortAuthentication(c, err)
+				return
+			}
+			c.Set(ContextUserKey, user)
+		case "apikey":
+			key, err := apiKeyService.Authenticate(c, credentials)
+			if err != nil {
+				abortAuthentication(c, err)
+				return
+			}
+			c.Set(ContextApiKeyKey, key)
+		default:
+			_ = c.Error(NewUnauthorizedError("Unauthenticated", "A bearer access token or API key is required"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// abortAuthentication reports a failed authentication through the error handler
+func abortAuthentication(c *gin.Context, err error) {
+	var rateLimitErr *interfaces.RateLimitedError
+	switch {
+	case errors.Is(err, interfaces.ErrInvalidToken), errors.Is(err, interfaces.ErrInvalidApiKey):
+		_ = c.Error(NewUnauthorizedError("Unauthenticated", err.Error()))
+	case errors.As(err, &rateLimitErr):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))))
+		_ = c.Error(NewTooManyRequestsError("Too many requests", err.Error()))
+	default:
+		_ = c.Error(NewInternalError("Failed to authenticate", err.Error()))
+	}
+	c.Abort()
+}
+
+// CurrentUser returns the user authenticated by the Authenticate middleware
+func CurrentUser(c *gin.Context) (dto.UserDTO, bool) {
+	value, ok := c.Get(ContextUserKey)
+	if !ok {
+		return dto.UserDTO{}, false
+	}
+	user, ok := value.(dto.UserDTO)
+	return user, ok
+}
+
+// CurrentApiKey returns the API key authenticated by the Authenticate middleware
+func CurrentApiKey(c *gin.Context) (dto.ApiKeyDTO, bool) {
+	value, ok := c.Get(ContextApiKeyKey)
+	if !ok {
+		return dto.ApiKeyDTO{}, false
+	}
+	key, ok := value.(dto.ApiKeyDTO)
+	return key, ok
+}
+
+// GrantedPermissions returns the permissions of the authenticated user, or the scopes of the
+// authenticated API key, and false when the request is not authenticated
+func GrantedPermissions(c *gin.Context) ([]string, bool) {
+	if user, ok := CurrentUser(c); ok {
+		return user.Permissions, true
+	}
+	if key, ok := CurrentApiKey(c); ok {
+		return key.Scopes, true
+	}
+	return nil, false
+}
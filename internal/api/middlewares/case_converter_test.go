@@ -0,0 +1,174 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/utils/keycase"
+	"beautyessentials.com/internal/validators"
+	"github.com/gin-gonic/gin"
+)
+
+// newCaseTestRouter serves a media document, a validation error and an echo of
+// the request body behind the case converter
+func newCaseTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	respHelper := responses.NewResponseHelper()
+
+	router := gin.New()
+	router.Use(CaseConverterMiddleware())
+	router.GET("/media", func(c *gin.Context) {
+		respHelper.OkResponse(c, map[string]interface{}{
+			"media_id":  "01J0000000000000000000MED1",
+			"thumb_url": "https://ik.imagekit.io/demo/a.png",
+			"tags":      []map[string]interface{}{{"tag_name": "summer"}},
+		}, "Media retrieved successfully")
+	})
+	router.GET("/invalid", func(c *gin.Context) {
+		respHelper.ValidationError(c, []validators.ValidationError{{Field: "media_id", Message: "media_id is required"}}, "Validation failed")
+	})
+	router.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "text/plain", body)
+	})
+	return router
+}
+
+func TestCaseConverterResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		header   map[string]string
+		wantCase keycase.Case
+		wantBody []string
+	}{
+		{
+			name:     "camelCase by default",
+			url:      "/media",
+			wantCase: keycase.Camel,
+			wantBody: []string{`"mediaId":`, `"thumbUrl":`, `"tagName":"summer"`},
+		},
+		{
+			name:     "snake_case on request",
+			url:      "/media",
+			header:   map[string]string{"Accept-Case": "snake_case"},
+			wantCase: keycase.Snake,
+			wantBody: []string{`"media_id":`, `"thumb_url":`, `"tag_name":"summer"`},
+		},
+		{
+			name:     "malformed Accept-Case falls back to the default",
+			url:      "/media",
+			header:   map[string]string{"Accept-Case": "kebab-case"},
+			wantCase: keycase.Camel,
+			wantBody: []string{`"mediaId":`, `"thumbUrl":`},
+		},
+		{
+			name:     "query flag wins over the header",
+			url:      "/media?case=snake",
+			header:   map[string]string{"Accept-Case": "camelCase"},
+			wantCase: keycase.Snake,
+			wantBody: []string{`"media_id":`},
+		},
+		{
+			name:     "validation errors keep their field names",
+			url:      "/invalid",
+			wantCase: keycase.Camel,
+			wantBody: []string{`"field":"media_id"`, `"message":"media_id is required"`},
+		},
+		{
+			name:     "problem details keep their field names",
+			url:      "/invalid",
+			header:   map[string]string{"Accept": responses.ProblemContentType},
+			wantCase: keycase.Camel,
+			wantBody: []string{`"field":"media_id"`, `"message":"media_id is required"`, `"status":422`},
+		},
+	}
+
+	router := newCaseTestRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get(keycase.ResponseHeader); got != string(tt.wantCase) {
+				t.Errorf("%s = %q, want %q", keycase.ResponseHeader, got, tt.wantCase)
+			}
+			if vary := w.Header().Values("Vary"); len(vary) == 0 || vary[0] != keycase.Header {
+				t.Errorf("Vary = %v, want %s", vary, keycase.Header)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("body %s does not contain %s", w.Body.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestCaseConverterRequestBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "camelCase keys are converted",
+			contentType: "application/json",
+			body:        `{"mediaIds":["a"],"folderId":"f","nested":[{"thumbUrl":"u"}]}`,
+			want:        `{"media_ids":["a"],"folder_id":"f","nested":[{"thumb_url":"u"}]}`,
+		},
+		{
+			name:        "snake_case keys are kept",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"media_ids":["a"]}`,
+			want:        `{"media_ids":["a"]}`,
+		},
+		{
+			name:        "values are kept",
+			contentType: "application/json",
+			body:        `{"name":"mediaId","tags":["thumbUrl"]}`,
+			want:        `{"name":"mediaId","tags":["thumbUrl"]}`,
+		},
+		{
+			name:        "merge patches are converted",
+			contentType: "application/merge-patch+json",
+			body:        `{"mediaId":null}`,
+			want:        `{"media_id":null}`,
+		},
+		{
+			name:        "malformed JSON is passed on",
+			contentType: "application/json",
+			body:        `{"mediaId":`,
+			want:        `{"mediaId":`,
+		},
+		{
+			name:        "other media types are passed on",
+			contentType: "text/plain",
+			body:        `{"mediaId":"1"}`,
+			want:        `{"mediaId":"1"}`,
+		},
+	}
+
+	router := newCaseTestRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Body.String() != tt.want {
+				t.Errorf("handler read %s, want %s", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
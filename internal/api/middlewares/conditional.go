@@ -0,0 +1,128 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"beautyessentials.com/internal/utils/keycase"
+	"github.com/gin-gonic/gin"
+)
+
+// Validator returns an opaque tag that changes whenever the data a route
+// serves does, and when that data last changed (zero when unknown)
+type Validator func(c *gin.Context) (tag string, lastModified time.Time, err error)
+
+// CachePolicy configures conditional request handling of a route
+type CachePolicy struct {
+	// CacheControl is sent with successful responses; empty leaves the header unset
+	CacheControl string
+	// Validator lets unchanged data be answered with 304 before the handler runs;
+	// without one the ETag is a hash of the response body
+	Validator Validator
+}
+
+// Conditional is a middleware that sends weak ETag, Last-Modified and
+// Cache-Control headers on successful GET responses and answers requests whose
+// If-None-Match or If-Modified-Since still match with 304 Not Modified
+func Conditional(policy CachePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		// A cheap version check spares the handler for unchanged data
+		var etag string
+		var lastModified time.Time
+		if policy.Validator != nil {
+			if tag, modified, err := policy.Validator(c); err == nil {
+				etag = weakETag(c, tag)
+				lastModified = modified.UTC().Truncate(time.Second)
+				if notModified(c.Request, etag, lastModified) {
+					setCacheHeaders(c.Writer.Header(), policy.CacheControl, etag, lastModified)
+					c.Status(http.StatusNotModified)
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		// Buffer the response so validators are only sent with successful ones
+		writer := &responseBodyWriter{
+			ResponseWriter: c.Writer,
+			body:           &bytes.Buffer{},
+		}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		if unanswered(c, writer) {
+			return
+		}
+		status := writer.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if status == http.StatusOK {
+			if etag == "" {
+				sum := sha256.Sum256(writer.body.Bytes())
+				etag = weakETag(c, hex.EncodeToString(sum[:]))
+			}
+			setCacheHeaders(writer.Header(), policy.CacheControl, etag, lastModified)
+			if notModified(c.Request, etag, lastModified) {
+				// A 304 has no body, so drop the headers describing one
+				writer.Header().Del("Content-Type")
+				writer.Header().Del("Content-Length")
+				writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		writer.ResponseWriter.WriteHeader(status)
+		_, _ = writer.ResponseWriter.Write(writer.body.Bytes())
+	}
+}
+
+// weakETag scopes a tag to the route, query and key casing, e.g. sparse fieldsets,
+// so different representations of the same data never match
+func weakETag(c *gin.Context, tag string) string {
+	keyCase := keycase.FromContext(c.Request.Context())
+	sum := sha256.Sum256([]byte(c.Request.Method + " " + c.FullPath() + "?" + c.Request.URL.RawQuery + " " + string(keyCase) + "\x00" + tag))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// setCacheHeaders sets the validators and caching policy of a response
+func setCacheHeaders(header http.Header, cacheControl string, etag string, lastModified time.Time) {
+	header.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if cacheControl != "" {
+		header.Set("Cache-Control", cacheControl)
+	}
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since as RFC 9110 requires
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !lastModified.After(since)
+}
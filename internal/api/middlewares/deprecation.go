@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation schedules the retirement of a route
+type Deprecation struct {
+	// Since is when the route was deprecated
+	Since time.Time
+	// Sunset is when the route stops being served, if already decided
+	Sunset time.Time
+	// Link points at migration notes
+	Link string
+}
+
+// Deprecated is a middleware that announces a route's deprecation through the
+// Deprecation, Sunset and Link response headers
+func Deprecated(deprecation Deprecation) gin.HandlerFunc {
+	since := "@" + strconv.FormatInt(deprecation.Since.Unix(), 10)
+	var sunset string
+	if !deprecation.Sunset.IsZero() {
+		sunset = deprecation.Sunset.UTC().Format(http.TimeFormat)
+	}
+	var link string
+	if deprecation.Link != "" {
+		link = "<" + deprecation.Link + `>; rel="deprecation"`
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("Deprecation", since)
+		if sunset != "" {
+			header.Set("Sunset", sunset)
+		}
+		if link != "" {
+			header.Add("Link", link)
+		}
+
+		c.Next()
+	}
+}
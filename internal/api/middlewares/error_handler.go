@@ -2,11 +2,15 @@ package middlewares
 
 import (
 	"errors"
-	"log"
 	"net/http"
+	"time"
 
 	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/domain"
+	"beautyessentials.com/internal/service/external"
+	"beautyessentials.com/internal/utils/logging"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // ErrorType represents different types of errors
@@ -23,6 +27,18 @@ const (
 	ErrorTypeUnauthorized ErrorType = "UNAUTHORIZED"
 	// ErrorTypeForbidden represents forbidden errors
 	ErrorTypeForbidden ErrorType = "FORBIDDEN"
+	// ErrorTypeTooManyRequests represents rate limited requests
+	ErrorTypeTooManyRequests ErrorType = "TOO_MANY_REQUESTS"
+	// ErrorTypeBadRequest represents request bodies that cannot be read
+	ErrorTypeBadRequest ErrorType = "BAD_REQUEST"
+	// ErrorTypeUnsupportedMediaType represents request bodies of the wrong media type
+	ErrorTypeUnsupportedMediaType ErrorType = "UNSUPPORTED_MEDIA_TYPE"
+	// ErrorTypePreconditionRequired represents updates sent without the version they are based on
+	ErrorTypePreconditionRequired ErrorType = "PRECONDITION_REQUIRED"
+	// ErrorTypeStorageUnavailable represents a media storage provider that is temporarily unavailable
+	ErrorTypeStorageUnavailable ErrorType = "STORAGE_UNAVAILABLE"
+	// ErrorTypeStorageFailed represents a media storage provider that failed or could not be reached
+	ErrorTypeStorageFailed ErrorType = "STORAGE_FAILED"
 )
 
 // AppError represents an application error
@@ -31,6 +47,8 @@ type AppError struct {
 	Message     string
 	Description string
 	Data        interface{}
+	// Err is the underlying error; a domain error in it decides the response
+	Err error
 }
 
 // Error implements the error interface
@@ -38,6 +56,11 @@ func (e AppError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying error
+func (e AppError) Unwrap() error {
+	return e.Err
+}
+
 // NewValidationError creates a new validation error
 func NewValidationError(message string, description string, data ...interface{}) AppError {
 	var errorData interface{}
@@ -70,6 +93,17 @@ func NewNotFoundError(message string, description string) AppError {
 	}
 }
 
+// NewServiceError wraps an error returned by a service. Domain errors are answered
+// with the status and code of their kind, any other error with a 500 and message.
+func NewServiceError(message string, err error) AppError {
+	return AppError{
+		Type:        ErrorTypeInternal,
+		Message:     message,
+		Description: err.Error(),
+		Err:         err,
+	}
+}
+
 // NewUnauthorizedError creates a new unauthorized error
 func NewUnauthorizedError(message string, description string) AppError {
 	return AppError{
@@ -88,8 +122,44 @@ func NewForbiddenError(message string, description string) AppError {
 	}
 }
 
+// NewTooManyRequestsError creates a new rate limit error
+func NewTooManyRequestsError(message string, description string) AppError {
+	return AppError{
+		Type:        ErrorTypeTooManyRequests,
+		Message:     message,
+		Description: description,
+	}
+}
+
+// NewBadRequestError creates a new bad request error
+func NewBadRequestError(message string, description string) AppError {
+	return AppError{
+		Type:        ErrorTypeBadRequest,
+		Message:     message,
+		Description: description,
+	}
+}
+
+// NewUnsupportedMediaTypeError creates a new unsupported media type error
+func NewUnsupportedMediaTypeError(message string, description string) AppError {
+	return AppError{
+		Type:        ErrorTypeUnsupportedMediaType,
+		Message:     message,
+		Description: description,
+	}
+}
+
+// NewPreconditionRequiredError creates a new precondition required error
+func NewPreconditionRequiredError(message string, description string) AppError {
+	return AppError{
+		Type:        ErrorTypePreconditionRequired,
+		Message:     message,
+		Description: description,
+	}
+}
+
 // ErrorHandler is a middleware that handles errors
-func ErrorHandler(respHelper *responses.ResponseHelper) gin.HandlerFunc {
+func ErrorHandler(respHelper *responses.ResponseHelper, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Process request
 		c.Next()
@@ -100,23 +170,45 @@ func ErrorHandler(respHelper *responses.ResponseHelper) gin.HandlerFunc {
 			err := c.Errors.Last().Err
 			
 			// Log the error
-			log.Printf("Error: %v", err)
+			logging.For(c.Request.Context(), logger).Warn("request error", zap.Error(err))
 			
-			// Handle different types of errors
+			// Handle different types of errors; storage and domain errors decide
+			// the response even when wrapped in an application error
 			var appErr AppError
-			if errors.As(err, &appErr) {
+			var storageErr *external.Error
+			if errors.Is(err, external.ErrServiceUnavailable) {
+				// Tell the caller when the storage circuit closes again, if known
+				var unavailable *external.UnavailableError
+				var retryAfter time.Duration
+				if errors.As(err, &unavailable) {
+					retryAfter = unavailable.RetryAfter
+				}
+				respHelper.ServiceUnavailable(c, string(ErrorTypeStorageUnavailable), retryAfter, "Media storage is temporarily unavailable", err.Error())
+			} else if domainErr, ok := domain.As(err); ok {
+				respHelper.SendCodedError(c, domainErr.Code, domainErr.Message, domainErr.Detail, domainStatus(domainErr.Kind), domainErr.Data)
+			} else if errors.As(err, &storageErr) {
+				respHelper.SendCodedError(c, string(ErrorTypeStorageFailed), "Media storage failed", err.Error(), http.StatusBadGateway)
+			} else if errors.As(err, &appErr) {
 				// Handle application errors
 				switch appErr.Type {
 				case ErrorTypeValidation:
-					respHelper.SendError(c, appErr.Message, appErr.Description, http.StatusUnprocessableEntity, appErr.Data)
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusUnprocessableEntity, appErr.Data)
 				case ErrorTypeInternal:
-					respHelper.SendError(c, appErr.Message, appErr.Description, http.StatusInternalServerError)
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusInternalServerError)
 				case ErrorTypeNotFound:
-					respHelper.SendError(c, appErr.Message, appErr.Description, http.StatusNotFound)
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusNotFound)
 				case ErrorTypeUnauthorized:
-					respHelper.SendError(c, appErr.Message, appErr.Description, http.StatusUnauthorized)
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusUnauthorized)
 				case ErrorTypeForbidden:
-					respHelper.SendError(c, appErr.Message, appErr.Description, http.StatusForbidden)
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusForbidden)
+				case ErrorTypeTooManyRequests:
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusTooManyRequests)
+				case ErrorTypeBadRequest:
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusBadRequest)
+				case ErrorTypeUnsupportedMediaType:
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusUnsupportedMediaType)
+				case ErrorTypePreconditionRequired:
+					respHelper.SendCodedError(c, string(appErr.Type), appErr.Message, appErr.Description, http.StatusPreconditionRequired)
 				default:
 					respHelper.SendError(c, "An unexpected error occurred", err.Error(), http.StatusInternalServerError)
 				}
@@ -126,4 +218,20 @@ func ErrorHandler(respHelper *responses.ResponseHelper) gin.HandlerFunc {
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+// domainStatus returns the HTTP status domain errors of a kind are answered with
+func domainStatus(kind domain.Kind) int {
+	switch kind {
+	case domain.KindNotFound:
+		return http.StatusNotFound
+	case domain.KindConflict:
+		return http.StatusConflict
+	case domain.KindValidation:
+		return http.StatusUnprocessableEntity
+	case domain.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
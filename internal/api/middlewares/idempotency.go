@@ -0,0 +1,158 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/keycase"
+	"beautyessentials.com/internal/utils/logging"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header
+const maxIdempotencyKeyLength = 255
+
+// idempotencyClaimKey is the context key of the Idempotency-Key claimed by a request
+const idempotencyClaimKey = "idempotencyClaim"
+
+// idempotencyClaim identifies a claimed key, whose outcome IdempotencyRecorder records
+type idempotencyClaim struct {
+	scope string
+	key   string
+}
+
+// Idempotency is a middleware that makes POST requests sent with an Idempotency-Key
+// header safe to retry. The first response of a key is stored and replayed, marked
+// with Idempotent-Replayed, to retries with the same body; reusing the key with a
+// different body gets a 422 and a retry racing the first request gets a 409.
+// Responses are stored by IdempotencyRecorder once they are final. It must run
+// after Authenticate so keys of different principals never collide.
+func Idempotency(respHelper *responses.ResponseHelper, logger *zap.Logger, idempotencyService interfaces.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if idempotencyService == nil || c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respHelper.SendError(c, "Invalid idempotency key", "Idempotency-Key must be at most 255 characters", http.StatusBadRequest)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respHelper.SendError(c, "Invalid request", "The request body could not be read", http.StatusBadRequest)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+		scope := KeyByPrincipal(c) + " " + c.Request.Method + " " + c.Request.URL.Path
+
+		stored, err := idempotencyService.Begin(c, scope, key, fingerprint)
+		switch {
+		case errors.Is(err, interfaces.ErrIdempotencyKeyReused):
+			respHelper.SendError(c, "Idempotency key reused", err.Error(), http.StatusUnprocessableEntity)
+			c.Abort()
+			return
+		case errors.Is(err, interfaces.ErrIdempotencyKeyInProgress):
+			respHelper.SendError(c, "Request in progress", err.Error(), http.StatusConflict)
+			c.Abort()
+			return
+		case err != nil:
+			// Fail open so an unavailable store only costs retries their protection
+			logging.For(c.Request.Context(), logger).Error("idempotency store error", zap.Error(err))
+			c.Next()
+			return
+		case stored != nil:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(stored.StatusCode, stored.ContentType, replayBody(c, stored))
+			c.Abort()
+			return
+		}
+
+		c.Set(idempotencyClaimKey, idempotencyClaim{scope: scope, key: key})
+		c.Next()
+	}
+}
+
+// IdempotencyRecorder is a middleware that records the outcome of requests whose
+// Idempotency-Key was claimed by Idempotency. The final response is stored unless
+// it is a server error, which releases the key so the request can be retried. It
+// must run outside ErrorHandler and Recovery so the responses they send are the
+// ones stored.
+func IdempotencyRecorder(logger *zap.Logger, idempotencyService interfaces.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if idempotencyService == nil || c.Request.Method != http.MethodPost || c.GetHeader("Idempotency-Key") == "" {
+			c.Next()
+			return
+		}
+
+		// Buffer the response so it can be stored before it is sent
+		writer := &responseBodyWriter{
+			ResponseWriter: c.Writer,
+			body:           &bytes.Buffer{},
+		}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+		status := writer.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if value, ok := c.Get(idempotencyClaimKey); ok {
+			claim := value.(idempotencyClaim)
+
+			// The outcome is recorded even when the client has gone away, as its retry will ask for it
+			ctx := context.WithoutCancel(c.Request.Context())
+			var err error
+			if status >= http.StatusInternalServerError {
+				err = idempotencyService.Release(ctx, claim.scope, claim.key)
+			} else {
+				err = idempotencyService.Complete(ctx, claim.scope, claim.key, dto.IdempotentResponseDTO{
+					StatusCode:  status,
+					ContentType: writer.Header().Get("Content-Type"),
+					Body:        writer.body.Bytes(),
+				})
+			}
+			if err != nil {
+				logging.For(c.Request.Context(), logger).Error("idempotency store error", zap.Error(err))
+			}
+		}
+
+		writer.ResponseWriter.WriteHeader(status)
+		_, _ = writer.ResponseWriter.Write(writer.body.Bytes())
+	}
+}
+
+// replayBody returns a stored response body with its JSON keys in the casing
+// negotiated for the retry, which may differ from the first request's
+func replayBody(c *gin.Context, stored *dto.IdempotentResponseDTO) []byte {
+	if !strings.Contains(stored.ContentType, "json") || len(stored.Body) > keycase.MaxRewriteSize || !json.Valid(stored.Body) {
+		return stored.Body
+	}
+	keyCase := keycase.FromContext(c.Request.Context())
+	converted := &bytes.Buffer{}
+	if err := keycase.Rewrite(converted, stored.Body, keyCase); err != nil {
+		return stored.Body
+	}
+	c.Header(keycase.ResponseHeader, string(keyCase))
+	return converted.Bytes()
+}
@@ -0,0 +1,266 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/domain"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/service/interfaces"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// memoryIdempotency keeps idempotency keys in memory
+type memoryIdempotency struct {
+	interfaces.IdempotencyService
+
+	mu   sync.Mutex
+	keys map[string]*idempotencyRecord
+}
+
+type idempotencyRecord struct {
+	fingerprint string
+	response    *dto.IdempotentResponseDTO
+}
+
+func newMemoryIdempotency() *memoryIdempotency {
+	return &memoryIdempotency{keys: make(map[string]*idempotencyRecord)}
+}
+
+func (s *memoryIdempotency) Begin(ctx context.Context, scope string, key string, fingerprint string) (*dto.IdempotentResponseDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.keys[scope+" "+key]
+	switch {
+	case !ok:
+		s.keys[scope+" "+key] = &idempotencyRecord{fingerprint: fingerprint}
+		return nil, nil
+	case record.fingerprint != fingerprint:
+		return nil, interfaces.ErrIdempotencyKeyReused
+	case record.response == nil:
+		return nil, interfaces.ErrIdempotencyKeyInProgress
+	default:
+		return record.response, nil
+	}
+}
+
+func (s *memoryIdempotency) Complete(ctx context.Context, scope string, key string, response dto.IdempotentResponseDTO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[scope+" "+key].response = &response
+	return nil
+}
+
+func (s *memoryIdempotency) Release(ctx context.Context, scope string, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, scope+" "+key)
+	return nil
+}
+
+// held reports whether a key of the test client is claimed or completed
+func (s *memoryIdempotency) held(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.keys {
+		if strings.HasSuffix(k, " "+key) {
+			return true
+		}
+	}
+	return false
+}
+
+// idempotencyTestRouter serves POST /orders behind the idempotency middleware,
+// answering with status and counting the requests that reach the handler
+type idempotencyTestRouter struct {
+	*gin.Engine
+
+	mu      sync.Mutex
+	status  int
+	fail    error
+	handled int
+	// entered, when set, is closed as the handler starts, which then waits for unblock
+	entered chan struct{}
+	unblock chan struct{}
+}
+
+func newIdempotencyTestRouter(service interfaces.IdempotencyService) *idempotencyTestRouter {
+	gin.SetMode(gin.TestMode)
+	respHelper := responses.NewResponseHelper()
+	router := &idempotencyTestRouter{Engine: gin.New(), status: http.StatusCreated}
+
+	router.Use(CaseConverterMiddleware(), IdempotencyRecorder(zap.NewNop(), service), ErrorHandler(respHelper, zap.NewNop()), Recovery(respHelper, zap.NewNop()))
+	router.POST("/orders", Idempotency(respHelper, zap.NewNop(), service), func(c *gin.Context) {
+		router.mu.Lock()
+		router.handled++
+		order, status, fail, entered, unblock := router.handled, router.status, router.fail, router.entered, router.unblock
+		router.mu.Unlock()
+
+		if entered != nil {
+			close(entered)
+			<-unblock
+		}
+		if fail != nil {
+			_ = c.Error(NewServiceError("Failed to create order", fail))
+			return
+		}
+		respHelper.SendResponse(c, gin.H{"order_id": order}, "Order created", status)
+	})
+	return router
+}
+
+// post sends body with the Idempotency-Key key
+func (r *idempotencyTestRouter) post(key string, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysTheFirstResponse(t *testing.T) {
+	router := newIdempotencyTestRouter(newMemoryIdempotency())
+
+	first := router.post("key-1", `{"item":"serum"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want %d; body %s", first.Code, http.StatusCreated, first.Body.String())
+	}
+
+	retry := router.post("key-1", `{"item":"serum"}`)
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %s, want the first response %d %s", retry.Code, retry.Body.String(), first.Code, first.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("retry is not marked Idempotent-Replayed")
+	}
+	if router.handled != 1 {
+		t.Errorf("handler ran %d times, want once", router.handled)
+	}
+
+	// The replay follows the casing asked for by the retry
+	snake := router.post("key-1", `{"item":"serum"}`, "Accept-Case", "snake_case")
+	if !strings.Contains(snake.Body.String(), `"order_id":1`) {
+		t.Errorf("snake_case retry body = %s, want order_id", snake.Body.String())
+	}
+
+	// Other keys are handled on their own
+	if other := router.post("key-2", `{"item":"serum"}`); other.Header().Get("Idempotent-Replayed") != "" || router.handled != 2 {
+		t.Errorf("request with another key was replayed")
+	}
+}
+
+func TestIdempotencyRejectsAKeyReusedWithAnotherBody(t *testing.T) {
+	router := newIdempotencyTestRouter(newMemoryIdempotency())
+
+	router.post("key-1", `{"item":"serum"}`)
+	reused := router.post("key-1", `{"item":"cream"}`)
+	if reused.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d; body %s", reused.Code, http.StatusUnprocessableEntity, reused.Body.String())
+	}
+	if router.handled != 1 {
+		t.Errorf("handler ran %d times, want once", router.handled)
+	}
+}
+
+func TestIdempotencyRejectsAConcurrentRetry(t *testing.T) {
+	router := newIdempotencyTestRouter(newMemoryIdempotency())
+	router.entered, router.unblock = make(chan struct{}), make(chan struct{})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- router.post("key-1", `{"item":"serum"}`) }()
+	<-router.entered
+
+	// The retry arrives while the first request is still being handled
+	router.mu.Lock()
+	router.entered = nil
+	router.mu.Unlock()
+	retry := router.post("key-1", `{"item":"serum"}`)
+	if retry.Code != http.StatusConflict {
+		t.Errorf("concurrent retry status = %d, want %d; body %s", retry.Code, http.StatusConflict, retry.Body.String())
+	}
+
+	close(router.unblock)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first status = %d, want %d", first.Code, http.StatusCreated)
+	}
+	if router.handled != 1 {
+		t.Errorf("handler ran %d times, want once", router.handled)
+	}
+}
+
+func TestIdempotencyStoresOnlyFinalOutcomes(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		fail       error
+		wantStatus int
+		wantStored bool
+	}{
+		{name: "success is stored", status: http.StatusCreated, wantStatus: http.StatusCreated, wantStored: true},
+		{name: "client error is stored", status: http.StatusBadRequest, wantStatus: http.StatusBadRequest, wantStored: true},
+		{name: "server error is released", status: http.StatusInternalServerError, wantStatus: http.StatusInternalServerError},
+		{name: "unavailable storage is released", status: http.StatusServiceUnavailable, wantStatus: http.StatusServiceUnavailable},
+		{name: "error answered by the error handler is stored", fail: domain.Conflict("ORDER_EXISTS", "Order exists"), wantStatus: http.StatusConflict, wantStored: true},
+		{name: "server error answered by the error handler is released", fail: errors.New("database is down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newMemoryIdempotency()
+			router := newIdempotencyTestRouter(service)
+			router.status, router.fail = tt.status, tt.fail
+
+			first := router.post("key-1", `{"item":"serum"}`)
+			if first.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", first.Code, tt.wantStatus, first.Body.String())
+			}
+			if first.Body.Len() == 0 {
+				t.Error("response has no body")
+			}
+			if stored := service.held("key-1"); stored != tt.wantStored {
+				t.Fatalf("key held after the response = %v, want %v", stored, tt.wantStored)
+			}
+
+			// A released key lets the retry through; a stored one replays the response
+			router.status, router.fail = http.StatusCreated, nil
+			retry := router.post("key-1", `{"item":"serum"}`)
+			if tt.wantStored {
+				if retry.Code != tt.wantStatus || router.handled != 1 {
+					t.Errorf("retry = %d after %d handled, want the replayed %d", retry.Code, router.handled, tt.wantStatus)
+				}
+				return
+			}
+			if retry.Code != http.StatusCreated || router.handled != 2 {
+				t.Errorf("retry = %d after %d handled, want a fresh 201", retry.Code, router.handled)
+			}
+		})
+	}
+}
+
+func TestIdempotencyIgnoresRequestsWithoutAKey(t *testing.T) {
+	router := newIdempotencyTestRouter(newMemoryIdempotency())
+
+	for i := 0; i < 2; i++ {
+		if w := router.post("", `{"item":"serum"}`); w.Header().Get("Idempotent-Replayed") != "" {
+			t.Fatal("request without a key was replayed")
+		}
+	}
+	if router.handled != 2 {
+		t.Errorf("handler ran %d times, want twice", router.handled)
+	}
+
+	if w := router.post(strings.Repeat("k", maxIdempotencyKeyLength+1), `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("overlong key status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
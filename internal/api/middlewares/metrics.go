@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"strconv"
+	"strings"
+	"time"
+
+	"beautyessentials.com/internal/utils/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// HTTP metrics, labelled by route template rather than path so IDs do not explode cardinality
+var (
+	httpRequestsTotal = metrics.NewCounterVec(
+		"http_requests_total",
+		"Number of HTTP requests handled, by method, route template and status.",
+		"method", "route", "status",
+	)
+	httpRequestDuration = metrics.NewHistogramVec(
+		"http_request_duration_seconds",
+		"Latency of HTTP requests, by method, route template and status.",
+		nil,
+		"method", "route", "status",
+	)
+)
+
+// Metrics is a middleware that records the count and latency of every request
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		// Unmatched paths share one label value
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequestsTotal.Inc(c.Request.Method, route, status)
+		httpRequestDuration.Observe(time.Since(start).Seconds(), c.Request.Method, route, status)
+	}
+}
+
+// MetricsToken is a middleware that requires "Authorization: Bearer <token>"; an empty
+// token rejects every request
+func MetricsToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, credentials, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		if token == "" || !strings.EqualFold(scheme, "bearer") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimSpace(credentials)), []byte(token)) != 1 {
+			_ = c.Error(NewUnauthorizedError("Unauthenticated", "A valid metrics token is required"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
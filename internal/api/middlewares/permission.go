@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission is a middleware that allows the request only when the authenticated user,
+// or API key, holds every listed permission. It must run after Authenticate.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, ok := GrantedPermissions(c)
+		if !ok {
+			_ = c.Error(NewUnauthorizedError("Unauthenticated", "Authentication is required"))
+			c.Abort()
+			return
+		}
+
+		held := make(map[string]bool, len(granted))
+		for _, permission := range granted {
+			held[permission] = true
+		}
+
+		var missing []string
+		for _, permission := range permissions {
+			if !held[permission] {
+				missing = append(missing, permission)
+			}
+		}
+
+		if len(missing) > 0 {
+			_ = c.Error(NewForbiddenError("Forbidden", "Missing permission: "+strings.Join(missing, ", ")))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireUser is a middleware that rejects requests authenticated with an API key,
+// for endpoints that act on the signed in user. It must run after Authenticate.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentUser(c); !ok {
+			_ = c.Error(NewForbiddenError("Forbidden", "This endpoint requires a user access token"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"beautyessentials.com/internal/utils/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID is a middleware that reuses a valid incoming X-Request-ID or generates one,
+// echoes it in the response and stores it in the request context for every layer to log
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"time"
+
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/utils/logging"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RequestLogger is a middleware that logs every request once it completes
+func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("http")
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := zapcore.InfoLevel
+		switch {
+		case status >= 500:
+			level = zapcore.ErrorLevel
+		case status >= 400:
+			level = zapcore.WarnLevel
+		}
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("route", c.FullPath()),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Int("bytes", c.Writer.Size()),
+		}
+		if user, ok := CurrentUser(c); ok {
+			fields = append(fields, zap.String("user_id", user.ID))
+		}
+		if key, ok := CurrentApiKey(c); ok {
+			fields = append(fields, zap.String("api_key_id", key.ID))
+		}
+
+		logging.For(c.Request.Context(), logger).Log(level, "request", fields...)
+	}
+}
+
+// Recovery is a middleware that turns panics into a logged 500 response
+func Recovery(respHelper *responses.ResponseHelper, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logging.For(c.Request.Context(), logger).Error("panic recovered",
+					zap.Any("panic", recovered),
+					zap.Stack("stack"),
+				)
+				respHelper.SendError(c, "An unexpected error occurred", "", 500)
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"bytes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseBodyWriter is a custom response writer that captures the response body,
+// for middlewares that inspect it before it is sent
+type responseBodyWriter struct {
+	gin.ResponseWriter
+	body   *bytes.Buffer
+	status int
+}
+
+// Write captures the response body
+func (r *responseBodyWriter) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return len(b), nil
+}
+
+// WriteHeader captures the status code
+func (r *responseBodyWriter) WriteHeader(statusCode int) {
+	r.status = statusCode
+}
+
+// WriteString captures the response body
+func (r *responseBodyWriter) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return len(s), nil
+}
+
+// unanswered reports whether the handlers left the response to the error handler,
+// recording errors without writing anything
+func unanswered(c *gin.Context, writer *responseBodyWriter) bool {
+	return len(c.Errors) > 0 && writer.status == 0 && writer.body.Len() == 0
+}
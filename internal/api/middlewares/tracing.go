@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"beautyessentials.com/internal/utils/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing is a middleware that records a server span for every request, continuing
+// the caller's trace when a traceparent header is present
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+
+		// Name spans by route template so they group across IDs
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx, span := tracing.Start(ctx, name,
+			tracing.WithKind(tracing.KindServer),
+			tracing.WithAttributes(
+				tracing.String("http.request.method", c.Request.Method),
+				tracing.String("http.route", route),
+				tracing.String("url.path", c.Request.URL.Path),
+				tracing.String("client.address", c.ClientIP()),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(tracing.Int("http.response.status_code", status))
+		if status >= 500 {
+			message := ""
+			if err := c.Errors.Last(); err != nil {
+				message = err.Error()
+			}
+			span.SetStatus(tracing.StatusError, message)
+		}
+	}
+}
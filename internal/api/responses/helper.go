@@ -1,9 +1,13 @@
 package responses
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"beautyessentials.com/internal/utils/requestid"
 	"beautyessentials.com/internal/validators"
 )
 
@@ -33,7 +37,7 @@ func (r *ResponseHelper) PaginatedResponse(c *gin.Context, data interface{}, mes
 		},
 	}
 
-	c.JSON(http.StatusOK, response)
+	renderJSON(c, http.StatusOK, response)
 }
 
 // NewResponseHelper creates a new ResponseHelper
@@ -56,6 +60,11 @@ func (h *ResponseHelper) SendError(c *gin.Context, message string, description s
 	SendError(c, message, description, code, data...)
 }
 
+// SendCodedError sends an error response with a machine-readable error code
+func (h *ResponseHelper) SendCodedError(c *gin.Context, errorCode string, message string, description string, status int, data ...interface{}) {
+	SendCodedError(c, errorCode, message, description, status, data...)
+}
+
 // SendSuccess sends a simple success message
 func (h *ResponseHelper) SendSuccess(c *gin.Context, message string, statusCode int) {
 	SendSuccess(c, message, statusCode)
@@ -74,10 +83,46 @@ func (h *ResponseHelper) CreatedResponse(c *gin.Context, data interface{}, messa
 
 // ValidationError sends a validation error response
 func (r *ResponseHelper) ValidationError(c *gin.Context, errors []validators.ValidationError, message string) {
+	if acceptsProblem(c) {
+		renderProblem(c, http.StatusUnprocessableEntity, ErrorResponse{
+			Code:      ErrorCode(http.StatusUnprocessableEntity),
+			Message:   message,
+			RequestID: requestid.FromContext(c.Request.Context()),
+		}, errors)
+		return
+	}
+
 	response := gin.H{
 		"success": false,
+		"code":    ErrorCode(http.StatusUnprocessableEntity),
 		"message": message,
 		"errors":  errors,
 	}
-	c.JSON(http.StatusUnprocessableEntity, response)
+	renderJSON(c, http.StatusUnprocessableEntity, response)
+}
+
+// SetRateLimitHeaders sets the X-RateLimit-* headers describing the caller's remaining quota
+func (h *ResponseHelper) SetRateLimitHeaders(c *gin.Context, limit int, remaining int, reset time.Duration) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+}
+
+// TooManyRequests sends a 429 response telling the caller when to retry
+func (h *ResponseHelper) TooManyRequests(c *gin.Context, retryAfter time.Duration, description string) {
+	c.Header("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+	SendError(c, "Too many requests", description, http.StatusTooManyRequests)
+}
+
+// ServiceUnavailable sends a 503 response, telling the caller when to retry if retryAfter is known
+func (h *ResponseHelper) ServiceUnavailable(c *gin.Context, errorCode string, retryAfter time.Duration, message string, description string) {
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+	}
+	SendCodedError(c, errorCode, message, description, http.StatusServiceUnavailable)
+}
+
+// ceilSeconds rounds a duration up to whole seconds, as HTTP headers expect
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
 }
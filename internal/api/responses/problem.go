@@ -0,0 +1,72 @@
+package responses
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iancoleman/strcase"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details, sent to
+// clients that list it in their Accept header
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document, extended with the members of
+// ErrorResponse
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+}
+
+// errorCodes are the error codes of statuses, matching the error types of the error handler
+var errorCodes = map[int]string{
+	http.StatusUnprocessableEntity: "VALIDATION_ERROR",
+	http.StatusInternalServerError: "INTERNAL_ERROR",
+}
+
+// ErrorCode returns the error code of errors without a more specific one, e.g.
+// NOT_FOUND for 404
+func ErrorCode(status int) string {
+	if code, ok := errorCodes[status]; ok {
+		return code
+	}
+	if text := http.StatusText(status); text != "" {
+		return strings.ToUpper(strcase.ToSnake(strings.ReplaceAll(text, "-", " ")))
+	}
+	return errorCodes[http.StatusInternalServerError]
+}
+
+// ProblemType returns the type URI of problems with an error code
+func ProblemType(errorCode string) string {
+	return "urn:problem-type:" + strings.ToLower(strings.ReplaceAll(errorCode, "_", "-"))
+}
+
+// acceptsProblem reports whether the client asked for problem details
+func acceptsProblem(c *gin.Context) bool {
+	return c.Request != nil && strings.Contains(c.GetHeader("Accept"), ProblemContentType)
+}
+
+// renderProblem sends an error response as problem details
+func renderProblem(c *gin.Context, status int, response ErrorResponse, errors interface{}) {
+	problem := Problem{
+		Type:      ProblemType(response.Code),
+		Title:     response.Message,
+		Status:    status,
+		Detail:    response.Description,
+		Instance:  c.Request.URL.Path,
+		Code:      response.Code,
+		RequestID: response.RequestID,
+		Data:      response.Data,
+		Errors:    errors,
+	}
+	c.Header("Content-Type", ProblemContentType)
+	renderJSON(c, status, problem)
+}
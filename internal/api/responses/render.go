@@ -0,0 +1,56 @@
+package responses
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"beautyessentials.com/internal/utils/keycase"
+	"github.com/gin-gonic/gin"
+)
+
+// jsonContentType is the Content-Type of JSON responses
+var jsonContentType = []string{"application/json; charset=utf-8"}
+
+// caseJSON renders a JSON response with its keys in the casing the client negotiated
+type caseJSON struct {
+	Case keycase.Case
+	Data interface{}
+}
+
+// Render encodes the data with its DTO json tags and rewrites the keys when the
+// client asked for another casing; documents too large to rewrite are sent as encoded
+func (r caseJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	encoded, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+
+	keyCase := r.Case
+	if len(encoded) > keycase.MaxRewriteSize {
+		keyCase = keycase.Snake
+	}
+	w.Header().Set(keycase.ResponseHeader, string(keyCase))
+	if keyCase == keycase.Snake {
+		_, err = w.Write(encoded)
+		return err
+	}
+	return keycase.Rewrite(w, encoded, keyCase)
+}
+
+// WriteContentType sets the JSON Content-Type unless the handler chose one
+func (r caseJSON) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if len(header["Content-Type"]) == 0 {
+		header["Content-Type"] = jsonContentType
+	}
+}
+
+// renderJSON sends data as JSON in the casing negotiated for the request
+func renderJSON(c *gin.Context, code int, data interface{}) {
+	keyCase := keycase.Default
+	if c.Request != nil {
+		keyCase = keycase.FromContext(c.Request.Context())
+	}
+	c.Render(code, caseJSON{Case: keyCase, Data: data})
+}
@@ -3,6 +3,7 @@ package responses
 import (
 	"net/http"
 
+	"beautyessentials.com/internal/utils/requestid"
 	"github.com/gin-gonic/gin"
 )
 
@@ -31,17 +32,19 @@ type PaginatedResponse struct {
 // Meta contains pagination metadata
 type Meta struct {
 	Page       int `json:"page"`
-	TotalPages int `json:"totalPages"`
-	PerPage    int `json:"perPage"`
-	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"total_pages"`
+	PerPage    int `json:"per_page"`
+	TotalItems int `json:"total_items"`
 }
 
 // ErrorResponse represents an error API response
 type ErrorResponse struct {
 	Success     bool        `json:"success"`
+	Code        string      `json:"code"`
 	Message     string      `json:"message"`
 	Description string      `json:"description,omitempty"`
 	Data        interface{} `json:"data,omitempty"`
+	RequestID   string      `json:"request_id,omitempty"`
 }
 
 // SendPaginatedResponse sends a paginated response
@@ -58,7 +61,7 @@ func SendPaginatedResponse(c *gin.Context, data interface{}, message string, pag
 		},
 	}
 
-	c.JSON(HTTPOk, response)
+	renderJSON(c, HTTPOk, response)
 }
 
 // SendResponse sends a success response with data
@@ -69,13 +72,20 @@ func SendResponse(c *gin.Context, data interface{}, message string, statusCode i
 		Message: message,
 	}
 
-	c.JSON(statusCode, response)
+	renderJSON(c, statusCode, response)
 }
 
-// SendError sends an error response
+// SendError sends an error response with the error code of its status
 func SendError(c *gin.Context, message string, description string, code int, data ...interface{}) {
+	SendCodedError(c, ErrorCode(code), message, description, code, data...)
+}
+
+// SendCodedError sends an error response with a machine-readable error code, as
+// problem details when the client accepts them
+func SendCodedError(c *gin.Context, errorCode string, message string, description string, status int, data ...interface{}) {
 	response := ErrorResponse{
 		Success:     false,
+		Code:        errorCode,
 		Message:     message,
 		Description: description,
 	}
@@ -84,7 +94,16 @@ func SendError(c *gin.Context, message string, description string, code int, dat
 		response.Data = data[0]
 	}
 
-	c.JSON(code, response)
+	// Let clients quote the request ID when reporting a failure
+	if c.Request != nil {
+		response.RequestID = requestid.FromContext(c.Request.Context())
+	}
+
+	if acceptsProblem(c) {
+		renderProblem(c, status, response, nil)
+		return
+	}
+	renderJSON(c, status, response)
 }
 
 // SendSuccess sends a simple success message
@@ -94,5 +113,5 @@ func SendSuccess(c *gin.Context, message string, statusCode int) {
 		Message: message,
 	}
 
-	c.JSON(statusCode, response)
+	renderJSON(c, statusCode, response)
 }
\ No newline at end of file
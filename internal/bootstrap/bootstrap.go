@@ -2,8 +2,8 @@ package bootstrap
 
 import (
 	"context"
-	"log"
 	"net/http"
+	"time"
 
 	"beautyessentials.com/internal/api/handlers"
 	"beautyessentials.com/internal/api/responses"
@@ -11,27 +11,49 @@ import (
 	repoImpl "beautyessentials.com/internal/repository/implementations"
 	"beautyessentials.com/internal/router"
 	serviceImpl "beautyessentials.com/internal/service/implementations"
+	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
 	"beautyessentials.com/internal/service/external" // Add this import
+	"beautyessentials.com/internal/utils/cache"
+	"beautyessentials.com/internal/utils/imageurl"
+	"beautyessentials.com/internal/utils/ratelimit"
+	"beautyessentials.com/internal/utils/tracing"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // Module exported for initializing application
 var Module = fx.Options(
-	ConfigModule,
-	RepositoryModule,
-	ServiceModule,
+	CoreModule,
 	HandlerModule,
 	RouterModule,
+	fx.WithLogger(newFxLogger),
 	fx.Invoke(bootstrap),
+	fx.Invoke(purgeIdempotencyKeys),
+)
+
+// CoreModule provides configuration, repositories and services without the HTTP layer,
+// so command line tools can reuse them
+var CoreModule = fx.Options(
+	ConfigModule,
+	RepositoryModule,
+	ServiceModule,
+	fx.Invoke(configureTracing),
+	fx.Invoke(config.MigrateDatabase),
+	fx.Invoke(configureImagePresets),
 )
 
 // ConfigModule provides configuration dependencies
 var ConfigModule = fx.Options( 
 	fx.Provide(config.LoadConfig),
+	fx.Provide(config.NewLogger),
 	fx.Provide(config.InitDatabase),
 	fx.Provide(responses.NewResponseHelper),
+	fx.Provide(newRateLimitStore),
+	fx.Provide(newCacheStore),
+	fx.Provide(newCache),
 )
 
 // RepositoryModule provides repository dependencies
@@ -40,6 +62,12 @@ var RepositoryModule = fx.Options(
 	fx.Provide(repoImpl.NewBrandRepository),
 	fx.Provide(repoImpl.NewCategoryRepository),
 	fx.Provide(repoImpl.NewMediaRepository), // Add media repository
+	fx.Provide(repoImpl.NewMediaFolderRepository),
+	fx.Provide(repoImpl.NewUserRepository),
+	fx.Provide(repoImpl.NewRefreshTokenRepository),
+	fx.Provide(repoImpl.NewRoleRepository),
+	fx.Provide(repoImpl.NewApiKeyRepository),
+	fx.Provide(repoImpl.NewIdempotencyKeyRepository),
 )
 
 // ServiceModule provides service dependencies
@@ -48,6 +76,11 @@ var ServiceModule = fx.Options(
 	fx.Provide(serviceImpl.NewBrandService),
 	fx.Provide(serviceImpl.NewCategoryService),
 	fx.Provide(serviceImpl.NewMediaService), // Add media service
+	fx.Provide(serviceImpl.NewMediaFolderService),
+	fx.Provide(serviceImpl.NewAuthService),
+	fx.Provide(serviceImpl.NewRoleService),
+	fx.Provide(serviceImpl.NewApiKeyService),
+	fx.Provide(serviceImpl.NewIdempotencyService),
 	fx.Provide(external.NewImageKitService), // Add ImageKit service for media uploads
 )
 
@@ -57,6 +90,10 @@ var HandlerModule = fx.Options(
 	fx.Provide(handlers.NewBrandHandler),
 	fx.Provide(handlers.NewCategoryHandler),
 	fx.Provide(handlers.NewMediaHandler), // Add media handler
+	fx.Provide(handlers.NewMediaFolderHandler),
+	fx.Provide(handlers.NewAuthHandler),
+	fx.Provide(handlers.NewRoleHandler),
+	fx.Provide(handlers.NewApiKeyHandler),
 )
 
 // RouterModule provides router dependencies
@@ -81,6 +118,99 @@ func newHTTPServer(router *gin.Engine, cfg *config.Config) *http.Server {
 	}
 }
 
+// newFxLogger routes fx's own lifecycle events through the application logger
+func newFxLogger(logger *zap.Logger) fxevent.Logger {
+	return &fxevent.ZapLogger{Logger: logger.Named("fx")}
+}
+
+// newRateLimitStore provides the store backing rate limits; swap it for a shared
+// implementation of ratelimit.Store when running several instances
+func newRateLimitStore() ratelimit.Store {
+	return ratelimit.NewMemoryStore()
+}
+
+// newCacheStore provides the store backing the service cache; swap it for a shared
+// implementation of cache.Store when running several instances
+func newCacheStore(cfg *config.Config) cache.Store {
+	return cache.NewMemoryStore(cfg.Cache().Size)
+}
+
+// newCache provides the read-through cache of catalog queries, nil when disabled
+func newCache(cfg *config.Config, store cache.Store) *cache.Cache {
+	cacheConfig := cfg.Cache()
+	if !cacheConfig.Enabled {
+		return nil
+	}
+	return cache.New(store, cacheConfig.TTL)
+}
+
+// configureTracing installs the configured span provider and flushes it on shutdown
+func configureTracing(lifecycle fx.Lifecycle, cfg *config.Config, logger *zap.Logger) error {
+	provider, err := config.NewTracerProvider(cfg, logger)
+	if err != nil || provider == nil {
+		return err
+	}
+	tracing.SetProvider(provider)
+
+	lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			tracing.SetProvider(nil)
+			return provider.Shutdown(ctx)
+		},
+	})
+	return nil
+}
+
+// configureImagePresets registers the configured transformation presets used for media variants
+func configureImagePresets(cfg *config.Config) error {
+	imageKitConfig := cfg.ImageKit()
+	presets, err := imageurl.ParsePresets(imageKitConfig.Presets)
+	if err != nil {
+		return err
+	}
+	imageurl.SetDefault(imageurl.NewBuilder(imageKitConfig.URLEndpoint, presets))
+	return nil
+}
+
+// purgeIdempotencyKeys periodically deletes expired idempotency keys while the server runs
+func purgeIdempotencyKeys(lifecycle fx.Lifecycle, cfg *config.Config, idempotencyService serviceInterfaces.IdempotencyService, logger *zap.Logger) {
+	interval := cfg.Idempotency().PurgeInterval
+	if interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						purged, err := idempotencyService.PurgeExpired(ctx)
+						if err != nil {
+							logger.Error("Failed to purge expired idempotency keys", zap.Error(err))
+						} else if purged > 0 {
+							logger.Info("Purged expired idempotency keys", zap.Int64("count", purged))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
+
 // bootstrap registers lifecycle hooks and initializes the application
 func bootstrap(
 	lifecycle fx.Lifecycle,
@@ -88,35 +218,35 @@ func bootstrap(
 	server *http.Server,
 	cfg *config.Config,
 	db *gorm.DB,
+	logger *zap.Logger,
 ) {
 	// Register server start and stop hooks
 	lifecycle.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			log.Println("Starting Application")
-			log.Println("------------------------")
-			log.Println("-- Beauty Essentials API --")
-			log.Println("------------------------")
+			logger.Info("Starting Beauty Essentials API")
 
 			// Start the server in a goroutine
 			go func() {
 				serverConfig := cfg.Server()
-				log.Printf("Server starting on port %s", serverConfig.Port)
+				logger.Info("Server starting", zap.String("port", serverConfig.Port))
 				if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-					log.Fatalf("Failed to start server: %v", err)
+					logger.Fatal("Failed to start server", zap.Error(err))
 				}
 			}()
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			log.Println("Shutting down server...")
-			
+			logger.Info("Shutting down server")
+			err := server.Shutdown(ctx)
+
 			// Close database connection
 			sqlDB, _ := db.DB()
 			if sqlDB != nil {
 				_ = sqlDB.Close()
 			}
-			
-			return server.Shutdown(ctx)
+
+			_ = logger.Sync()
+			return err
 		},
 	})
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"time"
 
@@ -13,6 +14,10 @@ type Config struct {
 	ServerPort         string        `mapstructure:"SERVER_PORT"`
 	ServerReadTimeout  time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
 	ServerWriteTimeout time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+
+	// Log config
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+	LogFormat string `mapstructure:"LOG_FORMAT"`
 	
 	// Database config
 	DBHost     string `mapstructure:"DB_HOST"`
@@ -23,9 +28,66 @@ type Config struct {
 	DBSSLMode  string `mapstructure:"DB_SSL_MODE"`
 	
 	// ImageKit config
-	ImageKitPublicKey   string `mapstructure:"IMAGEKIT_PUBLIC_KEY"`
-	ImageKitPrivateKey  string `mapstructure:"IMAGEKIT_PRIVATE_KEY"`
-	ImageKitURLEndpoint string `mapstructure:"IMAGEKIT_URL_ENDPOINT"`
+	ImageKitPublicKey   string        `mapstructure:"IMAGEKIT_PUBLIC_KEY"`
+	ImageKitPrivateKey  string        `mapstructure:"IMAGEKIT_PRIVATE_KEY"`
+	ImageKitURLEndpoint string        `mapstructure:"IMAGEKIT_URL_ENDPOINT"`
+	ImageKitUploadTTL   time.Duration `mapstructure:"IMAGEKIT_UPLOAD_TTL"`
+	ImageKitPresets     string        `mapstructure:"IMAGEKIT_PRESETS"`
+	ImageKitUploadURL   string        `mapstructure:"IMAGEKIT_UPLOAD_BASE_URL"`
+	ImageKitAPIURL      string        `mapstructure:"IMAGEKIT_API_BASE_URL"`
+
+	// ImageKit client resilience config
+	ImageKitTimeout          time.Duration `mapstructure:"IMAGEKIT_TIMEOUT"`
+	ImageKitUploadTimeout    time.Duration `mapstructure:"IMAGEKIT_UPLOAD_TIMEOUT"`
+	ImageKitMaxRetries       int           `mapstructure:"IMAGEKIT_MAX_RETRIES"`
+	ImageKitRetryBaseDelay   time.Duration `mapstructure:"IMAGEKIT_RETRY_BASE_DELAY"`
+	ImageKitRetryMaxDelay    time.Duration `mapstructure:"IMAGEKIT_RETRY_MAX_DELAY"`
+	ImageKitBreakerThreshold int           `mapstructure:"IMAGEKIT_BREAKER_THRESHOLD"`
+	ImageKitBreakerCooldown  time.Duration `mapstructure:"IMAGEKIT_BREAKER_COOLDOWN"`
+
+	// Auth config
+	JWTSecret     string        `mapstructure:"JWT_SECRET"`
+	JWTIssuer     string        `mapstructure:"JWT_ISSUER"`
+	JWTAccessTTL  time.Duration `mapstructure:"JWT_ACCESS_TTL"`
+	JWTRefreshTTL time.Duration `mapstructure:"JWT_REFRESH_TTL"`
+	BcryptCost    int           `mapstructure:"BCRYPT_COST"`
+
+	// API key config
+	ApiKeyDefaultRateLimit int `mapstructure:"API_KEY_DEFAULT_RATE_LIMIT"`
+
+	// Rate limit config, in requests per minute
+	RateLimitEnabled bool `mapstructure:"RATE_LIMIT_ENABLED"`
+	RateLimitPublic  int  `mapstructure:"RATE_LIMIT_PUBLIC"`
+	RateLimitAuth    int  `mapstructure:"RATE_LIMIT_AUTH"`
+	RateLimitAPI     int  `mapstructure:"RATE_LIMIT_API"`
+
+	// Metrics config
+	MetricsEnabled bool   `mapstructure:"METRICS_ENABLED"`
+	MetricsToken   string `mapstructure:"METRICS_TOKEN"`
+
+	// Service cache config
+	CacheEnabled bool          `mapstructure:"CACHE_ENABLED"`
+	CacheTTL     time.Duration `mapstructure:"CACHE_TTL"`
+	CacheSize    int           `mapstructure:"CACHE_SIZE"`
+
+	// HTTP cache config, as Cache-Control values per route
+	HTTPCacheActiveCategories string `mapstructure:"HTTP_CACHE_ACTIVE_CATEGORIES"`
+	HTTPCacheGroupedBrands    string `mapstructure:"HTTP_CACHE_GROUPED_BRANDS"`
+
+	// Idempotency config
+	IdempotencyTTL           time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+	IdempotencyPurgeInterval time.Duration `mapstructure:"IDEMPOTENCY_PURGE_INTERVAL"`
+
+	// Tracing config
+	TracingExporter    string  `mapstructure:"TRACING_EXPORTER"`
+	TracingFile        string  `mapstructure:"TRACING_FILE"`
+	TracingSampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	OtelServiceName    string  `mapstructure:"OTEL_SERVICE_NAME"`
+	OtelEndpoint       string  `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OtelHeaders        string  `mapstructure:"OTEL_EXPORTER_OTLP_HEADERS"`
+
+	// API versioning config
+	APIDeprecations string `mapstructure:"API_DEPRECATIONS"`
 }
 
 // ServerConfig returns the server configuration
@@ -37,6 +99,14 @@ func (c *Config) Server() ServerConfig {
 	}
 }
 
+// Log returns the logging configuration
+func (c *Config) Log() LogConfig {
+	return LogConfig{
+		Level:  c.LogLevel,
+		Format: c.LogFormat,
+	}
+}
+
 // Database returns the database configuration
 func (c *Config) Database() DatabaseConfig {
 	return DatabaseConfig{
@@ -52,9 +122,98 @@ func (c *Config) Database() DatabaseConfig {
 // ImageKit returns the ImageKit configuration
 func (c *Config) ImageKit() ImageKitConfig {
 	return ImageKitConfig{
-		PublicKey:   c.ImageKitPublicKey,
-		PrivateKey:  c.ImageKitPrivateKey,
-		URLEndpoint: c.ImageKitURLEndpoint,
+		PublicKey:     c.ImageKitPublicKey,
+		PrivateKey:    c.ImageKitPrivateKey,
+		URLEndpoint:   c.ImageKitURLEndpoint,
+		UploadTTL:     c.ImageKitUploadTTL,
+		Presets:       c.ImageKitPresets,
+		UploadBaseURL: c.ImageKitUploadURL,
+		APIBaseURL:    c.ImageKitAPIURL,
+		Client: ImageKitClientConfig{
+			Timeout:          c.ImageKitTimeout,
+			UploadTimeout:    c.ImageKitUploadTimeout,
+			MaxRetries:       c.ImageKitMaxRetries,
+			RetryBaseDelay:   c.ImageKitRetryBaseDelay,
+			RetryMaxDelay:    c.ImageKitRetryMaxDelay,
+			BreakerThreshold: c.ImageKitBreakerThreshold,
+			BreakerCooldown:  c.ImageKitBreakerCooldown,
+		},
+	}
+}
+
+// Auth returns the authentication configuration
+func (c *Config) Auth() AuthConfig {
+	return AuthConfig{
+		JWTSecret:              c.JWTSecret,
+		Issuer:                 c.JWTIssuer,
+		AccessTTL:              c.JWTAccessTTL,
+		RefreshTTL:             c.JWTRefreshTTL,
+		BcryptCost:             c.BcryptCost,
+		ApiKeyDefaultRateLimit: c.ApiKeyDefaultRateLimit,
+	}
+}
+
+// RateLimit returns the rate limiting configuration
+func (c *Config) RateLimit() RateLimitConfig {
+	return RateLimitConfig{
+		Enabled: c.RateLimitEnabled,
+		Public:  c.RateLimitPublic,
+		Auth:    c.RateLimitAuth,
+		API:     c.RateLimitAPI,
+	}
+}
+
+// Metrics returns the metrics endpoint configuration
+func (c *Config) Metrics() MetricsConfig {
+	return MetricsConfig{
+		Enabled: c.MetricsEnabled,
+		Token:   c.MetricsToken,
+	}
+}
+
+// Cache returns the service cache configuration
+func (c *Config) Cache() CacheConfig {
+	return CacheConfig{
+		Enabled: c.CacheEnabled,
+		TTL:     c.CacheTTL,
+		Size:    c.CacheSize,
+	}
+}
+
+// HTTPCache returns the Cache-Control policies of cacheable routes
+func (c *Config) HTTPCache() HTTPCacheConfig {
+	return HTTPCacheConfig{
+		ActiveCategories: c.HTTPCacheActiveCategories,
+		GroupedBrands:    c.HTTPCacheGroupedBrands,
+	}
+}
+
+// Idempotency returns the configuration of Idempotency-Key handling
+func (c *Config) Idempotency() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:           c.IdempotencyTTL,
+		PurgeInterval: c.IdempotencyPurgeInterval,
+	}
+}
+
+// Tracing returns the tracing configuration
+func (c *Config) Tracing() TracingConfig {
+	return TracingConfig{
+		Exporter:     c.TracingExporter,
+		File:         c.TracingFile,
+		SampleRatio:  c.TracingSampleRatio,
+		ServiceName:  c.OtelServiceName,
+		OTLPEndpoint: c.OtelEndpoint,
+		OTLPHeaders:  c.OtelHeaders,
+	}
+}
+
+// Versioning returns the API versioning configuration
+func (c *Config) Versioning() VersioningConfig {
+	// LoadConfig rejects invalid schedules, so parsing can't fail here
+	deprecations, _ := parseDeprecations(c.APIDeprecations)
+	return VersioningConfig{
+		Deprecations: deprecations,
 	}
 }
 
@@ -65,6 +224,14 @@ type ServerConfig struct {
 	WriteTimeout time.Duration
 }
 
+// LogConfig holds logging configuration
+type LogConfig struct {
+	// Level is a zap level such as debug, info or warn; debug includes SQL queries
+	Level string
+	// Format is json for production or console for local development
+	Format string
+}
+
 // DatabaseConfig holds database-related configuration
 type DatabaseConfig struct {
 	Host     string
@@ -77,9 +244,112 @@ type DatabaseConfig struct {
 
 // ImageKitConfig holds ImageKit-related configuration
 type ImageKitConfig struct {
-	PublicKey   string
-	PrivateKey  string
-	URLEndpoint string
+	PublicKey     string
+	PrivateKey    string
+	URLEndpoint   string
+	UploadTTL     time.Duration
+	Presets       string
+	UploadBaseURL string
+	APIBaseURL    string
+	Client        ImageKitClientConfig
+}
+
+// ImageKitClientConfig holds timeouts, retry and circuit breaker settings for ImageKit calls
+type ImageKitClientConfig struct {
+	Timeout          time.Duration
+	UploadTimeout    time.Duration
+	MaxRetries       int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// AuthConfig holds token signing and password hashing configuration
+type AuthConfig struct {
+	JWTSecret  string
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	BcryptCost int
+	// ApiKeyDefaultRateLimit is the per-minute limit of API keys created without one
+	ApiKeyDefaultRateLimit int
+}
+
+// RateLimitConfig holds per route group limits in requests per minute
+type RateLimitConfig struct {
+	Enabled bool
+	// Public applies per client IP to the public catalog routes
+	Public int
+	// Auth applies per client IP to login, registration and token refresh
+	Auth int
+	// API applies per user or API key to authenticated routes
+	API int
+}
+
+// MetricsConfig holds configuration of the Prometheus endpoint
+type MetricsConfig struct {
+	Enabled bool
+	// Token must be presented as a bearer token to scrape /metrics
+	Token string
+}
+
+// CacheConfig holds configuration of the read-through cache of catalog queries
+type CacheConfig struct {
+	Enabled bool
+	// TTL bounds how long an entry lives even without writes invalidating it
+	TTL time.Duration
+	// Size is the number of entries the in-memory store holds
+	Size int
+}
+
+// HTTPCacheConfig holds Cache-Control values of cacheable routes; empty omits the header
+type HTTPCacheConfig struct {
+	// ActiveCategories applies to GET /api/v1/categories/active
+	ActiveCategories string
+	// GroupedBrands applies to GET /api/v1/brands/grouped
+	GroupedBrands string
+}
+
+// IdempotencyConfig holds configuration of Idempotency-Key handling
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed for retries of its key
+	TTL time.Duration
+	// PurgeInterval is how often expired keys are deleted; zero disables purging
+	PurgeInterval time.Duration
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	// Exporter is one of none, stdout, file or otlp
+	Exporter string
+	// File is the path spans are appended to by the file exporter
+	File string
+	// SampleRatio is the fraction of new traces recorded, between 0 and 1
+	SampleRatio float64
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName string
+	// OTLPEndpoint is the base URL of an OTLP/HTTP collector
+	OTLPEndpoint string
+	// OTLPHeaders are extra export headers as comma separated key=value pairs
+	OTLPHeaders string
+}
+
+// VersioningConfig holds the API versioning configuration
+type VersioningConfig struct {
+	// Deprecations schedules v1 routes for removal, keyed by method and path
+	// relative to the version prefix, e.g. "GET /brands/grouped"
+	Deprecations map[string]RouteDeprecation
+}
+
+// RouteDeprecation schedules the retirement of a route
+type RouteDeprecation struct {
+	// Since is when the route was deprecated
+	Since time.Time
+	// Sunset is when the route stops being served; zero while undecided
+	Sunset time.Time
+	// Link points at migration notes, if any
+	Link string
 }
 
 // LoadConfig loads configuration from environment variables and .env files
@@ -94,6 +364,8 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("SERVER_PORT", "8080")
 	viper.SetDefault("SERVER_READ_TIMEOUT", "10s")
 	viper.SetDefault("SERVER_WRITE_TIMEOUT", "10s")
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_FORMAT", "json")
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "5432")
 	viper.SetDefault("DB_USER", "postgres")
@@ -103,6 +375,43 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("IMAGEKIT_PUBLIC_KEY", "")
 	viper.SetDefault("IMAGEKIT_PRIVATE_KEY", "")
 	viper.SetDefault("IMAGEKIT_URL_ENDPOINT", "")
+	viper.SetDefault("IMAGEKIT_UPLOAD_TTL", "30m")
+	viper.SetDefault("IMAGEKIT_PRESETS", "card:w-400,h-400,c-at_max,q-80;zoom:w-1600,q-90;og:w-1200,h-630,c-maintain_ratio,q-85")
+	viper.SetDefault("IMAGEKIT_UPLOAD_BASE_URL", "https://upload.imagekit.io")
+	viper.SetDefault("IMAGEKIT_API_BASE_URL", "https://api.imagekit.io")
+	viper.SetDefault("IMAGEKIT_TIMEOUT", "10s")
+	viper.SetDefault("IMAGEKIT_UPLOAD_TIMEOUT", "60s")
+	viper.SetDefault("IMAGEKIT_MAX_RETRIES", 3)
+	viper.SetDefault("IMAGEKIT_RETRY_BASE_DELAY", "200ms")
+	viper.SetDefault("IMAGEKIT_RETRY_MAX_DELAY", "5s")
+	viper.SetDefault("IMAGEKIT_BREAKER_THRESHOLD", 5)
+	viper.SetDefault("IMAGEKIT_BREAKER_COOLDOWN", "30s")
+	viper.SetDefault("JWT_SECRET", "")
+	viper.SetDefault("JWT_ISSUER", "beautyessentials")
+	viper.SetDefault("JWT_ACCESS_TTL", "15m")
+	viper.SetDefault("JWT_REFRESH_TTL", "720h")
+	viper.SetDefault("BCRYPT_COST", 12)
+	viper.SetDefault("API_KEY_DEFAULT_RATE_LIMIT", 600)
+	viper.SetDefault("RATE_LIMIT_ENABLED", true)
+	viper.SetDefault("RATE_LIMIT_PUBLIC", 120)
+	viper.SetDefault("RATE_LIMIT_AUTH", 10)
+	viper.SetDefault("RATE_LIMIT_API", 600)
+	viper.SetDefault("METRICS_ENABLED", false)
+	viper.SetDefault("METRICS_TOKEN", "")
+	viper.SetDefault("CACHE_ENABLED", true)
+	viper.SetDefault("CACHE_TTL", "10m")
+	viper.SetDefault("CACHE_SIZE", 1000)
+	viper.SetDefault("HTTP_CACHE_ACTIVE_CATEGORIES", "public, max-age=60, stale-while-revalidate=300")
+	viper.SetDefault("HTTP_CACHE_GROUPED_BRANDS", "public, max-age=60, stale-while-revalidate=300")
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("IDEMPOTENCY_PURGE_INTERVAL", "1h")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_FILE", "storage/traces.jsonl")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("OTEL_SERVICE_NAME", "beautyessentials-api")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_HEADERS", "")
+	viper.SetDefault("API_DEPRECATIONS", "")
 
 	// Enable environment variables
 	viper.AutomaticEnv()
@@ -119,5 +428,13 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	// The metrics endpoint is never served without a token
+	if config.MetricsEnabled && config.MetricsToken == "" {
+		return nil, errors.New("METRICS_TOKEN must be set when METRICS_ENABLED is true")
+	}
+	if _, err := parseDeprecations(config.APIDeprecations); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
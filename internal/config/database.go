@@ -2,17 +2,16 @@ package config
 
 import (
 	"fmt"
-	"log"
-	"os"
 	"time"
 
+	"beautyessentials.com/internal/utils/metrics"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // InitDatabase establishes a connection to the database using the application config
-func InitDatabase(cfg *Config) (*gorm.DB, error) {
+func InitDatabase(cfg *Config, logger *zap.Logger) (*gorm.DB, error) {
 	dbConfig := cfg.Database()
 	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
@@ -20,16 +19,8 @@ func InitDatabase(cfg *Config) (*gorm.DB, error) {
 		dbConfig.Password, dbConfig.DBName, dbConfig.SSLMode,
 	)
 
-	// Configure GORM logger
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags),
-		logger.Config{
-			SlowThreshold:             time.Second,
-			LogLevel:                  logger.Info,
-			IgnoreRecordNotFoundError: true,
-			Colorful:                  true,
-		},
-	)
+	// Route GORM logs through zap so queries carry the request ID
+	newLogger := newGormLogger(logger, time.Second)
 
 	// Connect to database
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
@@ -39,6 +30,11 @@ func InitDatabase(cfg *Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Record a span for every query
+	if err := db.Use(gormTracing{}); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+
 	// Get generic database object sql.DB to use its functions
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -50,5 +46,8 @@ func InitDatabase(cfg *Config) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
+	// Expose pool statistics on the metrics endpoint
+	metrics.NewDBStatsCollector(sqlDB)
+
 	return db, nil
 }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"beautyessentials.com/internal/utils/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger writes GORM logs through zap, tagged with the request ID of the query's context.
+// Queries are logged at debug level, slow queries at warn and failed queries at error.
+type gormLogger struct {
+	logger        *zap.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// newGormLogger creates a GORM logger backed by zap
+func newGormLogger(logger *zap.Logger, slowThreshold time.Duration) *gormLogger {
+	return &gormLogger{
+		logger:        logger.Named("gorm").WithOptions(zap.WithCaller(false)),
+		level:         gormlogger.Info,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode returns a copy of the logger with the given level
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info logs an informational message
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logging.For(ctx, l.logger).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn logs a warning
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logging.For(ctx, l.logger).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error logs an error
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logging.For(ctx, l.logger).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace logs a completed query
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	logger := logging.For(ctx, l.logger)
+	fields := func() []zap.Field {
+		sql, rows := fc()
+		return []zap.Field{zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed)}
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		logger.Error("query failed", append(fields(), zap.Error(err))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		logger.Warn("slow query", append(fields(), zap.Duration("threshold", l.slowThreshold))...)
+	case l.level >= gormlogger.Info && logger.Core().Enabled(zap.DebugLevel):
+		logger.Debug("query", fields()...)
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"context"
+	"errors"
+
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+)
+
+const (
+	gormSpanKey      = "tracing:span"
+	gormParentCtxKey = "tracing:parent_ctx"
+)
+
+// gormTracing is a GORM plugin that records a client span for every query
+type gormTracing struct{}
+
+// Name implements gorm.Plugin
+func (gormTracing) Name() string {
+	return "tracing"
+}
+
+// Initialize registers callbacks around each of GORM's processors
+func (p gormTracing) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+		name      string
+	}{
+		{"insert", callback.Create().Before("gorm:create").Register, callback.Create().After("gorm:create").Register, "create"},
+		{"select", callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register, "query"},
+		{"update", callback.Update().Before("gorm:update").Register, callback.Update().After("gorm:update").Register, "update"},
+		{"delete", callback.Delete().Before("gorm:delete").Register, callback.Delete().After("gorm:delete").Register, "delete"},
+		{"row", callback.Row().Before("gorm:row").Register, callback.Row().After("gorm:row").Register, "row"},
+		{"raw", callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register, "raw"},
+	}
+	for _, hook := range hooks {
+		if err := hook.before("tracing:before_"+hook.name, p.before(hook.operation)); err != nil {
+			return err
+		}
+		if err := hook.after("tracing:after_"+hook.name, p.after(hook.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before starts a span and makes it the statement's context
+func (gormTracing) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		spanCtx, span := tracing.Start(ctx, "db."+operation,
+			tracing.WithKind(tracing.KindClient),
+			tracing.WithAttributes(
+				tracing.String("db.system", "postgresql"),
+				tracing.String("db.operation", operation),
+			),
+		)
+		if !span.IsRecording() {
+			return
+		}
+		db.InstanceSet(gormParentCtxKey, ctx)
+		db.InstanceSet(gormSpanKey, span)
+		db.Statement.Context = spanCtx
+	}
+}
+
+// after ends the span with the executed statement; bound values are not recorded
+func (gormTracing) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormSpanKey)
+		if !ok {
+			return
+		}
+		span := value.(tracing.Span)
+
+		if table := db.Statement.Table; table != "" {
+			span.SetName("db." + operation + " " + table)
+			span.SetAttributes(tracing.String("db.sql.table", table))
+		}
+		span.SetAttributes(
+			tracing.String("db.statement", db.Statement.SQL.String()),
+			tracing.Int64("db.rows_affected", db.RowsAffected),
+		)
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(db.Error)
+		}
+		span.End()
+
+		if parent, ok := db.InstanceGet(gormParentCtxKey); ok {
+			db.Statement.Context = parent.(context.Context)
+		}
+	}
+}
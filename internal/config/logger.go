@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLogger creates the structured logger shared by every layer
+func NewLogger(cfg *Config) (*zap.Logger, error) {
+	logConfig := cfg.Log()
+
+	level, err := zapcore.ParseLevel(logConfig.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+
+	var zapConfig zap.Config
+	switch logConfig.Format {
+	case "json":
+		zapConfig = zap.NewProductionConfig()
+	case "console":
+		zapConfig = zap.NewDevelopmentConfig()
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q: expected json or console", logConfig.Format)
+	}
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+	zapConfig.EncoderConfig.TimeKey = "time"
+	zapConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	logger, err := zapConfig.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+	return logger, nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"beautyessentials.com/internal/constant"
+	"beautyessentials.com/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MigrateDatabase creates the tables and columns owned by this service.
+// Tables shared with the legacy Laravel schema (brands, categories, medias, mediables)
+// are never auto-migrated; only the columns this service adds to them are created.
+func MigrateDatabase(db *gorm.DB) error {
+	// Create tables introduced by this service
+	if err := db.AutoMigrate(
+		&models.MediaFolder{},
+		&models.MediaTag{},
+		&models.MediaTagPivot{},
+		&models.Role{},
+		&models.RolePermission{},
+		&models.UserRole{},
+		&models.User{},
+		&models.RefreshToken{},
+		&models.ApiKey{},
+		&models.ApiKeyScope{},
+		&models.IdempotencyKey{},
+	); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if err := seedSystemRoles(db); err != nil {
+		return err
+	}
+
+	// Add columns to legacy tables
+	if err := addMissingColumns(db, &models.Media{}, "FolderID", "ContentHash", "PerceptualHash"); err != nil {
+		return err
+	}
+	if err := uniqueContentHashes(db); err != nil {
+		return err
+	}
+	if err := addMissingIndexes(db, &models.Media{}, "FolderID", "ContentHash"); err != nil {
+		return err
+	}
+	if err := addMissingColumns(db, &models.Brand{}, "Version"); err != nil {
+		return err
+	}
+	if err := addMissingColumns(db, &models.Category{}, "Version", "Description"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// seedSystemRoles ensures the built-in admin role exists and holds every known permission
+func seedSystemRoles(db *gorm.DB) error {
+	var admin models.Role
+	err := db.Where("name = ?", constant.RoleAdmin).First(&admin).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		admin = models.Role{
+			Name:        constant.RoleAdmin,
+			Description: "Full access to every endpoint",
+			IsSystem:    true,
+		}
+		err = db.Create(&admin).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to seed admin role: %w", err)
+	}
+
+	// Grant permissions added since the role was created
+	grants := make([]models.RolePermission, 0, len(constant.Permissions))
+	for permission := range constant.Permissions {
+		grants = append(grants, models.RolePermission{RoleID: admin.ID, Permission: permission})
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&grants).Error; err != nil {
+		return fmt.Errorf("failed to seed admin permissions: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueContentHashes prepares medias for the unique content hash index: the
+// plain index it replaces is dropped and, of media sharing a hash, all but the
+// oldest lose theirs, so they are reported as near duplicates instead
+func uniqueContentHashes(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasIndex(&models.Media{}, "ContentHash") {
+		return nil
+	}
+	if migrator.HasIndex(&models.Media{}, "idx_medias_content_hash") {
+		if err := migrator.DropIndex(&models.Media{}, "idx_medias_content_hash"); err != nil {
+			return fmt.Errorf("failed to drop index idx_medias_content_hash: %w", err)
+		}
+	}
+
+	err := db.Exec(`UPDATE medias SET content_hash = NULL
+		WHERE content_hash IN (SELECT content_hash FROM medias WHERE content_hash <> '' GROUP BY content_hash HAVING COUNT(*) > 1)
+		AND id NOT IN (SELECT MIN(id) FROM medias WHERE content_hash <> '' GROUP BY content_hash)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to clear duplicate content hashes: %w", err)
+	}
+	return nil
+}
+
+// addMissingColumns adds the given fields of a model to its table when absent
+func addMissingColumns(db *gorm.DB, model interface{}, fields ...string) error {
+	migrator := db.Migrator()
+	for _, field := range fields {
+		if migrator.HasColumn(model, field) {
+			continue
+		}
+		if err := migrator.AddColumn(model, field); err != nil {
+			return fmt.Errorf("failed to add column %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// addMissingIndexes creates the indexes declared on the given fields of a model when absent
+func addMissingIndexes(db *gorm.DB, model interface{}, fields ...string) error {
+	migrator := db.Migrator()
+	for _, field := range fields {
+		if migrator.HasIndex(model, field) {
+			continue
+		}
+		if err := migrator.CreateIndex(model, field); err != nil {
+			return fmt.Errorf("failed to create index on %s: %w", field, err)
+		}
+	}
+	return nil
+}
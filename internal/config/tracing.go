@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"beautyessentials.com/internal/utils/tracing"
+	"go.uber.org/zap"
+)
+
+// NewTracerProvider creates the span provider selected by TRACING_EXPORTER,
+// or nil when tracing is disabled
+func NewTracerProvider(cfg *Config, logger *zap.Logger) (*tracing.Provider, error) {
+	tracingConfig := cfg.Tracing()
+
+	var exporter tracing.Exporter
+	switch tracingConfig.Exporter {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		writerExporter, err := tracing.NewWriterExporter(os.Stdout)
+		if err != nil {
+			return nil, err
+		}
+		exporter = writerExporter
+	case "file":
+		if err := os.MkdirAll(filepath.Dir(tracingConfig.File), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create trace directory: %w", err)
+		}
+		fileExporter, err := tracing.NewFileExporter(tracingConfig.File)
+		if err != nil {
+			return nil, err
+		}
+		exporter = fileExporter
+	case "otlp":
+		endpoint := strings.TrimSuffix(tracingConfig.OTLPEndpoint, "/") + "/v1/traces"
+		exporter = tracing.NewOTLPExporter(endpoint, parseHeaders(tracingConfig.OTLPHeaders))
+	default:
+		return nil, fmt.Errorf("invalid TRACING_EXPORTER %q: expected none, stdout, file or otlp", tracingConfig.Exporter)
+	}
+
+	logger = logger.Named("tracing")
+	return tracing.NewProvider(exporter, tracing.ProviderOptions{
+		ServiceName: tracingConfig.ServiceName,
+		SampleRatio: tracingConfig.SampleRatio,
+		OnError: func(err error) {
+			logger.Warn("failed to export spans", zap.Error(err))
+		},
+	}), nil
+}
+
+// parseHeaders parses comma separated key=value pairs as used by OTEL_EXPORTER_OTLP_HEADERS
+func parseHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return headers
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// deprecationDateLayout is the layout of the dates in API_DEPRECATIONS
+const deprecationDateLayout = "2006-01-02"
+
+// parseDeprecations parses API_DEPRECATIONS, entries of the form
+// "METHOD /path|since|sunset|link" separated by semicolons, e.g.
+// "GET /brands/grouped|2026-01-01|2026-07-01|https://example.com/migrate".
+// Paths are relative to the version prefix; sunset and link are optional.
+func parseDeprecations(value string) (map[string]RouteDeprecation, error) {
+	deprecations := make(map[string]RouteDeprecation)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Split(entry, "|")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		method, path, ok := strings.Cut(fields[0], " ")
+		path = strings.TrimSpace(path)
+		if !ok || method == "" || !strings.HasPrefix(path, "/") || len(fields) < 2 || len(fields) > 4 {
+			return nil, fmt.Errorf("invalid API_DEPRECATIONS entry %q: expected \"METHOD /path|since|sunset|link\"", entry)
+		}
+
+		var deprecation RouteDeprecation
+		var err error
+		if deprecation.Since, err = time.Parse(deprecationDateLayout, fields[1]); err != nil {
+			return nil, fmt.Errorf("invalid deprecation date in API_DEPRECATIONS entry %q: %w", entry, err)
+		}
+		if len(fields) > 2 && fields[2] != "" {
+			if deprecation.Sunset, err = time.Parse(deprecationDateLayout, fields[2]); err != nil {
+				return nil, fmt.Errorf("invalid sunset date in API_DEPRECATIONS entry %q: %w", entry, err)
+			}
+		}
+		if len(fields) > 3 {
+			deprecation.Link = fields[3]
+		}
+		deprecations[strings.ToUpper(method)+" "+path] = deprecation
+	}
+	return deprecations, nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDeprecations(t *testing.T) {
+	deprecations, err := parseDeprecations(" get /brands/grouped | 2026-01-01 | 2026-07-01 | https://example.com/migrate ;; GET /categories|2026-02-01 ")
+	if err != nil {
+		t.Fatalf("parseDeprecations() error = %v", err)
+	}
+	want := map[string]RouteDeprecation{
+		"GET /brands/grouped": {
+			Since:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Sunset: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+			Link:   "https://example.com/migrate",
+		},
+		"GET /categories": {Since: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if len(deprecations) != len(want) {
+		t.Fatalf("parseDeprecations() = %v, want %v", deprecations, want)
+	}
+	for key, deprecation := range want {
+		if got := deprecations[key]; !got.Since.Equal(deprecation.Since) || !got.Sunset.Equal(deprecation.Sunset) || got.Link != deprecation.Link {
+			t.Errorf("deprecation of %s = %+v, want %+v", key, got, deprecation)
+		}
+	}
+
+	for _, invalid := range []string{
+		"GET /brands/grouped",
+		"/brands/grouped|2026-01-01",
+		"GET brands|2026-01-01",
+		"GET /brands/grouped|January 2026",
+		"GET /brands/grouped|2026-01-01|soon",
+		"GET /brands/grouped|2026-01-01|2026-07-01|https://example.com|extra",
+	} {
+		if _, err := parseDeprecations(invalid); err == nil {
+			t.Errorf("parseDeprecations(%q) accepted an invalid entry", invalid)
+		}
+	}
+}
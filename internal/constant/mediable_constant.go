@@ -0,0 +1,11 @@
+package constant
+
+// Polymorphic types stored in mediables.mediable_type (kept compatible with the Laravel schema)
+const (
+	MediableTypeCategory = "App\\Model\\Category"
+)
+
+// MediableEntities maps mediable types to the entity names exposed by the API
+var MediableEntities = map[string]string{
+	MediableTypeCategory: "category",
+}
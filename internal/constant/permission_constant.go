@@ -0,0 +1,32 @@
+package constant
+
+// Permissions checked by the route-level permission middleware
+const (
+	PermissionBrandsWrite      = "brands.write"
+	PermissionBrandsDelete     = "brands.delete"
+	PermissionCategoriesWrite  = "categories.write"
+	PermissionCategoriesDelete = "categories.delete"
+	PermissionMediaRead        = "media.read"
+	PermissionMediaWrite       = "media.write"
+	PermissionMediaDelete      = "media.delete"
+	PermissionMediaSync        = "media.sync"
+	PermissionRolesManage      = "roles.manage"
+	PermissionApiKeysManage    = "api_keys.manage"
+)
+
+// RoleAdmin is the built-in role that always holds every permission
+const RoleAdmin = "admin"
+
+// Permissions lists every known permission with a description
+var Permissions = map[string]string{
+	PermissionBrandsWrite:      "Create and update brands",
+	PermissionBrandsDelete:     "Delete brands",
+	PermissionCategoriesWrite:  "Create and update categories",
+	PermissionCategoriesDelete: "Delete categories",
+	PermissionMediaRead:        "Browse the media library",
+	PermissionMediaWrite:       "Upload, move and tag media and manage folders",
+	PermissionMediaDelete:      "Delete media and folders",
+	PermissionMediaSync:        "Import untracked files from storage",
+	PermissionRolesManage:      "Manage roles and assign them to users",
+	PermissionApiKeysManage:    "Create and revoke API keys",
+}
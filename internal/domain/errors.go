@@ -0,0 +1,111 @@
+// Package domain holds the errors repositories and services report about the
+// catalog's data, independently of how they are answered over HTTP.
+package domain
+
+import "errors"
+
+// Kind classifies domain errors; it is also the code of errors without a more
+// specific one
+type Kind string
+
+const (
+	// KindNotFound reports a missing record
+	KindNotFound Kind = "NOT_FOUND"
+	// KindConflict reports a write clashing with the current state, e.g. a taken slug
+	KindConflict Kind = "CONFLICT"
+	// KindValidation reports input that can never succeed, e.g. a malformed ID
+	KindValidation Kind = "VALIDATION_ERROR"
+	// KindPreconditionFailed reports a request whose preconditions no longer hold
+	KindPreconditionFailed Kind = "PRECONDITION_FAILED"
+)
+
+// Error is a domain error with a stable, machine-readable code
+type Error struct {
+	Kind Kind
+	// Code identifies the error precisely, e.g. CATEGORY_NOT_FOUND
+	Code string
+	// Message summarizes the error for people
+	Message string
+	// Detail explains this occurrence, when there is more to say
+	Detail string
+	// Data is sent along with the error, e.g. the records clashing with the request
+	Data interface{}
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return e.Message + ": " + e.Detail
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error, so errors.Is still finds driver errors
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NotFound creates a not found error
+func NotFound(code string, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// Conflict creates a conflict error
+func Conflict(code string, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Validation creates a validation error
+func Validation(code string, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+// PreconditionFailed creates a precondition failed error
+func PreconditionFailed(code string, message string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Code: code, Message: message}
+}
+
+// WithDetail returns a copy of e explaining this occurrence
+func (e *Error) WithDetail(detail string) *Error {
+	copied := *e
+	copied.Detail = detail
+	return &copied
+}
+
+// WithData returns a copy of e sending data along
+func (e *Error) WithData(data interface{}) *Error {
+	copied := *e
+	copied.Data = data
+	return &copied
+}
+
+// Wrap returns a copy of e caused by err
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+// Is matches domain errors by code, so copies made by WithDetail, WithData and
+// Wrap still match the error they were made from
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && t.Kind == e.Kind
+}
+
+// As returns the domain error in err's chain
+func As(err error) (*Error, bool) {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound reports whether err is a not found error
+func IsNotFound(err error) bool {
+	domainErr, ok := As(err)
+	return ok && domainErr.Kind == KindNotFound
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"sort"
+	"time"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/utils/transformer"
+)
+
+// ApiKeyDTO represents the data transfer object for ApiKey
+type ApiKeyDTO struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	RateLimit   int        `json:"rate_limit"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedByID *string    `json:"created_by_id"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// ApiKeyCreatedDTO carries a newly created API key; Key is only ever returned here
+type ApiKeyCreatedDTO struct {
+	ApiKeyDTO
+	Key string `json:"key"`
+}
+
+// FromApiKeyModel converts an ApiKey model to an ApiKeyDTO
+func FromApiKeyModel(key models.ApiKey) ApiKeyDTO {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, scope.Scope)
+	}
+	sort.Strings(scopes)
+
+	return ApiKeyDTO{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Scopes:      scopes,
+		RateLimit:   key.RateLimit,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		RevokedAt:   key.RevokedAt,
+		CreatedByID: key.CreatedByID,
+		CreatedAt:   &key.CreatedAt,
+		UpdatedAt:   &key.UpdatedAt,
+	}
+}
+
+// TransformApiKeyCollection transforms a slice of ApiKey models to a slice of ApiKeyDTOs
+func TransformApiKeyCollection(keys []models.ApiKey) []ApiKeyDTO {
+	return transformer.TransformCollection(keys, FromApiKeyModel)
+}
@@ -15,9 +15,10 @@ type BrandDTO struct {
 	Name      string         `json:"name"`
 	Slug      string         `json:"slug"`
 	Status    string         `json:"status"`
-	CreatedAt *time.Time     `json:"createdAt,omitempty"`
-	UpdatedAt *time.Time     `json:"updatedAt,omitempty"`
-	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty"`
+	Version   int64          `json:"version,omitempty"`
+	CreatedAt *time.Time     `json:"created_at,omitempty"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty"`
 }
 
 // FromModel converts a Brand model to a BrandDTO
@@ -27,6 +28,7 @@ func FromModel(brand models.Brand) BrandDTO {
 		Name:      brand.Name,
 		Slug:      brand.Slug,
 		Status:    string(brand.Status),
+		Version:   brand.Version,
 		CreatedAt: &brand.CreatedAt,
 		UpdatedAt: &brand.UpdatedAt,
 		DeletedAt: brand.DeletedAt,
@@ -36,10 +38,11 @@ func FromModel(brand models.Brand) BrandDTO {
 // ToModel converts a BrandDTO to a Brand model
 func (dto BrandDTO) ToModel() models.Brand {
 	return models.Brand{
-		ID:     dto.ID,
-		Name:   dto.Name,
-		Slug:   dto.Slug,
-		Status: constant.StatusEnum(dto.Status),
+		ID:        dto.ID,
+		Name:      dto.Name,
+		Slug:      dto.Slug,
+		Status:    constant.StatusEnum(dto.Status),
+		Version:   dto.Version,
 		CreatedAt: *dto.CreatedAt,
 		UpdatedAt: *dto.UpdatedAt,
 		DeletedAt: dto.DeletedAt,
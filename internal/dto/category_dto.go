@@ -11,40 +11,63 @@ import (
 
 // CategoryDTO represents the data transfer object for Category
 type CategoryDTO struct {
-	ID        string         `json:"id"`
-	Name      string         `json:"name"`
-	Slug      string         `json:"slug"`
-	Status    string         `json:"status"`
-	CreatedAt *time.Time     `json:"createdAt,omitempty"`
-	UpdatedAt *time.Time     `json:"updatedAt,omitempty"`
-	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Slug        string         `json:"slug"`
+	Status      string         `json:"status"`
+	Description string         `json:"description"`
+	MediaID     string         `json:"media_id,omitempty"`
+	Media       *MediaDTO      `json:"media,omitempty"`
+	Version     int64          `json:"version,omitempty"`
+	CreatedAt   *time.Time     `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty"`
 }
 
 // FromCategoryModel converts a Category model to a CategoryDTO
 func FromCategoryModel(category models.Category) CategoryDTO {
+	var media *MediaDTO
+	if category.Media != nil {
+		mediaDTO := FromMediaModel(*category.Media)
+		media = &mediaDTO
+	}
+
 	return CategoryDTO{
 		ID:   category.ID,
 		Name: category.Name,
 		Slug: category.Slug,
 
-		Status:    string(category.Status),
-		CreatedAt: &category.CreatedAt,
-		UpdatedAt: &category.UpdatedAt,
-		DeletedAt: category.DeletedAt,
+		Status:      string(category.Status),
+		Description: category.Description,
+		MediaID:     category.MediaID,
+		Media:       media,
+		Version:     category.Version,
+		CreatedAt:   &category.CreatedAt,
+		UpdatedAt:   &category.UpdatedAt,
+		DeletedAt:   category.DeletedAt,
 	}
 }
 
 // ToCategoryModel converts a CategoryDTO to a Category model
 func (dto CategoryDTO) ToCategoryModel() models.Category {
+	var media *models.Media
+	if dto.Media != nil {
+		mediaModel := dto.Media.ToMediaModel()
+		media = &mediaModel
+	}
 
 	return models.Category{
-		ID:        dto.ID,
-		Name:      dto.Name,
-		Slug:      dto.Slug,
-		Status:    constant.StatusEnum(dto.Status),
-		CreatedAt: *dto.CreatedAt,
-		UpdatedAt: *dto.UpdatedAt,
-		DeletedAt: dto.DeletedAt,
+		ID:          dto.ID,
+		Name:        dto.Name,
+		Slug:        dto.Slug,
+		Status:      constant.StatusEnum(dto.Status),
+		Description: dto.Description,
+		MediaID:     dto.MediaID,
+		Media:       media,
+		Version:     dto.Version,
+		CreatedAt:   *dto.CreatedAt,
+		UpdatedAt:   *dto.UpdatedAt,
+		DeletedAt:   dto.DeletedAt,
 	}
 }
 
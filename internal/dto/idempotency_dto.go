@@ -0,0 +1,19 @@
+package dto
+
+import "beautyessentials.com/internal/models"
+
+// IdempotentResponseDTO is the stored response of a request made with an idempotency key
+type IdempotentResponseDTO struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// FromIdempotencyKeyModel converts the response stored on an IdempotencyKey model to an IdempotentResponseDTO
+func FromIdempotencyKeyModel(key models.IdempotencyKey) IdempotentResponseDTO {
+	return IdempotentResponseDTO{
+		StatusCode:  key.StatusCode,
+		ContentType: key.ContentType,
+		Body:        key.Body,
+	}
+}
@@ -3,27 +3,99 @@ package dto
 import (
 	"time"
 
+	"beautyessentials.com/internal/constant"
 	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/utils/imageurl"
 	"beautyessentials.com/internal/utils/transformer"
 )
 
 // MediaDTO represents the data transfer object for Media
 type MediaDTO struct {
+	ID        string                      `json:"id"`
+	FileID    string                      `json:"file_id"`
+	URL       string                      `json:"url"`
+	ThumbURL  string                      `json:"thumb_url"`
+	Variants  map[string]imageurl.Variant `json:"variants,omitempty"`
+	SrcSet    string                      `json:"srcset,omitempty"`
+	FolderID  *string                     `json:"folder_id"`
+	Tags      []MediaTagDTO               `json:"tags"`
+	CreatedAt *time.Time                  `json:"created_at,omitempty"`
+	UpdatedAt *time.Time                  `json:"updated_at,omitempty"`
+}
+
+// MediaTagDTO represents the data transfer object for MediaTag
+type MediaTagDTO struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// MediaFolderDTO represents the data transfer object for MediaFolder
+type MediaFolderDTO struct {
 	ID        string     `json:"id"`
-	FileID    string     `json:"file_id"`
-	URL       string     `json:"url"`
-	ThumbURL  string     `json:"thumb_url"`
+	Name      string     `json:"name"`
+	ParentID  *string    `json:"parent_id"`
+	Path      string     `json:"path"`
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 }
 
+// MediaUsageDTO represents an entity a media is attached to
+type MediaUsageDTO struct {
+	ID           string `json:"id"`
+	Entity       string `json:"entity"`
+	MediableID   string `json:"mediable_id"`
+	MediableType string `json:"mediable_type"`
+}
+
+// MediaDuplicateGroupDTO represents a set of media that look alike
+type MediaDuplicateGroupDTO struct {
+	MaxDistance int        `json:"max_distance"`
+	Media       []MediaDTO `json:"media"`
+}
+
+// MediaSyncReportDTO summarises an import of remote storage files into the media library
+type MediaSyncReportDTO struct {
+	DryRun   bool                `json:"dry_run"`
+	Scanned  int                 `json:"scanned"`
+	Imported int                 `json:"imported"`
+	Skipped  int                 `json:"skipped"`
+	Failed   int                 `json:"failed"`
+	Media    []MediaDTO          `json:"media"`
+	Errors   []MediaSyncErrorDTO `json:"errors"`
+}
+
+// MediaSyncErrorDTO describes a remote file that could not be imported
+type MediaSyncErrorDTO struct {
+	FileID  string `json:"file_id"`
+	Message string `json:"message"`
+}
+
+// MediaUploadAuthDTO represents the parameters a client needs to upload directly to storage
+type MediaUploadAuthDTO struct {
+	Token       string `json:"token"`
+	Expire      int64  `json:"expire"`
+	Signature   string `json:"signature"`
+	PublicKey   string `json:"public_key"`
+	URLEndpoint string `json:"url_endpoint"`
+	// Folder is the storage folder the file must be uploaded to, empty for the root
+	Folder string `json:"folder"`
+	// Receipt binds the credentials to the folder; it is sent back with the confirmation
+	Receipt string `json:"receipt"`
+}
+
 // FromMediaModel converts a Media model to a MediaDTO
 func FromMediaModel(media models.Media) MediaDTO {
+	variants := imageurl.Variants(media.URL)
 	return MediaDTO{
 		ID:        media.ID,
 		FileID:    media.FileID,
 		URL:       media.URL,
 		ThumbURL:  media.ThumbURL,
+		Variants:  variants,
+		SrcSet:    imageurl.SrcSet(variants),
+		FolderID:  media.FolderID,
+		Tags:      TransformMediaTagCollection(media.Tags),
 		CreatedAt: &media.CreatedAt,
 		UpdatedAt: &media.UpdatedAt,
 	}
@@ -37,6 +109,7 @@ func (dto MediaDTO) ToMediaModel() models.Media {
 		FileID:    dto.FileID,
 		URL:       dto.URL,
 		ThumbURL:  dto.ThumbURL,
+		FolderID:  dto.FolderID,
 		CreatedAt: *dto.CreatedAt,
 		UpdatedAt: *dto.UpdatedAt,
 	}
@@ -51,3 +124,53 @@ func TransformMediaCollection(media []models.Media) []MediaDTO {
 func TransformMediaPagination(paginatedResult map[string]interface{}) map[string]interface{} {
 	return transformer.TransformPagination(paginatedResult, FromMediaModel)
 }
+
+// FromMediableModel converts a Mediable model to a MediaUsageDTO
+func FromMediableModel(mediable models.Mediable) MediaUsageDTO {
+	entity, ok := constant.MediableEntities[mediable.MediableType]
+	if !ok {
+		entity = mediable.MediableType
+	}
+	return MediaUsageDTO{
+		ID:           mediable.ID,
+		Entity:       entity,
+		MediableID:   mediable.MediableID,
+		MediableType: mediable.MediableType,
+	}
+}
+
+// TransformMediaUsageCollection transforms a slice of Mediable models to a slice of MediaUsageDTOs
+func TransformMediaUsageCollection(mediables []models.Mediable) []MediaUsageDTO {
+	return transformer.TransformCollection(mediables, FromMediableModel)
+}
+
+// FromMediaTagModel converts a MediaTag model to a MediaTagDTO
+func FromMediaTagModel(tag models.MediaTag) MediaTagDTO {
+	return MediaTagDTO{
+		ID:   tag.ID,
+		Name: tag.Name,
+		Slug: tag.Slug,
+	}
+}
+
+// TransformMediaTagCollection transforms a slice of MediaTag models to a slice of MediaTagDTOs
+func TransformMediaTagCollection(tags []models.MediaTag) []MediaTagDTO {
+	return transformer.TransformCollection(tags, FromMediaTagModel)
+}
+
+// FromMediaFolderModel converts a MediaFolder model to a MediaFolderDTO
+func FromMediaFolderModel(folder models.MediaFolder) MediaFolderDTO {
+	return MediaFolderDTO{
+		ID:        folder.ID,
+		Name:      folder.Name,
+		ParentID:  folder.ParentID,
+		Path:      folder.Path,
+		CreatedAt: &folder.CreatedAt,
+		UpdatedAt: &folder.UpdatedAt,
+	}
+}
+
+// TransformMediaFolderCollection transforms a slice of MediaFolder models to a slice of MediaFolderDTOs
+func TransformMediaFolderCollection(folders []models.MediaFolder) []MediaFolderDTO {
+	return transformer.TransformCollection(folders, FromMediaFolderModel)
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"sort"
+	"time"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/utils/transformer"
+)
+
+// RoleDTO represents the data transfer object for Role
+type RoleDTO struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	IsSystem    bool       `json:"is_system"`
+	Permissions []string   `json:"permissions"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// PermissionDTO describes a permission that can be granted to roles
+type PermissionDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// FromRoleModel converts a Role model to a RoleDTO
+func FromRoleModel(role models.Role) RoleDTO {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Permission)
+	}
+	sort.Strings(permissions)
+
+	return RoleDTO{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: permissions,
+		CreatedAt:   &role.CreatedAt,
+		UpdatedAt:   &role.UpdatedAt,
+	}
+}
+
+// TransformRoleCollection transforms a slice of Role models to a slice of RoleDTOs
+func TransformRoleCollection(roles []models.Role) []RoleDTO {
+	return transformer.TransformCollection(roles, FromRoleModel)
+}
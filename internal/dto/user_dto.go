@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"sort"
+	"time"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/utils/transformer"
+)
+
+// UserDTO represents the data transfer object for User
+type UserDTO struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Roles       []string   `json:"roles"`
+	Permissions []string   `json:"permissions"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// AuthTokensDTO represents the tokens issued on login, registration and refresh
+type AuthTokensDTO struct {
+	TokenType        string  `json:"token_type"`
+	AccessToken      string  `json:"access_token"`
+	ExpiresIn        int64   `json:"expires_in"`
+	RefreshToken     string  `json:"refresh_token"`
+	RefreshExpiresIn int64   `json:"refresh_expires_in"`
+	User             UserDTO `json:"user"`
+}
+
+// FromUserModel converts a User model to a UserDTO
+func FromUserModel(user models.User) UserDTO {
+	roles := make([]string, 0, len(user.Roles))
+	granted := make(map[string]bool)
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+		for _, permission := range role.Permissions {
+			granted[permission.Permission] = true
+		}
+	}
+
+	// Flatten the permissions of every role
+	permissions := make([]string, 0, len(granted))
+	for permission := range granted {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(roles)
+	sort.Strings(permissions)
+
+	return UserDTO{
+		ID:          user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		Roles:       roles,
+		Permissions: permissions,
+		CreatedAt:   &user.CreatedAt,
+		UpdatedAt:   &user.UpdatedAt,
+	}
+}
+
+// TransformUserCollection transforms a slice of User models to a slice of UserDTOs
+func TransformUserCollection(users []models.User) []UserDTO {
+	return transformer.TransformCollection(users, FromUserModel)
+}
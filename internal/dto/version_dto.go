@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"beautyessentials.com/internal/models"
+)
+
+// VersionDTO identifies the state of a collection for conditional requests
+type VersionDTO struct {
+	Count        int64        `json:"count"`
+	LastModified time.Time    `json:"last_modified"`
+	Related      []VersionDTO `json:"related,omitempty"`
+}
+
+// FromTableVersion converts a TableVersion model to a VersionDTO
+func FromTableVersion(version models.TableVersion) VersionDTO {
+	result := VersionDTO{
+		Count:        version.Count,
+		LastModified: version.LastModified,
+	}
+	for _, related := range version.Related {
+		result.Related = append(result.Related, FromTableVersion(related))
+	}
+	return result
+}
+
+// Latest returns the latest change of the collection and of the rows it embeds
+func (v VersionDTO) Latest() time.Time {
+	latest := v.LastModified
+	for _, related := range v.Related {
+		if modified := related.Latest(); modified.After(latest) {
+			latest = modified
+		}
+	}
+	return latest
+}
+
+// Tag returns an opaque string that changes whenever the collection does. Every
+// related table is a part of its own, so their changes never cancel out.
+func (v VersionDTO) Tag() string {
+	parts := []string{fmt.Sprintf("%x-%x", v.Count, v.LastModified.UnixNano())}
+	for _, related := range v.Related {
+		parts = append(parts, related.Tag())
+	}
+	return strings.Join(parts, ".")
+}
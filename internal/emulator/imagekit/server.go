@@ -0,0 +1,641 @@
+// Package imagekit implements a local, disk-backed stand-in for the parts of the
+// ImageKit API this service uses: upload, file details, list, delete, batch delete,
+// move and folders. Point IMAGEKIT_UPLOAD_BASE_URL, IMAGEKIT_API_BASE_URL and
+// IMAGEKIT_URL_ENDPOINT (suffixed with /files) at it for local development, or
+// wrap it in an httptest.Server from Go tests.
+package imagekit
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// indexFile holds file metadata next to the stored files
+const indexFile = ".imagekit-emulator.json"
+
+// maxUploadSize bounds the size of a single uploaded file
+const maxUploadSize = 32 << 20
+
+// Options configures the emulator
+type Options struct {
+	// PublicURL is the externally reachable base URL used to build file URLs
+	PublicURL string
+	// PrivateKey, when set, is required as the basic auth username on API calls
+	PrivateKey string
+	// Client fetches remote files for uploads given as a URL
+	Client *http.Client
+}
+
+// File is the metadata stored for an uploaded file
+type File struct {
+	FileID    string    `json:"fileId"`
+	Name      string    `json:"name"`
+	FilePath  string    `json:"filePath"`
+	Size      int64     `json:"size"`
+	FileType  string    `json:"fileType"`
+	MimeType  string    `json:"mime"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Server is an ImageKit-compatible HTTP server storing files on disk
+type Server struct {
+	root       string
+	privateKey string
+	client     *http.Client
+
+	mu        sync.RWMutex
+	publicURL string
+	files     map[string]*File
+	folders   map[string]bool
+	mux       *http.ServeMux
+}
+
+// NewServer creates an emulator storing files under root, reloading any previous state
+func NewServer(root string, opts Options) (*Server, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	s := &Server{
+		root:       root,
+		privateKey: opts.PrivateKey,
+		client:     client,
+		publicURL:  strings.TrimRight(opts.PublicURL, "/"),
+		files:      make(map[string]*File),
+		folders:    make(map[string]bool),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/files/upload", s.authenticated(s.handleUpload))
+	mux.HandleFunc("GET /v1/files", s.authenticated(s.handleList))
+	mux.HandleFunc("GET /v1/files/{id}/details", s.authenticated(s.handleDetails))
+	mux.HandleFunc("DELETE /v1/files/{id}", s.authenticated(s.handleDelete))
+	mux.HandleFunc("POST /v1/files/batch/deleteByFileIds", s.authenticated(s.handleBatchDelete))
+	mux.HandleFunc("POST /v1/files/move", s.authenticated(s.handleMove))
+	mux.HandleFunc("POST /v1/folder", s.authenticated(s.handleCreateFolder))
+	mux.HandleFunc("DELETE /v1/folder", s.authenticated(s.handleDeleteFolder))
+	mux.HandleFunc("GET /files/{path...}", s.handleServe)
+	s.mux = mux
+
+	return s, nil
+}
+
+// SetPublicURL changes the base URL used to build file URLs, e.g. once an httptest.Server has started
+func (s *Server) SetPublicURL(publicURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.publicURL = strings.TrimRight(publicURL, "/")
+}
+
+// URLEndpoint returns the URL endpoint files are served from
+func (s *Server) URLEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.publicURL + "/files"
+}
+
+// Files returns a snapshot of the stored files ordered by creation
+func (s *Server) Files() []File {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedFiles()
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// authenticated rejects API calls that don't carry the configured private key
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.privateKey != "" {
+			user, _, ok := r.BasicAuth()
+			if !ok || user != s.privateKey {
+				writeError(w, http.StatusUnauthorized, "Your request does not contain private API key.")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// handleUpload stores a file given as base64, a URL or a multipart part
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize*2)
+
+	var content []byte
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		content, err = readMultipartFile(r)
+	} else if err = r.ParseForm(); err == nil {
+		content, err = s.decodeFileField(r, r.PostForm.Get("file"))
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fileName := path.Base(r.FormValue("fileName"))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		writeError(w, http.StatusBadRequest, "Missing fileName parameter for upload")
+		return
+	}
+
+	folder := cleanFolder(r.FormValue("folder"))
+	fileID := newID()
+	if r.FormValue("useUniqueFileName") != "false" {
+		ext := path.Ext(fileName)
+		fileName = strings.TrimSuffix(fileName, ext) + "_" + fileID[:8] + ext
+	}
+	filePath := path.Join("/", folder, fileName)
+
+	// Write the content to disk
+	diskPath := s.diskPath(filePath)
+	if err := os.MkdirAll(filepath.Dir(diskPath), 0o755); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := os.WriteFile(diskPath, content, 0o644); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	file := &File{
+		FileID:    fileID,
+		Name:      fileName,
+		FilePath:  filePath,
+		Size:      int64(len(content)),
+		FileType:  fileType(content),
+		MimeType:  http.DetectContentType(content),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	s.files[fileID] = file
+	err = s.save()
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.describe(file))
+}
+
+// handleList lists files with ImageKit's skip/limit pagination
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	skip, _ := strconv.Atoi(query.Get("skip"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	folder := query.Get("path")
+
+	s.mu.RLock()
+	files := s.sortedFiles()
+	s.mu.RUnlock()
+
+	if query.Get("sort") == "DESC_CREATED" {
+		for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+			files[i], files[j] = files[j], files[i]
+		}
+	}
+
+	result := make([]map[string]interface{}, 0)
+	if query.Get("type") != "folder" {
+		for _, file := range files {
+			if folder != "" && !strings.HasPrefix(file.FilePath, cleanFolder(folder)+"/") {
+				continue
+			}
+			result = append(result, s.describe(&file))
+		}
+	}
+
+	// Apply pagination
+	if skip > len(result) {
+		skip = len(result)
+	}
+	end := min(skip+limit, len(result))
+
+	writeJSON(w, http.StatusOK, result[skip:end])
+}
+
+// handleDetails returns the metadata of a single file
+func (s *Server) handleDetails(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	file, ok := s.files[r.PathValue("id")]
+	s.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "The requested file does not exist.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.describe(file))
+}
+
+// handleDelete deletes a single file
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.deleteFile(r.PathValue("id")) {
+		writeError(w, http.StatusNotFound, "The requested file does not exist.")
+		return
+	}
+	if err := s.save(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleBatchDelete deletes several files at once
+func (s *Server) handleBatchDelete(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		FileIDs []string `json:"fileIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.FileIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "fileIds parameter is missing.")
+		return
+	}
+	if len(body.FileIDs) > 100 {
+		writeError(w, http.StatusBadRequest, "fileIds must not contain more than 100 items.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// ImageKit fails the whole batch when any ID is unknown
+	var missing []string
+	for _, id := range body.FileIDs {
+		if _, ok := s.files[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"message":        "The requested file(s) does not exist.",
+			"missingFileIds": missing,
+		})
+		return
+	}
+
+	for _, id := range body.FileIDs {
+		s.deleteFile(id)
+	}
+	if err := s.save(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"successfullyDeletedFileIds": body.FileIDs,
+	})
+}
+
+// handleMove moves a file, given by path, into another folder
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		SourceFilePath  string `json:"sourceFilePath"`
+		DestinationPath string `json:"destinationPath"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.SourceFilePath == "" || body.DestinationPath == "" {
+		writeError(w, http.StatusBadRequest, "sourceFilePath and destinationPath parameters are required.")
+		return
+	}
+	source := path.Clean("/" + body.SourceFilePath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var file *File
+	for _, candidate := range s.files {
+		if candidate.FilePath == source {
+			file = candidate
+			break
+		}
+	}
+	if file == nil {
+		writeError(w, http.StatusNotFound, "No file found with filePath "+source)
+		return
+	}
+
+	folder := cleanFolder(body.DestinationPath)
+	destination := path.Join("/", folder, file.Name)
+	if err := os.MkdirAll(filepath.Dir(s.diskPath(destination)), 0o755); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := os.Rename(s.diskPath(file.FilePath), s.diskPath(destination)); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	file.FilePath = destination
+	if folder != "" {
+		s.folders[folder] = true
+	}
+	if err := s.save(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCreateFolder creates a folder
+func (s *Server) handleCreateFolder(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		FolderName       string `json:"folderName"`
+		ParentFolderPath string `json:"parentFolderPath"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.FolderName == "" {
+		writeError(w, http.StatusBadRequest, "folderName parameter is missing.")
+		return
+	}
+
+	folder := cleanFolder(path.Join(body.ParentFolderPath, body.FolderName))
+	if err := os.MkdirAll(s.diskPath(folder), 0o755); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.folders[folder] = true
+	err := s.save()
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{})
+}
+
+// handleDeleteFolder deletes a folder and every file inside it
+func (s *Server) handleDeleteFolder(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		FolderPath string `json:"folderPath"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.FolderPath == "" {
+		writeError(w, http.StatusBadRequest, "folderPath parameter is missing.")
+		return
+	}
+	folder := cleanFolder(body.FolderPath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.folders[folder] {
+		writeError(w, http.StatusNotFound, "No folder found with folderPath "+folder)
+		return
+	}
+
+	for id, file := range s.files {
+		if strings.HasPrefix(file.FilePath, folder+"/") {
+			delete(s.files, id)
+		}
+	}
+	for other := range s.folders {
+		if other == folder || strings.HasPrefix(other, folder+"/") {
+			delete(s.folders, other)
+		}
+	}
+	if err := os.RemoveAll(s.diskPath(folder)); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := s.save(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleServe serves stored files, ignoring any "tr:" transformation segment
+func (s *Server) handleServe(w http.ResponseWriter, r *http.Request) {
+	filePath := r.PathValue("path")
+	if strings.HasPrefix(filePath, "tr:") {
+		if _, rest, ok := strings.Cut(filePath, "/"); ok {
+			filePath = rest
+		}
+	}
+
+	http.ServeFile(w, r, s.diskPath("/"+filePath))
+}
+
+// decodeFileField decodes an upload "file" field holding base64 content, a data URI or a URL
+func (s *Server) decodeFileField(r *http.Request, value string) ([]byte, error) {
+	if value == "" {
+		return nil, errors.New("Missing file parameter for upload")
+	}
+
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, value, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch file: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch file: status code %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxUploadSize))
+	}
+
+	if _, data, ok := strings.Cut(value, ";base64,"); ok && strings.HasPrefix(value, "data:") {
+		value = data
+	}
+	content, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("file parameter is not valid base64")
+	}
+	return content, nil
+}
+
+// readMultipartFile reads the binary "file" part of a multipart upload
+func readMultipartFile(r *http.Request) ([]byte, error) {
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return nil, err
+	}
+	part, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, errors.New("Missing file parameter for upload")
+	}
+	defer part.Close()
+	return io.ReadAll(part)
+}
+
+// describe renders a file the way the ImageKit API does
+func (s *Server) describe(file *File) map[string]interface{} {
+	s.mu.RLock()
+	endpoint := s.publicURL + "/files"
+	s.mu.RUnlock()
+
+	fileURL := endpoint + file.FilePath
+	return map[string]interface{}{
+		"type":         "file",
+		"fileId":       file.FileID,
+		"name":         file.Name,
+		"filePath":     file.FilePath,
+		"url":          fileURL,
+		"thumbnail":    endpoint + "/tr:n-ik_ml_thumbnail" + file.FilePath,
+		"thumbnailUrl": endpoint + "/tr:n-ik_ml_thumbnail" + file.FilePath,
+		"size":         file.Size,
+		"fileType":     file.FileType,
+		"mime":         file.MimeType,
+		"createdAt":    file.CreatedAt,
+		"updatedAt":    file.CreatedAt,
+	}
+}
+
+// deleteFile removes a file from disk and the index; callers hold the lock
+func (s *Server) deleteFile(id string) bool {
+	file, ok := s.files[id]
+	if !ok {
+		return false
+	}
+	_ = os.Remove(s.diskPath(file.FilePath))
+	delete(s.files, id)
+	return true
+}
+
+// sortedFiles returns the files ordered by creation; callers hold the lock
+func (s *Server) sortedFiles() []File {
+	files := make([]File, 0, len(s.files))
+	for _, file := range s.files {
+		files = append(files, *file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].CreatedAt.Equal(files[j].CreatedAt) {
+			return files[i].FileID < files[j].FileID
+		}
+		return files[i].CreatedAt.Before(files[j].CreatedAt)
+	})
+	return files
+}
+
+// diskPath maps an ImageKit path to a location under the storage root
+func (s *Server) diskPath(filePath string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+filePath)))
+}
+
+// state is the persisted index
+type state struct {
+	Files   []*File  `json:"files"`
+	Folders []string `json:"folders"`
+}
+
+// load reads the index from disk if present
+func (s *Server) load() error {
+	data, err := os.ReadFile(filepath.Join(s.root, indexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("failed to parse index: %w", err)
+	}
+	for _, file := range st.Files {
+		s.files[file.FileID] = file
+	}
+	for _, folder := range st.Folders {
+		s.folders[folder] = true
+	}
+	return nil
+}
+
+// save writes the index to disk; callers hold the lock
+func (s *Server) save() error {
+	st := state{Files: make([]*File, 0, len(s.files)), Folders: make([]string, 0, len(s.folders))}
+	for _, file := range s.files {
+		st.Files = append(st.Files, file)
+	}
+	for folder := range s.folders {
+		st.Folders = append(st.Folders, folder)
+	}
+	sort.Strings(st.Folders)
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write atomically so a crash never leaves a truncated index
+	tmp := filepath.Join(s.root, indexFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(s.root, indexFile))
+}
+
+// cleanFolder normalises a folder path to "/a/b" form, or "" for the root
+func cleanFolder(folder string) string {
+	folder = path.Clean("/" + strings.Trim(folder, "/"))
+	if folder == "/" {
+		return ""
+	}
+	return folder
+}
+
+// fileType reports "image" for image content and "non-image" otherwise
+func fileType(content []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	if strings.HasPrefix(mediaType, "image/") {
+		return "image"
+	}
+	return "non-image"
+}
+
+// newID returns a random 24 character hex file ID, like ImageKit's
+func newID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an ImageKit style error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
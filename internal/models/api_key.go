@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// ApiKey represents a non-interactive credential for service-to-service access.
+// Only the key prefix, used for lookup, and the SHA-256 hash of the full key are stored.
+type ApiKey struct {
+	ID          string        `json:"id" gorm:"primaryKey;type:char(26)"`
+	Name        string        `json:"name" gorm:"type:varchar(255);not null"`
+	Prefix      string        `json:"prefix" gorm:"type:varchar(16);not null;uniqueIndex"`
+	KeyHash     string        `json:"-" gorm:"type:char(64);not null"`
+	Scopes      []ApiKeyScope `json:"scopes" gorm:"foreignKey:ApiKeyID;constraint:OnDelete:CASCADE"`
+	RateLimit   int           `json:"rate_limit" gorm:"not null;default:0"`
+	ExpiresAt   *time.Time    `json:"expires_at"`
+	LastUsedAt  *time.Time    `json:"last_used_at"`
+	RevokedAt   *time.Time    `json:"revoked_at"`
+	CreatedByID *string       `json:"created_by_id" gorm:"type:char(26);index"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// BeforeCreate will set a ULID rather than numeric ID
+func (k *ApiKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		k.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the ApiKey model
+func (ApiKey) TableName() string {
+	return "api_keys"
+}
+
+// ApiKeyScope grants a permission to an API key
+type ApiKeyScope struct {
+	ApiKeyID string `json:"api_key_id" gorm:"primaryKey;type:char(26)"`
+	Scope    string `json:"scope" gorm:"primaryKey;type:varchar(100)"`
+}
+
+// TableName specifies the table name for the ApiKeyScope model
+func (ApiKeyScope) TableName() string {
+	return "api_key_scopes"
+}
@@ -15,6 +15,7 @@ type Brand struct {
 	Name      string              `json:"name" gorm:"type:varchar(255);not null"`
 	Slug      string              `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex"`
 	Status    constant.StatusEnum `json:"status" gorm:"type:enum('active','inactive');default:active"`
+	Version   int64               `json:"version" gorm:"not null;default:1"`
 	CreatedAt time.Time           `json:"created_at"`
 	UpdatedAt time.Time           `json:"updated_at"`
 	DeletedAt gorm.DeletedAt      `json:"deleted_at,omitempty" gorm:"index"`
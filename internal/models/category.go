@@ -11,13 +11,18 @@ import (
 
 // Category represents a category in the system
 type Category struct {
-	ID        string              `json:"id" gorm:"primaryKey;type:char(26)"`
-	Name      string              `json:"name" gorm:"type:varchar(255);not null"`
-	Slug      string              `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex"`
-	Status    constant.StatusEnum `json:"status" gorm:"type:enum('active','inactive');default:active"`
-	CreatedAt time.Time           `json:"created_at"`
-	UpdatedAt time.Time           `json:"updated_at"`
-	DeletedAt gorm.DeletedAt      `json:"deleted_at,omitempty" gorm:"index"`
+	ID          string              `json:"id" gorm:"primaryKey;type:char(26)"`
+	Name        string              `json:"name" gorm:"type:varchar(255);not null"`
+	Slug        string              `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex"`
+	Status      constant.StatusEnum `json:"status" gorm:"type:enum('active','inactive');default:active"`
+	Description string              `json:"description" gorm:"type:text"`
+	Version     int64               `json:"version" gorm:"not null;default:1"`
+	// MediaID and Media are the attached media, loaded by the repository
+	MediaID   string         `json:"media_id,omitempty" gorm:"-"`
+	Media     *Media         `json:"media,omitempty" gorm:"-"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 	// Media       []Media             `json:"media,omitempty" gorm:"many2many:mediables;foreignKey:ID;joinForeignKey:mediable_id;joinReferences:media_id;where:mediable_type = 'App\\Model\\Category'"`
 }
 
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey records a request sent with an Idempotency-Key header. The
+// response is stored once the request completes so retries can be replayed;
+// a zero StatusCode means the first request is still being handled.
+type IdempotencyKey struct {
+	ID string `json:"id" gorm:"primaryKey;type:char(26)"`
+	// Scope separates the keys of different callers and routes
+	Scope string `json:"scope" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope_key"`
+	Key   string `json:"key" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope_key"`
+	// Fingerprint is the SHA-256 of the request body
+	Fingerprint string    `json:"fingerprint" gorm:"type:char(64);not null"`
+	StatusCode  int       `json:"status_code" gorm:"not null;default:0"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(255)"`
+	Body        []byte    `json:"-"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a ULID rather than numeric ID
+func (k *IdempotencyKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		k.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the IdempotencyKey model
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
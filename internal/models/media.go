@@ -9,12 +9,16 @@ import (
 
 // Media represents a media file in the system
 type Media struct {
-	ID          string    `json:"id" gorm:"primaryKey;type:char(26)"`
-	FileID      string    `json:"file_id" gorm:"type:varchar(255)"`	
-	URL         string    `json:"url" gorm:"type:varchar(255)"`
-	ThumbURL    string    `json:"thumb_url" gorm:"type:varchar(255)"`	
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             string     `json:"id" gorm:"primaryKey;type:char(26)"`
+	FileID         string     `json:"file_id" gorm:"type:varchar(255)"`
+	URL            string     `json:"url" gorm:"type:varchar(255)"`
+	ThumbURL       string     `json:"thumb_url" gorm:"type:varchar(255)"`
+	FolderID       *string    `json:"folder_id" gorm:"type:char(26);index"`
+	ContentHash    string     `json:"content_hash" gorm:"type:char(64);uniqueIndex:idx_medias_content_hash_unique,where:content_hash <> ''"`
+	PerceptualHash string     `json:"perceptual_hash" gorm:"type:char(16)"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Tags           []MediaTag `json:"tags,omitempty" gorm:"many2many:media_tag;joinForeignKey:MediaID;joinReferences:TagID"`
 }
 
 // BeforeCreate will set a ULID rather than numeric ID
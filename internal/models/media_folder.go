@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// MediaFolder represents a (possibly nested) folder in the media library
+type MediaFolder struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:char(26)"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null;uniqueIndex:idx_media_folders_parent_name"`
+	ParentID  *string   `json:"parent_id" gorm:"type:char(26);uniqueIndex:idx_media_folders_parent_name"`
+	Path      string    `json:"path" gorm:"type:varchar(1024);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a ULID rather than numeric ID
+func (f *MediaFolder) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		f.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the MediaFolder model
+func (MediaFolder) TableName() string {
+	return "media_folders"
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"beautyessentials.com/internal/utils"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// MediaTag represents a free-form tag applied to media
+type MediaTag struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:char(26)"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
+	Slug      string    `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a ULID rather than numeric ID and generate a slug
+func (t *MediaTag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		t.ID = id.String()
+	}
+
+	// Generate slug from name if not provided
+	if t.Slug == "" && t.Name != "" {
+		t.Slug = utils.GenerateSlug(t.Name)
+	}
+
+	return nil
+}
+
+// TableName specifies the table name for the MediaTag model
+func (MediaTag) TableName() string {
+	return "media_tags"
+}
+
+// MediaTagPivot links media to tags
+type MediaTagPivot struct {
+	MediaID string `gorm:"primaryKey;type:char(26)"`
+	TagID   string `gorm:"primaryKey;type:char(26);index"`
+	// CreatedAt lets catalog versions notice media being tagged
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for the MediaTagPivot model
+func (MediaTagPivot) TableName() string {
+	return "media_tag"
+}
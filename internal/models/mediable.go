@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// Mediable represents the polymorphic attachment of a media to another entity
+type Mediable struct {
+	ID           string `json:"id" gorm:"primaryKey;type:char(26)"`
+	MediableID   string `json:"mediable_id" gorm:"type:char(26);index"`
+	MediableType string `json:"mediable_type" gorm:"type:varchar(255)"`
+	MediaID      string `json:"media_id" gorm:"type:char(26);index"`
+}
+
+// BeforeCreate will set a ULID rather than numeric ID
+func (m *Mediable) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		m.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the Mediable model
+func (Mediable) TableName() string {
+	return "mediables"
+}
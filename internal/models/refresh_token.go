@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// RefreshToken represents an issued refresh token. Only the SHA-256 hash of the
+// token is stored; tokens rotated from the same login share a FamilyID so a
+// reused token can revoke the whole chain.
+type RefreshToken struct {
+	ID           string     `json:"id" gorm:"primaryKey;type:char(26)"`
+	UserID       string     `json:"user_id" gorm:"type:char(26);not null;index"`
+	FamilyID     string     `json:"family_id" gorm:"type:char(26);not null;index"`
+	TokenHash    string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *string    `json:"replaced_by_id" gorm:"type:char(26)"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// BeforeCreate will set a ULID rather than numeric ID
+func (t *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		t.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// Role represents a named set of permissions that can be assigned to users
+type Role struct {
+	ID          string           `json:"id" gorm:"primaryKey;type:char(26)"`
+	Name        string           `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Description string           `json:"description" gorm:"type:varchar(255)"`
+	IsSystem    bool             `json:"is_system" gorm:"not null;default:false"`
+	Permissions []RolePermission `json:"permissions" gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// BeforeCreate will set a ULID rather than numeric ID
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		r.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the Role model
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RolePermission grants a permission to a role
+type RolePermission struct {
+	RoleID     string `json:"role_id" gorm:"primaryKey;type:char(26)"`
+	Permission string `json:"permission" gorm:"primaryKey;type:varchar(100)"`
+}
+
+// TableName specifies the table name for the RolePermission model
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// UserRole assigns a role to a user
+type UserRole struct {
+	UserID string `gorm:"primaryKey;type:char(26)"`
+	RoleID string `gorm:"primaryKey;type:char(26);index"`
+}
+
+// TableName specifies the table name for the UserRole model
+func (UserRole) TableName() string {
+	return "user_roles"
+}
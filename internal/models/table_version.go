@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// TableVersion summarises the rows of a table so readers can tell whether it changed
+type TableVersion struct {
+	// Count includes soft deleted rows
+	Count int64
+	// LastModified is the latest creation, update or soft deletion
+	LastModified time.Time
+	// Related holds the versions of related tables, e.g. embedded media, each kept
+	// apart so that a row added to one cannot cancel out a row removed from another
+	Related []TableVersion
+}
+
+// Include folds the versions of related rows, e.g. embedded media, into v
+func (v TableVersion) Include(related ...TableVersion) TableVersion {
+	v.Related = append(append([]TableVersion(nil), v.Related...), related...)
+	return v
+}
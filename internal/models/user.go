@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// User represents an account that can sign in to the API
+type User struct {
+	ID        string         `json:"id" gorm:"primaryKey;type:char(26)"`
+	Name      string         `json:"name" gorm:"type:varchar(255);not null"`
+	Email     string         `json:"email" gorm:"type:varchar(255);not null;uniqueIndex"`
+	Password  string         `json:"-" gorm:"type:varchar(255);not null"`
+	Roles     []Role         `json:"roles,omitempty" gorm:"many2many:user_roles;joinForeignKey:UserID;joinReferences:RoleID"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a ULID rather than numeric ID
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		// Generate a new ULID
+		id := ulid.Make()
+		u.ID = id.String()
+	}
+	return nil
+}
+
+// TableName specifies the table name for the User model
+func (User) TableName() string {
+	return "users"
+}
@@ -0,0 +1,277 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Auth describes who may call a route
+type Auth int
+
+const (
+	// AuthNone marks a public route
+	AuthNone Auth = iota
+	// AuthAny accepts a user access token or an API key
+	AuthAny
+	// AuthUser accepts only a user access token
+	AuthUser
+)
+
+// Route documents one registered route
+type Route struct {
+	Method string
+	// Path uses gin syntax, e.g. /api/v1/brands/:id
+	Path string
+	// Operation is the operationId, conventionally the handler method name
+	Operation   string
+	Tag         string
+	Summary     string
+	Description string
+	Auth        Auth
+	// Permission is the permission the route requires, if any
+	Permission string
+	Query      []Parameter
+	// Body is the JSON request struct and Form the multipart request struct
+	Body interface{}
+	Form interface{}
+	// Patch is the struct a JSON merge patch request body applies to
+	Patch interface{}
+	// Statuses are the success status codes, 200 when empty
+	Statuses []int
+	// Response is the type returned in the envelope's data field, nil when there is none
+	Response interface{}
+	// Errors are route specific error status codes, e.g. 409 for conflicts
+	Errors []int
+	// Paginated routes return a page with metadata when called with paginate=true
+	Paginated   bool
+	RateLimited bool
+	Deprecated  bool
+	// Conditional routes send ETag and Last-Modified and answer matching requests with 304
+	Conditional bool
+}
+
+// Spec holds the API-wide parts of a document
+type Spec struct {
+	Info            Info
+	Servers         []Server
+	Tags            []Tag
+	SecuritySchemes map[string]*SecurityScheme
+	// Security maps an auth mode to the security requirement of its routes
+	Security map[Auth][]map[string][]string
+	// Envelope wraps the data of a successful response
+	Envelope func(data *Schema) *Schema
+	// PaginatedEnvelope wraps a page of items
+	PaginatedEnvelope func(items *Schema) *Schema
+	// Error is the body of every error response
+	Error *Schema
+	// Problem is the application/problem+json body of error responses, nil when not offered
+	Problem *Schema
+}
+
+// Build generates the document for routes
+func Build(spec Spec, schemas *Schemas, routes []Route) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    spec.Info,
+		Servers: spec.Servers,
+		Tags:    spec.Tags,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	for _, route := range routes {
+		path := Path(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(route.Method)] = buildOperation(spec, schemas, route)
+	}
+
+	doc.Components = Components{
+		Schemas:         schemas.Components(),
+		SecuritySchemes: spec.SecuritySchemes,
+	}
+	return doc
+}
+
+// buildOperation documents a single route
+func buildOperation(spec Spec, schemas *Schemas, route Route) *Operation {
+	op := &Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: route.Operation,
+		Permission:  route.Permission,
+		Deprecated:  route.Deprecated,
+		Security:    spec.Security[route.Auth],
+		Responses:   make(map[string]*Response),
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if route.Permission != "" {
+		op.Description = strings.TrimSpace(op.Description + "\n\nRequires the `" + route.Permission + "` permission.")
+	}
+
+	// Path parameters come from the route itself so they cannot drift
+	for _, name := range pathParams(route.Path) {
+		param := Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			param.Schema.Pattern = ulidPattern
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	op.Parameters = append(op.Parameters, route.Query...)
+	if route.Conditional {
+		op.Parameters = append(op.Parameters,
+			Parameter{Name: "If-None-Match", In: "header", Description: "ETag of a cached response", Schema: &Schema{Type: "string"}},
+			Parameter{Name: "If-Modified-Since", In: "header", Description: "Last-Modified of a cached response", Schema: &Schema{Type: "string"}},
+		)
+	}
+
+	switch {
+	case route.Body != nil:
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			"application/json": {Schema: schemas.JSON(route.Body)},
+		}}
+	case route.Form != nil:
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			"multipart/form-data": {Schema: schemas.Form(route.Form)},
+		}}
+	case route.Patch != nil:
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			"application/merge-patch+json": {Schema: schemas.MergePatch(route.Patch)},
+		}}
+	}
+
+	// Successful responses
+	var data *Schema
+	if route.Response != nil {
+		data = schemas.JSON(route.Response)
+	}
+	body := spec.Envelope(data)
+	if route.Paginated && data != nil {
+		body = &Schema{OneOf: []*Schema{body, spec.PaginatedEnvelope(data.Items)}}
+	}
+	statuses := route.Statuses
+	if len(statuses) == 0 {
+		statuses = []int{http.StatusOK}
+	}
+	for _, status := range statuses {
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{"application/json": {Schema: body}},
+		}
+	}
+	if route.Conditional {
+		cacheHeaders := map[string]Header{
+			"ETag":          {Description: "Weak validator of the response", Schema: &Schema{Type: "string"}},
+			"Last-Modified": {Description: "When the data last changed", Schema: &Schema{Type: "string"}},
+			"Cache-Control": {Description: "Caching policy of the route", Schema: &Schema{Type: "string"}},
+		}
+		op.Responses[strconv.Itoa(statuses[0])].Headers = cacheHeaders
+		op.Responses[strconv.Itoa(http.StatusNotModified)] = &Response{
+			Description: http.StatusText(http.StatusNotModified),
+			Headers:     cacheHeaders,
+		}
+	}
+
+	// Error responses the middleware chain can produce for this route
+	errorStatuses := []int{http.StatusInternalServerError}
+	if route.Auth != AuthNone {
+		errorStatuses = append(errorStatuses, http.StatusUnauthorized)
+	}
+	if route.Permission != "" || route.Auth == AuthUser {
+		errorStatuses = append(errorStatuses, http.StatusForbidden)
+	}
+	if params := pathParams(route.Path); len(params) > 0 {
+		errorStatuses = append(errorStatuses, http.StatusNotFound)
+		// Malformed record IDs are rejected before the lookup
+		if params[0] == "id" {
+			errorStatuses = append(errorStatuses, http.StatusUnprocessableEntity)
+		}
+	}
+	if route.Body != nil || route.Form != nil || route.Patch != nil {
+		errorStatuses = append(errorStatuses, http.StatusUnprocessableEntity)
+	}
+	if route.RateLimited {
+		errorStatuses = append(errorStatuses, http.StatusTooManyRequests)
+	}
+	errorStatuses = append(errorStatuses, route.Errors...)
+	for _, status := range errorStatuses {
+		content := map[string]MediaType{"application/json": {Schema: spec.Error}}
+		if spec.Problem != nil {
+			content["application/problem+json"] = MediaType{Schema: spec.Problem}
+		}
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     content,
+		}
+	}
+
+	return op
+}
+
+// QueryParam documents a query string parameter
+func QueryParam(name string, schema *Schema, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// HeaderParam describes an optional request header
+func HeaderParam(name string, schema *Schema, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: schema}
+}
+
+// Path converts a gin route path to OpenAPI syntax, e.g. /brands/:id to /brands/{id}
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParams returns the parameter names of a gin route path
+func pathParams(ginPath string) []string {
+	var names []string
+	for _, segment := range strings.Split(ginPath, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			names = append(names, segment[1:])
+		}
+	}
+	return names
+}
+
+// Diff compares documented routes against the "METHOD /path" keys registered with the
+// router and returns an error listing every route missing from either side
+func Diff(routes []Route, registered []string) error {
+	documented := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		if documented[key] {
+			return fmt.Errorf("route %s is documented twice", key)
+		}
+		documented[key] = true
+	}
+
+	var problems []string
+	for _, key := range registered {
+		if !documented[key] {
+			problems = append(problems, "undocumented route: "+key)
+		}
+		delete(documented, key)
+	}
+	for key := range documented {
+		problems = append(problems, "documented route is not registered: "+key)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("OpenAPI spec is out of date:\n  %s", strings.Join(problems, "\n  "))
+}
@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+)
+
+//go:embed docs.html
+var docsHTML string
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsHTML))
+
+// JSONHandler serves the document as JSON; it is encoded once since it never changes
+func JSONHandler(doc *Document) http.Handler {
+	payload, err := json.MarshalIndent(doc, "", "  ")
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if err != nil {
+			http.Error(w, "failed to encode OpenAPI document: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write(payload)
+	})
+}
+
+// DocsHandler serves a self-contained docs UI rendering the document at specURL
+func DocsHandler(title string, specURL string) http.Handler {
+	var page bytes.Buffer
+	err := docsTemplate.Execute(&page, struct{ Title, SpecURL string }{title, specURL})
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if err != nil {
+			http.Error(w, "failed to render docs: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(page.Bytes())
+	})
+}
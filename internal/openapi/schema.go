@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+)
+
+// ulidPattern matches the 26 character Crockford base32 IDs used for every entity
+const ulidPattern = "^[0-9A-HJKMNP-TV-Z]{26}$"
+
+// slugPattern matches the "slug" validation rule
+const slugPattern = "^[a-z0-9]+(?:-[a-z0-9]+)*$"
+
+// Schemas generates schemas from Go types, collecting named structs as components
+type Schemas struct {
+	components map[string]*Schema
+	overrides  map[reflect.Type]*Schema
+}
+
+// NewSchemas creates a schema generator with the standard library overrides registered
+func NewSchemas() *Schemas {
+	s := &Schemas{
+		components: make(map[string]*Schema),
+		overrides:  make(map[reflect.Type]*Schema),
+	}
+	s.Override(time.Time{}, &Schema{Type: "string", Format: "date-time"})
+	s.Override(multipart.FileHeader{}, &Schema{Type: "string", Format: "binary"})
+	return s
+}
+
+// Override uses schema for the type of v instead of reflecting on it, for types
+// with custom JSON marshalling
+func (s *Schemas) Override(v interface{}, schema *Schema) {
+	s.overrides[reflect.TypeOf(v)] = schema
+}
+
+// Components returns the named schemas generated so far
+func (s *Schemas) Components() map[string]*Schema {
+	return s.components
+}
+
+// JSON returns the schema of v as seen by API clients: keys are camelCase, matching
+// the case converter applied to every JSON request and response
+func (s *Schemas) JSON(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return s.schemaOf(reflect.TypeOf(v), "json")
+}
+
+// MergePatch returns the schema of a JSON merge patch of the struct v: every
+// member is optional and null clears it
+func (s *Schemas) MergePatch(v interface{}) *Schema {
+	schema := s.structSchema(reflect.TypeOf(v), "json")
+	schema.Required = nil
+	for _, property := range schema.Properties {
+		property.Nullable = true
+	}
+	return schema
+}
+
+// Form returns the schema of a struct bound from multipart form fields
+func (s *Schemas) Form(v interface{}) *Schema {
+	return s.schemaOf(reflect.TypeOf(v), "form")
+}
+
+// schemaOf reflects on t; tagKey selects the struct tag naming fields
+func (s *Schemas) schemaOf(t reflect.Type, tagKey string) *Schema {
+	if override, ok := s.overrides[t]; ok {
+		copied := *override
+		return &copied
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.schemaOf(t.Elem(), tagKey)
+		if schema.Ref != "" || tagKey == "form" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schemaOf(t.Elem(), tagKey)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem(), tagKey)}
+	case reflect.Struct:
+		if t.Name() == "" || tagKey != "json" {
+			return s.structSchema(t, tagKey)
+		}
+		// Named structs become components referenced by name
+		name := strcase.ToCamel(t.Name())
+		if _, ok := s.components[name]; !ok {
+			s.components[name] = &Schema{}
+			*s.components[name] = *s.structSchema(t, tagKey)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// interface{} and anything else accepts any value
+		return &Schema{}
+	}
+}
+
+// structSchema builds an object schema from the exported fields of t
+func (s *Schemas) structSchema(t reflect.Type, tagKey string) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.addFields(schema, t, tagKey)
+	if len(schema.Properties) == 0 {
+		schema.Properties = nil
+	}
+	return schema
+}
+
+// addFields adds the fields of t to schema, flattening embedded structs as encoding/json does
+func (s *Schemas) addFields(schema *Schema, t reflect.Type, tagKey string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get(tagKey)
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.addFields(schema, field.Type, tagKey)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if tagKey == "json" {
+			name = strcase.ToLowerCamel(name)
+		}
+
+		property := s.schemaOf(field.Type, tagKey)
+		required := applyValidation(property, field.Tag.Get("validate"))
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// applyValidation maps go-playground validator rules onto schema constraints and
+// reports whether the field is required. Rules after "dive" apply to array items.
+func applyValidation(schema *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	required := false
+	target := schema
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+			continue
+		case "required":
+			if target == schema {
+				required = true
+			}
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "ulid":
+			target.Pattern = ulidPattern
+		case "slug":
+			target.Pattern = slugPattern
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				target.Enum = append(target.Enum, value)
+			}
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyBound(target, name, n)
+		}
+	}
+	return required
+}
+
+// applyBound sets the length, item count or value bound matching the schema's type
+func applyBound(schema *Schema, rule string, n int) {
+	lower, upper := rule == "min" || rule == "len", rule == "max" || rule == "len"
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = &n
+		}
+		if upper {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if lower {
+			schema.MinItems = &n
+		}
+		if upper {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		f := float64(n)
+		if lower {
+			schema.Minimum = &f
+		}
+		if upper {
+			schema.Maximum = &f
+		}
+	}
+}
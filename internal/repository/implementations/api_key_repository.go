@@ -0,0 +1,93 @@
+package implementations
+
+import (
+	"context"
+	"time"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+)
+
+// ApiKeyRepository implements the ApiKeyRepository interface
+type ApiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewApiKeyRepository creates a new instance of ApiKeyRepository
+func NewApiKeyRepository(db *gorm.DB) interfaces.ApiKeyRepository {
+	return &ApiKeyRepository{
+		db: db,
+	}
+}
+
+// GetAllApiKeys retrieves every API key with its scopes, newest first
+func (r *ApiKeyRepository) GetAllApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyRepository.GetAllApiKeys")
+	defer span.End()
+
+	var keys []models.ApiKey
+	result := r.db.WithContext(ctx).Preload("Scopes").Order("created_at desc").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+// FindApiKey finds an API key by ID
+func (r *ApiKeyRepository) FindApiKey(ctx context.Context, id string) (models.ApiKey, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyRepository.FindApiKey")
+	defer span.End()
+
+	var key models.ApiKey
+	result := r.db.WithContext(ctx).Preload("Scopes").Where("id = ?", id).First(&key)
+	if result.Error != nil {
+		return models.ApiKey{}, result.Error
+	}
+	return key, nil
+}
+
+// FindApiKeyByPrefix finds an API key by its public prefix
+func (r *ApiKeyRepository) FindApiKeyByPrefix(ctx context.Context, prefix string) (models.ApiKey, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyRepository.FindApiKeyByPrefix")
+	defer span.End()
+
+	var key models.ApiKey
+	result := r.db.WithContext(ctx).Preload("Scopes").Where("prefix = ?", prefix).First(&key)
+	if result.Error != nil {
+		return models.ApiKey{}, result.Error
+	}
+	return key, nil
+}
+
+// CreateApiKey creates an API key together with its scopes
+func (r *ApiKeyRepository) CreateApiKey(ctx context.Context, key models.ApiKey) (models.ApiKey, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyRepository.CreateApiKey")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(&key).Error; err != nil {
+		return models.ApiKey{}, err
+	}
+	return key, nil
+}
+
+// RevokeApiKey revokes an API key
+func (r *ApiKeyRepository) RevokeApiKey(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "ApiKeyRepository.RevokeApiKey")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Model(&models.ApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// TouchApiKey records a use of an API key, skipping the write when it was recorded after staleBefore
+func (r *ApiKeyRepository) TouchApiKey(ctx context.Context, id string, usedAt time.Time, staleBefore time.Time) error {
+	ctx, span := tracing.Start(ctx, "ApiKeyRepository.TouchApiKey")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Model(&models.ApiKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, staleBefore).
+		UpdateColumn("last_used_at", usedAt).Error
+}
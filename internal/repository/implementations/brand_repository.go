@@ -7,9 +7,13 @@ import (
 	"beautyessentials.com/internal/constant"
 	"beautyessentials.com/internal/models"
 	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
 	"gorm.io/gorm"
 )
 
+// brandColumns are the columns sparse fieldsets of brands select from
+var brandColumns = []string{"name", "slug", "status", "version", "created_at", "updated_at", "deleted_at"}
+
 // BrandRepository implements the BrandRepository interface
 type BrandRepository struct {
 	db       *gorm.DB
@@ -26,6 +30,9 @@ func NewBrandRepository(db *gorm.DB) interfaces.BrandRepository {
 
 // GetAllBrands retrieves all brands from the database with filtering and pagination
 func (r *BrandRepository) GetAllBrands(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "BrandRepository.GetAllBrands")
+	defer span.End()
+
 	var brands []models.Brand
 
 	// Start with base query
@@ -78,7 +85,7 @@ func (r *BrandRepository) GetAllBrands(ctx context.Context, filters map[string]i
 
 		offset := (page - 1) * perPage
 
-		result := query.Limit(perPage).Offset(offset).Find(&brands)
+		result := selectFields(query, appends, "brands", brandColumns).Limit(perPage).Offset(offset).Find(&brands)
 		if result.Error != nil {
 			return nil, result.Error
 		}
@@ -94,7 +101,7 @@ func (r *BrandRepository) GetAllBrands(ctx context.Context, filters map[string]i
 	}
 
 	// Return all results without pagination
-	result := query.Find(&brands)
+	result := selectFields(query, appends, "brands", brandColumns).Find(&brands)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -103,17 +110,27 @@ func (r *BrandRepository) GetAllBrands(ctx context.Context, filters map[string]i
 }
 
 // FindBrand finds a brand by ID
-func (r *BrandRepository) FindBrand(ctx context.Context, id string) (models.Brand, error) {
+func (r *BrandRepository) FindBrand(ctx context.Context, id string, appends map[string]interface{}) (models.Brand, error) {
+	ctx, span := tracing.Start(ctx, "BrandRepository.FindBrand")
+	defer span.End()
+
+	if err := checkID(id, "brand"); err != nil {
+		return models.Brand{}, err
+	}
+
 	var brand models.Brand
-	result := r.db.WithContext(ctx).Where("id = ?", id).First(&brand)
+	result := selectFields(r.db.WithContext(ctx), appends, "brands", brandColumns).Where("id = ?", id).First(&brand)
 	if result.Error != nil {
-		return models.Brand{}, result.Error
+		return models.Brand{}, translateError(result.Error, "brand")
 	}
 	return brand, nil
 }
 
 // CreateBrand creates a new brand
 func (r *BrandRepository) CreateBrand(ctx context.Context, data map[string]interface{}) (models.Brand, error) {
+	ctx, span := tracing.Start(ctx, "BrandRepository.CreateBrand")
+	defer span.End()
+
 	// Create a new brand instance
 	brand := models.Brand{
 		Name: data["name"].(string),
@@ -142,7 +159,7 @@ func (r *BrandRepository) CreateBrand(ctx context.Context, data map[string]inter
 	// Create the brand within the transaction
 	if err := tx.Create(&brand).Error; err != nil {
 		tx.Rollback()
-		return models.Brand{}, err
+		return models.Brand{}, translateError(err, "brand")
 	}
 
 	// Commit the transaction
@@ -154,10 +171,13 @@ func (r *BrandRepository) CreateBrand(ctx context.Context, data map[string]inter
 	return brand, nil
 }
 
-// UpdateBrand updates an existing brand
-func (r *BrandRepository) UpdateBrand(ctx context.Context, data map[string]interface{}, id string) (models.Brand, error) {
+// UpdateBrand updates an existing brand, provided it is still at version when that is positive
+func (r *BrandRepository) UpdateBrand(ctx context.Context, data map[string]interface{}, id string, version int64) (models.Brand, error) {
+	ctx, span := tracing.Start(ctx, "BrandRepository.UpdateBrand")
+	defer span.End()
+
 	// Find the brand first
-	brand, err := r.FindBrand(ctx, id)
+	brand, err := r.FindBrand(ctx, id, nil)
 	if err != nil {
 		return models.Brand{}, err
 	}
@@ -176,9 +196,9 @@ func (r *BrandRepository) UpdateBrand(ctx context.Context, data map[string]inter
 	}()
 
 	// Update the brand within the transaction
-	if err := tx.Model(&brand).Updates(data).Error; err != nil {
+	if err := versionedUpdate(tx.Model(&brand), data, version); err != nil {
 		tx.Rollback()
-		return models.Brand{}, err
+		return models.Brand{}, translateError(err, "brand")
 	}
 
 	// Commit the transaction
@@ -187,13 +207,16 @@ func (r *BrandRepository) UpdateBrand(ctx context.Context, data map[string]inter
 	}
 
 	// Refresh the brand data
-	return r.FindBrand(ctx, id)
+	return r.FindBrand(ctx, id, nil)
 }
 
 // DeleteBrand soft deletes a brand
 func (r *BrandRepository) DeleteBrand(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "BrandRepository.DeleteBrand")
+	defer span.End()
+
 	// Find the brand first
-	brand, err := r.FindBrand(ctx, id)
+	brand, err := r.FindBrand(ctx, id, nil)
 	if err != nil {
 		return err
 	}
@@ -214,7 +237,7 @@ func (r *BrandRepository) DeleteBrand(ctx context.Context, id string) error {
 	// Use Delete for soft delete since we're using gorm.DeletedAt
 	if err := tx.Delete(&brand).Error; err != nil {
 		tx.Rollback()
-		return err
+		return translateError(err, "brand")
 	}
 
 	// Commit the transaction
@@ -223,6 +246,9 @@ func (r *BrandRepository) DeleteBrand(ctx context.Context, id string) error {
 
 // GetActiveBrands retrieves all active brands
 func (r *BrandRepository) GetActiveBrands(ctx context.Context) ([]models.Brand, error) {
+	ctx, span := tracing.Start(ctx, "BrandRepository.GetActiveBrands")
+	defer span.End()
+
 	var brands []models.Brand
 	result := r.db.WithContext(ctx).Where("status = ?", constant.StatusActive).Find(&brands)
 	if result.Error != nil {
@@ -232,14 +258,20 @@ func (r *BrandRepository) GetActiveBrands(ctx context.Context) ([]models.Brand,
 }
 
 // GetGroupedBrands retrieves active brands grouped by first letter
-func (r *BrandRepository) GetGroupedBrands(ctx context.Context) (map[string][]models.Brand, error) {
+func (r *BrandRepository) GetGroupedBrands(ctx context.Context, appends map[string]interface{}) (map[string][]models.Brand, error) {
+	ctx, span := tracing.Start(ctx, "BrandRepository.GetGroupedBrands")
+	defer span.End()
+
 	var brands []models.Brand
 
-	// Select only needed fields
-	result := r.db.WithContext(ctx).
-		Where("status = ?", constant.StatusActive).
-		Select("id, name, slug").
-		Find(&brands)
+	// Select only needed fields; the name is always needed for grouping
+	query := r.db.WithContext(ctx).Where("status = ?", constant.StatusActive)
+	if fieldsetOf(appends).FieldsOf("brands") == nil {
+		query = query.Select("id, name, slug")
+	} else {
+		query = selectFields(query, appends, "brands", brandColumns, "name")
+	}
+	result := query.Find(&brands)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -256,3 +288,11 @@ func (r *BrandRepository) GetGroupedBrands(ctx context.Context) (map[string][]mo
 
 	return groupedBrands, nil
 }
+
+// GetVersion summarises the brands table so unchanged listings can be served from caches
+func (r *BrandRepository) GetVersion(ctx context.Context) (models.TableVersion, error) {
+	ctx, span := tracing.Start(ctx, "BrandRepository.GetVersion")
+	defer span.End()
+
+	return tableVersion(ctx, r.db, &models.Brand{})
+}
@@ -6,9 +6,13 @@ import (
 	"beautyessentials.com/internal/constant"
 	"beautyessentials.com/internal/models"
 	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
 	"gorm.io/gorm"
 )
 
+// categoryColumns are the columns sparse fieldsets of categories select from
+var categoryColumns = []string{"name", "slug", "status", "description", "version", "created_at", "updated_at", "deleted_at"}
+
 // CategoryRepository implements the CategoryRepository interface
 type CategoryRepository struct {
 	db       *gorm.DB
@@ -25,6 +29,9 @@ func NewCategoryRepository(db *gorm.DB) interfaces.CategoryRepository {
 
 // GetAllCategories retrieves all categories from the database with filtering and pagination
 func (r *CategoryRepository) GetAllCategories(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "CategoryRepository.GetAllCategories")
+	defer span.End()
+
 	var categories []models.Category
 
 	// Start with base query
@@ -77,10 +84,13 @@ func (r *CategoryRepository) GetAllCategories(ctx context.Context, filters map[s
 
 		offset := (page - 1) * perPage
 
-		result := query.Limit(perPage).Offset(offset).Find(&categories)
+		result := selectFields(query, appends, "categories", categoryColumns).Limit(perPage).Offset(offset).Find(&categories)
 		if result.Error != nil {
 			return nil, result.Error
 		}
+		if err := r.attachMedia(ctx, categories, appends); err != nil {
+			return nil, err
+		}
 
 		// Return paginated result
 		return map[string]interface{}{
@@ -93,30 +103,51 @@ func (r *CategoryRepository) GetAllCategories(ctx context.Context, filters map[s
 	}
 
 	// Return all results without pagination
-	result := query.Find(&categories)
+	result := selectFields(query, appends, "categories", categoryColumns).Find(&categories)
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	if err := r.attachMedia(ctx, categories, appends); err != nil {
+		return nil, err
+	}
 
 	return categories, nil
 }
 
 // FindCategory finds a category by ID
-func (r *CategoryRepository) FindCategory(ctx context.Context, id string) (models.Category, error) {
+func (r *CategoryRepository) FindCategory(ctx context.Context, id string, appends map[string]interface{}) (models.Category, error) {
+	ctx, span := tracing.Start(ctx, "CategoryRepository.FindCategory")
+	defer span.End()
+
+	if err := checkID(id, "category"); err != nil {
+		return models.Category{}, err
+	}
+
 	var category models.Category
-	result := r.db.WithContext(ctx).Where("id = ?", id).First(&category)
+	result := selectFields(r.db.WithContext(ctx), appends, "categories", categoryColumns).Where("id = ?", id).First(&category)
 	if result.Error != nil {
-		return models.Category{}, result.Error
+		return models.Category{}, translateError(result.Error, "category")
 	}
-	return category, nil
+
+	categories := []models.Category{category}
+	if err := r.attachMedia(ctx, categories, appends); err != nil {
+		return models.Category{}, err
+	}
+	return categories[0], nil
 }
 
 // CreateCategory creates a new category
 func (r *CategoryRepository) CreateCategory(ctx context.Context, data map[string]interface{}) (models.Category, error) {
+	ctx, span := tracing.Start(ctx, "CategoryRepository.CreateCategory")
+	defer span.End()
+
 	// Create a new category instance
 	category := models.Category{
 		Name:        data["name"].(string),
 	}
+	if description, ok := data["description"].(string); ok {
+		category.Description = description
+	}
 
 	// If status is provided, set it
 	if status, ok := data["status"].(string); ok {
@@ -141,15 +172,15 @@ func (r *CategoryRepository) CreateCategory(ctx context.Context, data map[string
 	// Create the category within the transaction
 	if err := tx.Create(&category).Error; err != nil {
 		tx.Rollback()
-		return models.Category{}, err
+		return models.Category{}, translateError(err, "category")
 	}
 
 	// Handle media attachment if provided
 	if mediaID, ok := data["media_id"].(string); ok && mediaID != "" {
 		if err := tx.Exec("INSERT INTO mediables (id, mediable_id, mediable_type, media_id) VALUES (?, ?, ?, ?)",
-			data["mediable_id"], category.ID, "App\\Model\\Category", mediaID).Error; err != nil {
+			data["mediable_id"], category.ID, constant.MediableTypeCategory, mediaID).Error; err != nil {
 			tx.Rollback()
-			return models.Category{}, err
+			return models.Category{}, translateError(err, "category")
 		}
 	}
 
@@ -162,10 +193,13 @@ func (r *CategoryRepository) CreateCategory(ctx context.Context, data map[string
 	return category, nil
 }
 
-// UpdateCategory updates an existing category
-func (r *CategoryRepository) UpdateCategory(ctx context.Context, data map[string]interface{}, id string) (models.Category, error) {
+// UpdateCategory updates an existing category, provided it is still at version when that is positive
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, data map[string]interface{}, id string, version int64) (models.Category, error) {
+	ctx, span := tracing.Start(ctx, "CategoryRepository.UpdateCategory")
+	defer span.End()
+
 	// Find the category first
-	category, err := r.FindCategory(ctx, id)
+	category, err := r.FindCategory(ctx, id, nil)
 	if err != nil {
 		return models.Category{}, err
 	}
@@ -183,26 +217,28 @@ func (r *CategoryRepository) UpdateCategory(ctx context.Context, data map[string
 		}
 	}()
 
-	// Update the category within the transaction
-	if err := tx.Model(&category).Updates(data).Error; err != nil {
+	// Update the category within the transaction; the media link is synced below
+	if err := versionedUpdate(tx.Model(&category), categoryUpdates(data), version); err != nil {
 		tx.Rollback()
-		return models.Category{}, err
+		return models.Category{}, translateError(err, "category")
 	}
 
-	// Handle media sync if provided
-	if mediaID, ok := data["media_id"].(string); ok && mediaID != "" {
+	// Handle media sync if provided; an empty media ID detaches the media
+	if mediaID, ok := data["media_id"].(string); ok {
 		// First delete existing media relationships
 		if err := tx.Exec("DELETE FROM mediables WHERE mediable_id = ? AND mediable_type = ?",
-			category.ID, "App\\Model\\Category").Error; err != nil {
+			category.ID, constant.MediableTypeCategory).Error; err != nil {
 			tx.Rollback()
 			return models.Category{}, err
 		}
 
 		// Then add the new one
-		if err := tx.Exec("INSERT INTO mediables (id, mediable_id, mediable_type, media_id) VALUES (?, ?, ?, ?)",
-			data["mediable_id"], category.ID, "App\\Model\\Category", mediaID).Error; err != nil {
-			tx.Rollback()
-			return models.Category{}, err
+		if mediaID != "" {
+			if err := tx.Exec("INSERT INTO mediables (id, mediable_id, mediable_type, media_id) VALUES (?, ?, ?, ?)",
+				data["mediable_id"], category.ID, constant.MediableTypeCategory, mediaID).Error; err != nil {
+				tx.Rollback()
+				return models.Category{}, translateError(err, "category")
+			}
 		}
 	}
 
@@ -212,13 +248,16 @@ func (r *CategoryRepository) UpdateCategory(ctx context.Context, data map[string
 	}
 
 	// Refresh the category data
-	return r.FindCategory(ctx, id)
+	return r.FindCategory(ctx, id, nil)
 }
 
 // DeleteCategory soft deletes a category
 func (r *CategoryRepository) DeleteCategory(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "CategoryRepository.DeleteCategory")
+	defer span.End()
+
 	// Find the category first
-	category, err := r.FindCategory(ctx, id)
+	category, err := r.FindCategory(ctx, id, nil)
 	if err != nil {
 		return err
 	}
@@ -239,7 +278,7 @@ func (r *CategoryRepository) DeleteCategory(ctx context.Context, id string) erro
 	// Delete the category within the transaction
 	if err := tx.Delete(&category).Error; err != nil {
 		tx.Rollback()
-		return err
+		return translateError(err, "category")
 	}
 
 	// Commit the transaction
@@ -247,21 +286,117 @@ func (r *CategoryRepository) DeleteCategory(ctx context.Context, id string) erro
 }
 
 // GetActiveCategories retrieves all active categories
-func (r *CategoryRepository) GetActiveCategories(ctx context.Context) ([]models.Category, error) {
+func (r *CategoryRepository) GetActiveCategories(ctx context.Context, appends map[string]interface{}) ([]models.Category, error) {
+	ctx, span := tracing.Start(ctx, "CategoryRepository.GetActiveCategories")
+	defer span.End()
+
 	var categories []models.Category
-	result := r.db.WithContext(ctx).Where("status = ?", constant.StatusActive).Find(&categories)
+	result := selectFields(r.db.WithContext(ctx), appends, "categories", categoryColumns).Where("status = ?", constant.StatusActive).Find(&categories)
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	if err := r.attachMedia(ctx, categories, appends); err != nil {
+		return nil, err
+	}
 	return categories, nil
 }
 
 // FindCategoryBySlug finds categories by slug
-func (r *CategoryRepository) FindCategoryBySlug(ctx context.Context, slug string) ([]models.Category, error) {
+func (r *CategoryRepository) FindCategoryBySlug(ctx context.Context, slug string, appends map[string]interface{}) ([]models.Category, error) {
+	ctx, span := tracing.Start(ctx, "CategoryRepository.FindCategoryBySlug")
+	defer span.End()
+
 	var categories []models.Category
-	result := r.db.WithContext(ctx).Where("slug = ?", slug).Find(&categories)
+	result := selectFields(r.db.WithContext(ctx), appends, "categories", categoryColumns).Where("slug = ?", slug).Find(&categories)
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	if err := r.attachMedia(ctx, categories, appends); err != nil {
+		return nil, err
+	}
 	return categories, nil
 }
+
+// GetVersion summarises the categories table, their media and the tags of those media so unchanged listings
+// can be served from caches
+func (r *CategoryRepository) GetVersion(ctx context.Context) (models.TableVersion, error) {
+	ctx, span := tracing.Start(ctx, "CategoryRepository.GetVersion")
+	defer span.End()
+
+	version, err := tableVersion(ctx, r.db, &models.Category{})
+	if err != nil {
+		return models.TableVersion{}, err
+	}
+	// Listings embed the media, which change without touching the categories
+	media, err := mediaVersion(ctx, r.db, constant.MediableTypeCategory)
+	if err != nil {
+		return models.TableVersion{}, err
+	}
+	// and the media embed their tags
+	tags, err := mediaTagVersion(ctx, r.db, constant.MediableTypeCategory)
+	if err != nil {
+		return models.TableVersion{}, err
+	}
+	return version.Include(media, tags), nil
+}
+
+// categoryUpdates returns the columns of categories set by data, leaving out the
+// media link, which lives in mediables
+func categoryUpdates(data map[string]interface{}) map[string]interface{} {
+	updates := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if key != "media_id" && key != "mediable_id" {
+			updates[key] = value
+		}
+	}
+	return updates
+}
+
+// attachMedia loads the media attached to categories with one query per relation
+// for the whole batch. Media IDs are loaded unless the fieldset leaves them out;
+// the media themselves only when included.
+func (r *CategoryRepository) attachMedia(ctx context.Context, categories []models.Category, appends map[string]interface{}) error {
+	withMedia := fieldsetOf(appends).Includes("media")
+	if len(categories) == 0 || (!withMedia && !wantsField(appends, "categories", "media_id")) {
+		return nil
+	}
+
+	ids := make([]string, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+	var mediables []models.Mediable
+	if err := r.db.WithContext(ctx).
+		Where("mediable_type = ? AND mediable_id IN ?", constant.MediableTypeCategory, ids).
+		Find(&mediables).Error; err != nil {
+		return err
+	}
+
+	mediaIDs := make(map[string]string, len(mediables))
+	for _, mediable := range mediables {
+		mediaIDs[mediable.MediableID] = mediable.MediaID
+	}
+
+	media := make(map[string]models.Media)
+	if withMedia && len(mediaIDs) > 0 {
+		ids := make([]string, 0, len(mediaIDs))
+		for _, mediaID := range mediaIDs {
+			ids = append(ids, mediaID)
+		}
+		var found []models.Media
+		if err := r.db.WithContext(ctx).Preload("Tags").Where("id IN ?", ids).Find(&found).Error; err != nil {
+			return err
+		}
+		for _, item := range found {
+			media[item.ID] = item
+		}
+	}
+
+	for i := range categories {
+		categories[i].MediaID = mediaIDs[categories[i].ID]
+		if item, ok := media[categories[i].MediaID]; ok {
+			categories[i].Media = &item
+		}
+	}
+	return nil
+}
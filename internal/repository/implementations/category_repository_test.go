@@ -0,0 +1,126 @@
+package implementations
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"beautyessentials.com/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a Postgres gorm handle that builds statements without a server
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db
+}
+
+func TestCategoryUpdatesLeaveOutMediaLink(t *testing.T) {
+	data := map[string]interface{}{
+		"name":        "Skincare",
+		"description": "",
+		"status":      "active",
+		"media_id":    "",
+		"mediable_id": "01J0000000000000000000000M",
+	}
+
+	category := models.Category{ID: "01J0000000000000000000000C"}
+	stmt := dryRunDB(t).Model(&category).Updates(categoryUpdates(data)).Statement
+	if stmt.Error != nil {
+		t.Fatalf("Updates() error = %v", stmt.Error)
+	}
+	sql := stmt.SQL.String()
+
+	for _, column := range []string{`"media_id"`, `"mediable_id"`} {
+		if strings.Contains(sql, column) {
+			t.Errorf("UPDATE sets %s, which categories has no column for: %s", column, sql)
+		}
+	}
+	for _, column := range []string{`"name"`, `"description"`, `"status"`} {
+		if !strings.Contains(sql, column) {
+			t.Errorf("UPDATE does not set %s: %s", column, sql)
+		}
+	}
+	if _, ok := data["media_id"]; !ok {
+		t.Error("categoryUpdates() removed media_id from the caller's data, which the media sync still needs")
+	}
+}
+
+func TestCategoryVersionIncludesMedia(t *testing.T) {
+	categoriesChanged := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	mediaChanged := categoriesChanged.Add(time.Hour)
+	tagged := mediaChanged.Add(time.Hour)
+
+	db, fake := newFakeDB(t, func(query string, args []driver.NamedValue) fakeResult {
+		switch {
+		case strings.Contains(query, "FROM \"categories\""):
+			return fakeResult{
+				Columns: []string{"count", "updated_at", "deleted_at"},
+				Rows:    [][]driver.Value{{int64(3), categoriesChanged, nil}},
+			}
+		case strings.Contains(query, "FROM \"media_tag\""):
+			return fakeResult{
+				Columns: []string{"count", "created_at", "updated_at"},
+				Rows:    [][]driver.Value{{int64(4), tagged, categoriesChanged}},
+			}
+		case strings.Contains(query, "FROM \"mediables\""):
+			return fakeResult{
+				Columns: []string{"count", "updated_at"},
+				Rows:    [][]driver.Value{{int64(2), mediaChanged}},
+			}
+		}
+		return fakeResult{Err: fmt.Errorf("unexpected query %s", query)}
+	})
+
+	version, err := NewCategoryRepository(db).GetVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetVersion() error = %v", err)
+	}
+	if version.Count != 3 || !version.LastModified.Equal(categoriesChanged) {
+		t.Errorf("version = %d at %v, want the 3 categories changed at %v", version.Count, version.LastModified, categoriesChanged)
+	}
+	want := []models.TableVersion{
+		{Count: 2, LastModified: mediaChanged},
+		{Count: 4, LastModified: tagged},
+	}
+	if len(version.Related) != len(want) {
+		t.Fatalf("Related = %+v, want the media and their tags %+v", version.Related, want)
+	}
+	for i, related := range version.Related {
+		if related.Count != want[i].Count || !related.LastModified.Equal(want[i].LastModified) {
+			t.Errorf("Related[%d] = %+v, want %+v", i, related, want[i])
+		}
+	}
+
+	var media, tags string
+	for _, statement := range fake.Statements() {
+		switch {
+		case strings.Contains(statement, "media_tag"):
+			tags = statement
+		case strings.Contains(statement, "mediables"):
+			media = statement
+		}
+	}
+	for _, part := range []string{"MAX(medias.updated_at)", "mediables.mediable_type"} {
+		if !strings.Contains(media, part) {
+			t.Errorf("media version query lacks %s: %s", part, media)
+		}
+	}
+	for _, part := range []string{"MAX(media_tag.created_at)", "MAX(media_tags.updated_at)", "mediable_type"} {
+		if !strings.Contains(tags, part) {
+			t.Errorf("media tag version query lacks %s: %s", part, tags)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package implementations
+
+import (
+	"errors"
+	"strings"
+
+	"beautyessentials.com/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// Postgres error codes translated into domain errors
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// translateError turns the errors of queries on resource, e.g. "media_folder", into
+// domain errors: missing records and unique and foreign key violations. Other
+// errors are returned unchanged.
+func translateError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+	code, name := strings.ToUpper(resource), resourceName(resource)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.NotFound(code+"_NOT_FOUND", name+" not found").Wrap(err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return domain.Conflict(code+"_ALREADY_EXISTS", name+" already exists").WithDetail(pgErr.Detail).Wrap(err)
+	case pgForeignKeyViolation:
+		// Deletes fail on records still referenced, inserts and updates on references to missing ones
+		if strings.Contains(pgErr.Detail, "still referenced") {
+			return domain.Conflict(code+"_IN_USE", name+" is still in use").WithDetail(pgErr.Detail).Wrap(err)
+		}
+		return domain.Validation(code+"_INVALID_REFERENCE", name+" references a record that does not exist").WithDetail(pgErr.Detail).Wrap(err)
+	}
+	return err
+}
+
+// checkID rejects IDs that are not ULIDs before they reach the database
+func checkID(id string, resource string) error {
+	if _, err := ulid.ParseStrict(id); err != nil {
+		return domain.Validation("INVALID_"+strings.ToUpper(resource)+"_ID", "Invalid "+strings.ToLower(resourceName(resource))+" ID").WithDetail(id + " is not a ULID").Wrap(err)
+	}
+	return nil
+}
+
+// resourceName returns the name of resource for messages, e.g. "Media folder"
+func resourceName(resource string) string {
+	name := strings.ReplaceAll(resource, "_", " ")
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,36 @@
+package implementations
+
+import (
+	"beautyessentials.com/internal/utils/fieldset"
+	"gorm.io/gorm"
+)
+
+// fieldsetOf returns the sparse fieldsets and includes requested in appends
+func fieldsetOf(appends map[string]interface{}) fieldset.Query {
+	query, _ := appends["fieldset"].(fieldset.Query)
+	return query
+}
+
+// selectFields restricts query to the columns backing the fields requested for
+// resourceType, out of columns; required columns are selected regardless
+func selectFields(query *gorm.DB, appends map[string]interface{}, resourceType string, columns []string, required ...string) *gorm.DB {
+	fields := fieldsetOf(appends).FieldsOf(resourceType)
+	if fields == nil {
+		return query
+	}
+	return query.Select(fieldset.Columns(append(append([]string{}, fields...), required...), columns...))
+}
+
+// wantsField reports whether field of resourceType is part of the response
+func wantsField(appends map[string]interface{}, resourceType string, field string) bool {
+	fields := fieldsetOf(appends).FieldsOf(resourceType)
+	if fields == nil {
+		return true
+	}
+	for _, name := range fields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
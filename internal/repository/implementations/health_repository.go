@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
 )
 
 // HealthRepositoryImpl implements the HealthRepository interface
@@ -16,6 +17,9 @@ func NewHealthRepository() interfaces.HealthRepository {
 
 // CheckHealth checks if the repository layer is healthy
 func (r *HealthRepositoryImpl) CheckHealth(ctx context.Context) (bool, error) {
+	ctx, span := tracing.Start(ctx, "HealthRepositoryImpl.CheckHealth")
+	defer span.End()
+
 	// In a real application, this might check database connectivity
 	return true, nil
 }
\ No newline at end of file
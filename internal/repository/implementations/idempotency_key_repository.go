@@ -0,0 +1,81 @@
+package implementations
+
+import (
+	"context"
+	"time"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKeyRepository implements the IdempotencyKeyRepository interface
+type IdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyKeyRepository creates a new instance of IdempotencyKeyRepository
+func NewIdempotencyKeyRepository(db *gorm.DB) interfaces.IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{
+		db: db,
+	}
+}
+
+// CreateIdempotencyKey stores a key unless its scope already holds it; the unique index
+// decides between concurrent requests so exactly one of them is stored
+func (r *IdempotencyKeyRepository) CreateIdempotencyKey(ctx context.Context, key models.IdempotencyKey) (bool, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyKeyRepository.CreateIdempotencyKey")
+	defer span.End()
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&key)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindIdempotencyKey finds a key within a scope
+func (r *IdempotencyKeyRepository) FindIdempotencyKey(ctx context.Context, scope string, key string) (models.IdempotencyKey, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyKeyRepository.FindIdempotencyKey")
+	defer span.End()
+
+	var record models.IdempotencyKey
+	result := r.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).First(&record)
+	if result.Error != nil {
+		return models.IdempotencyKey{}, result.Error
+	}
+	return record, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request holding a key
+func (r *IdempotencyKeyRepository) CompleteIdempotencyKey(ctx context.Context, scope string, key string, statusCode int, contentType string, body []byte) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyKeyRepository.CompleteIdempotencyKey")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Updates(map[string]interface{}{
+			"status_code":  statusCode,
+			"content_type": contentType,
+			"body":         body,
+		}).Error
+}
+
+// DeleteIdempotencyKey deletes a key so it can be used again
+func (r *IdempotencyKeyRepository) DeleteIdempotencyKey(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyKeyRepository.DeleteIdempotencyKey")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Delete(&models.IdempotencyKey{}, "id = ?", id).Error
+}
+
+// DeleteExpiredIdempotencyKeys deletes keys that expired before now
+func (r *IdempotencyKeyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyKeyRepository.DeleteExpiredIdempotencyKeys")
+	defer span.End()
+
+	result := r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
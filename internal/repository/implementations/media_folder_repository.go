@@ -0,0 +1,114 @@
+package implementations
+
+import (
+	"context"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+)
+
+// MediaFolderRepository implements the MediaFolderRepository interface
+type MediaFolderRepository struct {
+	db *gorm.DB
+}
+
+// NewMediaFolderRepository creates a new instance of MediaFolderRepository
+func NewMediaFolderRepository(db *gorm.DB) interfaces.MediaFolderRepository {
+	return &MediaFolderRepository{
+		db: db,
+	}
+}
+
+// GetAllFolders retrieves folders, optionally restricted to the children of a parent
+func (r *MediaFolderRepository) GetAllFolders(ctx context.Context, filters map[string]interface{}) ([]models.MediaFolder, error) {
+	ctx, span := tracing.Start(ctx, "MediaFolderRepository.GetAllFolders")
+	defer span.End()
+
+	var folders []models.MediaFolder
+
+	// Start with base query
+	query := r.db.WithContext(ctx).Model(&models.MediaFolder{})
+
+	// Apply filters
+	if parentID, ok := filters["parent_id"].(string); ok {
+		if parentID == "" {
+			query = query.Where("parent_id IS NULL")
+		} else {
+			query = query.Where("parent_id = ?", parentID)
+		}
+	}
+
+	result := query.Order("path asc").Find(&folders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return folders, nil
+}
+
+// FindFolder finds a folder by ID
+func (r *MediaFolderRepository) FindFolder(ctx context.Context, id string) (models.MediaFolder, error) {
+	ctx, span := tracing.Start(ctx, "MediaFolderRepository.FindFolder")
+	defer span.End()
+
+	if err := checkID(id, "media_folder"); err != nil {
+		return models.MediaFolder{}, err
+	}
+
+	var folder models.MediaFolder
+	result := r.db.WithContext(ctx).Where("id = ?", id).First(&folder)
+	if result.Error != nil {
+		return models.MediaFolder{}, translateError(result.Error, "media_folder")
+	}
+	return folder, nil
+}
+
+// CreateFolder creates a new folder
+func (r *MediaFolderRepository) CreateFolder(ctx context.Context, data map[string]interface{}) (models.MediaFolder, error) {
+	ctx, span := tracing.Start(ctx, "MediaFolderRepository.CreateFolder")
+	defer span.End()
+
+	// Create a new folder instance
+	folder := models.MediaFolder{
+		Name: data["name"].(string),
+		Path: data["path"].(string),
+	}
+
+	// If parent is provided, set it
+	if parentID, ok := data["parent_id"].(string); ok && parentID != "" {
+		folder.ParentID = &parentID
+	}
+
+	if err := r.db.WithContext(ctx).Create(&folder).Error; err != nil {
+		return models.MediaFolder{}, translateError(err, "media_folder")
+	}
+
+	return folder, nil
+}
+
+// DeleteFolder deletes a folder
+func (r *MediaFolderRepository) DeleteFolder(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "MediaFolderRepository.DeleteFolder")
+	defer span.End()
+
+	return translateError(r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.MediaFolder{}).Error, "media_folder")
+}
+
+// IsFolderEmpty reports whether a folder has neither sub-folders nor media
+func (r *MediaFolderRepository) IsFolderEmpty(ctx context.Context, id string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "MediaFolderRepository.IsFolderEmpty")
+	defer span.End()
+
+	var children int64
+	if err := r.db.WithContext(ctx).Model(&models.MediaFolder{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		return false, err
+	}
+
+	var media int64
+	if err := r.db.WithContext(ctx).Model(&models.Media{}).Where("folder_id = ?", id).Count(&media).Error; err != nil {
+		return false, err
+	}
+
+	return children == 0 && media == 0, nil
+}
@@ -2,12 +2,26 @@ package implementations
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 
+	"beautyessentials.com/internal/domain"
 	"beautyessentials.com/internal/models"
 	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils"
+	"beautyessentials.com/internal/utils/tracing"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// mediaContentHashIndex is the unique index on the content hash of medias
+const mediaContentHashIndex = "idx_medias_content_hash_unique"
+
+// mediaColumns are the columns of medias that sparse fieldsets may select
+var mediaColumns = []string{"file_id", "url", "thumb_url", "folder_id", "created_at", "updated_at"}
+
 // MediaRepository implements the MediaRepository interface
 type MediaRepository struct {
 	db       *gorm.DB
@@ -24,16 +38,48 @@ func NewMediaRepository(db *gorm.DB) interfaces.MediaRepository {
 
 // GetAllMedia retrieves all media from the database with filtering and pagination
 func (r *MediaRepository) GetAllMedia(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.GetAllMedia")
+	defer span.End()
+
 	var media []models.Media
 
-	// Start with base query
+	// Start with base query; variants and srcset are derived from the URL
 	query := r.db.WithContext(ctx).Model(&models.Media{})
+	if wantsField(appends, "media", "variants") || wantsField(appends, "media", "srcset") {
+		query = selectFields(query, appends, "media", mediaColumns, "url")
+	} else {
+		query = selectFields(query, appends, "media", mediaColumns)
+	}
+	if wantsField(appends, "media", "tags") {
+		query = query.Preload("Tags")
+	}
 
 	// Apply filters
 	if search, ok := filters["search"].(string); ok && search != "" {
 		query = query.Where("file_name ILIKE ?", "%"+search+"%")
 	}
 
+	// Filter by folder; an empty folder ID selects the library root
+	if folderID, ok := filters["folder_id"].(string); ok {
+		if folderID == "" {
+			query = query.Where("folder_id IS NULL")
+		} else {
+			query = query.Where("folder_id = ?", folderID)
+		}
+	}
+
+	// Filter by tags; media must carry every requested tag
+	if tags, ok := filters["tags"].([]string); ok && len(tags) > 0 {
+		slugs := slugify(tags)
+		tagged := r.db.Table("media_tag").
+			Select("media_tag.media_id").
+			Joins("JOIN media_tags ON media_tags.id = media_tag.tag_id").
+			Where("media_tags.slug IN ?", slugs).
+			Group("media_tag.media_id").
+			Having("COUNT(DISTINCT media_tags.id) = ?", len(slugs))
+		query = query.Where("id IN (?)", tagged)
+	}
+
 	// Handle trashed (soft deleted) records
 	if trashed, ok := filters["trashed"].(bool); ok && trashed {
 		query = query.Unscoped().Where("deleted_at IS NOT NULL")
@@ -102,16 +148,27 @@ func (r *MediaRepository) GetAllMedia(ctx context.Context, filters map[string]in
 
 // FindMedia finds a media by ID
 func (r *MediaRepository) FindMedia(ctx context.Context, id string) (models.Media, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.FindMedia")
+	defer span.End()
+
+	if err := checkID(id, "media"); err != nil {
+		return models.Media{}, err
+	}
+
 	var media models.Media
-	result := r.db.WithContext(ctx).Where("id = ?", id).First(&media)
+	result := r.db.WithContext(ctx).Preload("Tags").Where("id = ?", id).First(&media)
 	if result.Error != nil {
-		return models.Media{}, result.Error
+		return models.Media{}, translateError(result.Error, "media")
 	}
 	return media, nil
 }
 
-// CreateMedia creates a new media
+// CreateMedia creates a new media, returning ErrDuplicateContent when one with
+// the same content hash exists
 func (r *MediaRepository) CreateMedia(ctx context.Context, data map[string]interface{}) (models.Media, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.CreateMedia")
+	defer span.End()
+
 	// Create a new media instance
 	media := models.Media{
 		FileID: data["file_id"].(string),
@@ -119,6 +176,19 @@ func (r *MediaRepository) CreateMedia(ctx context.Context, data map[string]inter
 		ThumbURL: data["thumb_url"].(string),
 	}
 
+	// If folder is provided, set it
+	if folderID, ok := data["folder_id"].(string); ok && folderID != "" {
+		media.FolderID = &folderID
+	}
+
+	// If content fingerprints are provided, set them
+	if hash, ok := data["content_hash"].(string); ok {
+		media.ContentHash = hash
+	}
+	if hash, ok := data["perceptual_hash"].(string); ok {
+		media.PerceptualHash = hash
+	}
+
 	// Start a transaction
 	tx := r.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -135,7 +205,11 @@ func (r *MediaRepository) CreateMedia(ctx context.Context, data map[string]inter
 	// Create the media within the transaction
 	if err := tx.Create(&media).Error; err != nil {
 		tx.Rollback()
-		return models.Media{}, err
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == mediaContentHashIndex {
+			return models.Media{}, interfaces.ErrDuplicateContent
+		}
+		return models.Media{}, translateError(err, "media")
 	}
 
 	// Commit the transaction
@@ -148,39 +222,51 @@ func (r *MediaRepository) CreateMedia(ctx context.Context, data map[string]inter
 }
 
 
-// DeleteMedia soft deletes a media
-func (r *MediaRepository) DeleteMedia(ctx context.Context, id string) error {
-	// Find the media first
-	media, err := r.FindMedia(ctx, id)
-	if err != nil {
-		return err
-	}
+// DeleteMedia deletes a media and returns it along with its attachments. Its row
+// is locked while the attachments are checked, so none can be added in between;
+// attachments are removed with it when force is set, otherwise ErrMediaInUse is
+// returned along with them.
+func (r *MediaRepository) DeleteMedia(ctx context.Context, id string, force bool) (models.Media, []models.Mediable, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.DeleteMedia")
+	defer span.End()
 
-	// Start a transaction
-	tx := r.db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		return tx.Error
+	if err := checkID(id, "media"); err != nil {
+		return models.Media{}, nil, err
 	}
 
-	// Defer a rollback in case anything fails
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	var media models.Media
+	var usages []models.Mediable
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&media).Error; err != nil {
+			return translateError(err, "media")
 		}
-	}()
 
-	// Delete the media within the transaction
-	if err := tx.Delete(&media).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		if err := tx.Where("media_id = ?", id).Find(&usages).Error; err != nil {
+			return err
+		}
+		if len(usages) > 0 {
+			if !force {
+				return interfaces.ErrMediaInUse
+			}
+			// Detach the media from every entity using it
+			if err := tx.Where("media_id = ?", id).Delete(&models.Mediable{}).Error; err != nil {
+				return err
+			}
+		}
 
-	// Commit the transaction
-	return tx.Commit().Error
+		return translateError(tx.Delete(&media).Error, "media")
+	})
+	if err != nil {
+		return models.Media{}, usages, err
+	}
+	return media, usages, nil
 }
 
 // FindMediaByFileID finds a media by file ID
 func (r *MediaRepository) FindMediaByFileID(ctx context.Context, fileID string) (models.Media, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.FindMediaByFileID")
+	defer span.End()
+
 	var media models.Media
 	result := r.db.WithContext(ctx).Where("file_id = ?", fileID).First(&media)
 	if result.Error != nil {
@@ -188,3 +274,206 @@ func (r *MediaRepository) FindMediaByFileID(ctx context.Context, fileID string)
 	}
 	return media, nil
 }
+
+// GetMediaUsages retrieves every attachment of a media to another entity
+func (r *MediaRepository) GetMediaUsages(ctx context.Context, id string) ([]models.Mediable, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.GetMediaUsages")
+	defer span.End()
+
+	var usages []models.Mediable
+	result := r.db.WithContext(ctx).Where("media_id = ?", id).Find(&usages)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return usages, nil
+}
+
+// FindMediaByContentHash finds a media by the SHA-256 of its content
+func (r *MediaRepository) FindMediaByContentHash(ctx context.Context, hash string) (models.Media, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.FindMediaByContentHash")
+	defer span.End()
+
+	var media models.Media
+	result := r.db.WithContext(ctx).Preload("Tags").Where("content_hash = ?", hash).First(&media)
+	if result.Error != nil {
+		return models.Media{}, result.Error
+	}
+	return media, nil
+}
+
+// GetPerceptualHashCounts counts the media sharing each perceptual hash
+func (r *MediaRepository) GetPerceptualHashCounts(ctx context.Context) (map[string]int64, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.GetPerceptualHashCounts")
+	defer span.End()
+
+	var rows []struct {
+		PerceptualHash string
+		Count          int64
+	}
+	result := r.db.WithContext(ctx).Model(&models.Media{}).
+		Select("perceptual_hash, COUNT(*) AS count").
+		Where("perceptual_hash IS NOT NULL AND perceptual_hash <> ''").
+		Group("perceptual_hash").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.PerceptualHash] = row.Count
+	}
+	return counts, nil
+}
+
+// FindMediaByPerceptualHashes finds the media with any of the given perceptual hashes, in upload order
+func (r *MediaRepository) FindMediaByPerceptualHashes(ctx context.Context, hashes []string) ([]models.Media, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.FindMediaByPerceptualHashes")
+	defer span.End()
+
+	var media []models.Media
+	result := r.db.WithContext(ctx).
+		Where("perceptual_hash IN ?", hashes).
+		Order("created_at asc").
+		Find(&media)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return media, nil
+}
+
+// FindMediaByIDs finds the media with the given IDs, skipping unknown ones
+func (r *MediaRepository) FindMediaByIDs(ctx context.Context, ids []string) ([]models.Media, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.FindMediaByIDs")
+	defer span.End()
+
+	var media []models.Media
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&media).Error; err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// MoveMedia moves media into a folder, or to the library root when folderID is nil,
+// storing the URLs their files are served from after the move
+func (r *MediaRepository) MoveMedia(ctx context.Context, media []models.Media, folderID *string) error {
+	ctx, span := tracing.Start(ctx, "MediaRepository.MoveMedia")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, m := range media {
+			err := tx.Model(&models.Media{ID: m.ID}).Updates(map[string]interface{}{
+				"folder_id": folderID,
+				"url":       m.URL,
+				"thumb_url": m.ThumbURL,
+			}).Error
+			if err != nil {
+				return translateError(err, "media")
+			}
+		}
+		return nil
+	})
+}
+
+// TagMedia attaches tags to media, creating tags that don't exist yet. Every media
+// must exist; their rows are locked until the tags are attached, so none can be
+// deleted in between.
+func (r *MediaRepository) TagMedia(ctx context.Context, ids []string, tags []string) error {
+	ctx, span := tracing.Start(ctx, "MediaRepository.TagMedia")
+	defer span.End()
+
+	for _, id := range ids {
+		if err := checkID(id, "media"); err != nil {
+			return err
+		}
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var found []string
+		if err := tx.Model(&models.Media{}).Clauses(clause.Locking{Strength: "SHARE"}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		var missing []string
+		for _, id := range ids {
+			if !slices.Contains(found, id) && !slices.Contains(missing, id) {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			return domain.NotFound("MEDIA_NOT_FOUND", "Media not found").WithDetail("Unknown media: " + strings.Join(missing, ", "))
+		}
+
+		tagModels, err := r.findOrCreateTags(tx, tags)
+		if err != nil {
+			return err
+		}
+
+		// Build the pivot rows for every media/tag pair
+		pivots := make([]models.MediaTagPivot, 0, len(found)*len(tagModels))
+		for _, id := range found {
+			for _, tag := range tagModels {
+				pivots = append(pivots, models.MediaTagPivot{MediaID: id, TagID: tag.ID})
+			}
+		}
+		if len(pivots) == 0 {
+			return nil
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&pivots).Error
+	})
+}
+
+// UntagMedia detaches tags from media
+func (r *MediaRepository) UntagMedia(ctx context.Context, ids []string, tags []string) error {
+	ctx, span := tracing.Start(ctx, "MediaRepository.UntagMedia")
+	defer span.End()
+
+	tagIDs := r.db.Model(&models.MediaTag{}).Select("id").Where("slug IN ?", slugify(tags))
+	return r.db.WithContext(ctx).
+		Where("media_id IN ? AND tag_id IN (?)", ids, tagIDs).
+		Delete(&models.MediaTagPivot{}).Error
+}
+
+// GetAllTags retrieves all media tags
+func (r *MediaRepository) GetAllTags(ctx context.Context) ([]models.MediaTag, error) {
+	ctx, span := tracing.Start(ctx, "MediaRepository.GetAllTags")
+	defer span.End()
+
+	var tags []models.MediaTag
+	result := r.db.WithContext(ctx).Order("name asc").Find(&tags)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tags, nil
+}
+
+// findOrCreateTags returns the tags with the given names, creating missing ones
+func (r *MediaRepository) findOrCreateTags(tx *gorm.DB, names []string) ([]models.MediaTag, error) {
+	tags := make([]models.MediaTag, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		slug := utils.GenerateSlug(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+
+		tag := models.MediaTag{Name: strings.TrimSpace(name), Slug: slug}
+		if err := tx.Where("slug = ?", slug).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// slugify converts tag names to their distinct slugs
+func slugify(names []string) []string {
+	slugs := make([]string, 0, len(names))
+	for _, name := range names {
+		if slug := utils.GenerateSlug(name); !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+	return slugs
+}
@@ -0,0 +1,213 @@
+package implementations
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"beautyessentials.com/internal/domain"
+	"beautyessentials.com/internal/repository/interfaces"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const testMediaID = "01J0000000000000000000MED1"
+
+// mediaDeletion answers the statements of a media deletion; the media has the given attachments
+func mediaDeletion(found bool, attachments int) func(query string, args []driver.NamedValue) fakeResult {
+	return func(query string, args []driver.NamedValue) fakeResult {
+		switch {
+		case strings.HasPrefix(query, `SELECT * FROM "medias"`):
+			if !found {
+				return fakeResult{Columns: []string{"id", "file_id"}}
+			}
+			return fakeResult{Columns: []string{"id", "file_id"}, Rows: [][]driver.Value{{testMediaID, "file-1"}}}
+		case strings.HasPrefix(query, `SELECT * FROM "mediables"`):
+			rows := make([][]driver.Value, attachments)
+			for i := range rows {
+				rows[i] = []driver.Value{"01J000000000000000000MABL" + string(rune('0'+i)), "01J0000000000000000000CAT1", "App\\Model\\Category", testMediaID}
+			}
+			return fakeResult{Columns: []string{"id", "mediable_id", "mediable_type", "media_id"}, Rows: rows}
+		}
+		return fakeResult{RowsAffected: 1}
+	}
+}
+
+func TestDeleteMedia(t *testing.T) {
+	tests := []struct {
+		name        string
+		found       bool
+		attachments int
+		force       bool
+		wantErr     error
+		wantUsages  int
+		want        []string
+	}{
+		{
+			name:  "unused media is deleted",
+			found: true,
+			want:  []string{"BEGIN", `SELECT * FROM "medias"`, `SELECT * FROM "mediables"`, `DELETE FROM "medias"`, "COMMIT"},
+		},
+		{
+			name:        "attached media is kept without force",
+			found:       true,
+			attachments: 2,
+			wantErr:     interfaces.ErrMediaInUse,
+			wantUsages:  2,
+			want:        []string{"BEGIN", `SELECT * FROM "medias"`, `SELECT * FROM "mediables"`, "ROLLBACK"},
+		},
+		{
+			name:        "attached media is detached and deleted with force",
+			found:       true,
+			attachments: 2,
+			force:       true,
+			wantUsages:  2,
+			want:        []string{"BEGIN", `SELECT * FROM "medias"`, `SELECT * FROM "mediables"`, `DELETE FROM "mediables"`, `DELETE FROM "medias"`, "COMMIT"},
+		},
+		{
+			name:    "missing media is not found",
+			wantErr: domain.NotFound("MEDIA_NOT_FOUND", "Media not found"),
+			want:    []string{"BEGIN", `SELECT * FROM "medias"`, "ROLLBACK"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, mediaDeletion(tt.found, tt.attachments))
+
+			media, usages, err := NewMediaRepository(db).DeleteMedia(context.Background(), testMediaID, tt.force)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("DeleteMedia() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(usages) != tt.wantUsages {
+				t.Errorf("DeleteMedia() returned %d usages, want %d", len(usages), tt.wantUsages)
+			}
+			if tt.wantErr == nil && media.FileID != "file-1" {
+				t.Errorf("DeleteMedia() media file = %q, want the deleted media's", media.FileID)
+			}
+
+			var got []string
+			for _, statement := range fake.Statements() {
+				if strings.HasPrefix(statement, `SELECT * FROM "medias"`) && !strings.HasSuffix(statement, "FOR UPDATE") {
+					t.Errorf("media is read without locking it: %s", statement)
+				}
+				for _, prefix := range tt.want {
+					if strings.HasPrefix(statement, prefix) {
+						got = append(got, prefix)
+						break
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements = %q, want %q", fake.Statements(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateMediaConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		wantErr    error
+	}{
+		{"same content", mediaContentHashIndex, interfaces.ErrDuplicateContent},
+		{"other unique index", "medias_pkey", domain.Conflict("MEDIA_ALREADY_EXISTS", "Media already exists")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := newFakeDB(t, func(query string, args []driver.NamedValue) fakeResult {
+				if strings.HasPrefix(query, `INSERT INTO "medias"`) {
+					return fakeResult{Err: &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: tt.constraint}}
+				}
+				return fakeResult{}
+			})
+
+			_, err := NewMediaRepository(db).CreateMedia(context.Background(), map[string]interface{}{
+				"file_id":      "file-1",
+				"url":          "https://example.com/file-1.png",
+				"thumb_url":    "",
+				"content_hash": strings.Repeat("a", 64),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateMedia() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTagMedia(t *testing.T) {
+	const otherMediaID = "01J0000000000000000000MED2"
+	tests := []struct {
+		name       string
+		ids        []string
+		wantErr    error
+		wantTagged bool
+	}{
+		{
+			name:       "existing media are tagged",
+			ids:        []string{testMediaID, testMediaID},
+			wantTagged: true,
+		},
+		{
+			name:    "unknown media are not found",
+			ids:     []string{testMediaID, otherMediaID},
+			wantErr: domain.NotFound("MEDIA_NOT_FOUND", "Media not found"),
+		},
+		{
+			name:    "invalid media IDs are rejected",
+			ids:     []string{"not-a-ulid"},
+			wantErr: domain.Validation("INVALID_MEDIA_ID", "Invalid media ID"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, func(query string, args []driver.NamedValue) fakeResult {
+				if strings.HasPrefix(query, `SELECT "id" FROM "medias"`) {
+					return fakeResult{Columns: []string{"id"}, Rows: [][]driver.Value{{testMediaID}}}
+				}
+				return fakeResult{RowsAffected: 1}
+			})
+
+			err := NewMediaRepository(db).TagMedia(context.Background(), tt.ids, []string{"Summer"})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("TagMedia() error = %v, want %v", err, tt.wantErr)
+			}
+
+			tagged := false
+			for _, statement := range fake.Statements() {
+				if strings.HasPrefix(statement, `SELECT "id" FROM "medias"`) && !strings.HasSuffix(statement, "FOR SHARE") {
+					t.Errorf("media are read without locking them: %s", statement)
+				}
+				tagged = tagged || strings.HasPrefix(statement, `INSERT INTO "media_tag" `)
+			}
+			if tagged != tt.wantTagged {
+				t.Errorf("statements = %q, want tagging %v", fake.Statements(), tt.wantTagged)
+			}
+		})
+	}
+}
+
+func TestGetAllMediaTagFilterIgnoresRepeatedTags(t *testing.T) {
+	var having []driver.NamedValue
+	db, _ := newFakeDB(t, func(query string, args []driver.NamedValue) fakeResult {
+		if strings.Contains(query, "HAVING") {
+			having = args
+		}
+		return fakeResult{Columns: []string{"id"}}
+	})
+
+	filters := map[string]interface{}{"tags": []string{"Summer", "summer", " SUMMER ", "Sale"}}
+	if _, err := NewMediaRepository(db).GetAllMedia(context.Background(), filters, map[string]interface{}{"paginate": "false"}); err != nil {
+		t.Fatalf("GetAllMedia() error = %v", err)
+	}
+
+	// The media must carry each distinct tag once: summer and sale
+	if len(having) == 0 || having[len(having)-1].Value != int64(2) {
+		t.Errorf("tag filter arguments = %v, want a count of 2 distinct tags", having)
+	}
+}
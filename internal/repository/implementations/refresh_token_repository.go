@@ -0,0 +1,109 @@
+package implementations
+
+import (
+	"context"
+	"time"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository implements the RefreshTokenRepository interface
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new instance of RefreshTokenRepository
+func NewRefreshTokenRepository(db *gorm.DB) interfaces.RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+// CreateRefreshToken stores a new refresh token
+func (r *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+	ctx, span := tracing.Start(ctx, "RefreshTokenRepository.CreateRefreshToken")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(&token).Error; err != nil {
+		return models.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+// FindRefreshTokenByHash finds a refresh token by the hash of its value
+func (r *RefreshTokenRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (models.RefreshToken, error) {
+	ctx, span := tracing.Start(ctx, "RefreshTokenRepository.FindRefreshTokenByHash")
+	defer span.End()
+
+	var token models.RefreshToken
+	result := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token)
+	if result.Error != nil {
+		return models.RefreshToken{}, result.Error
+	}
+	return token, nil
+}
+
+// RotateRefreshToken revokes a refresh token and stores its replacement in one transaction.
+// It fails with ErrRefreshTokenRevoked when the token was revoked concurrently.
+func (r *RefreshTokenRepository) RotateRefreshToken(ctx context.Context, id string, replacement models.RefreshToken) (models.RefreshToken, error) {
+	ctx, span := tracing.Start(ctx, "RefreshTokenRepository.RotateRefreshToken")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&replacement).Error; err != nil {
+			return err
+		}
+
+		// Only the first caller may revoke the token
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": replacement.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return interfaces.ErrRefreshTokenRevoked
+		}
+		return nil
+	})
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	return replacement, nil
+}
+
+// RevokeRefreshToken revokes a single refresh token
+func (r *RefreshTokenRepository) RevokeRefreshToken(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "RefreshTokenRepository.RevokeRefreshToken")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token rotated from the same login
+func (r *RefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	ctx, span := tracing.Start(ctx, "RefreshTokenRepository.RevokeRefreshTokenFamily")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserRefreshTokens revokes every refresh token of a user
+func (r *RefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "RefreshTokenRepository.RevokeUserRefreshTokens")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,156 @@
+package implementations
+
+import (
+	"context"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository implements the RoleRepository interface
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new instance of RoleRepository
+func NewRoleRepository(db *gorm.DB) interfaces.RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+// GetAllRoles retrieves every role with its permissions
+func (r *RoleRepository) GetAllRoles(ctx context.Context) ([]models.Role, error) {
+	ctx, span := tracing.Start(ctx, "RoleRepository.GetAllRoles")
+	defer span.End()
+
+	var roles []models.Role
+	result := r.db.WithContext(ctx).Preload("Permissions").Order("name asc").Find(&roles)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return roles, nil
+}
+
+// FindRole finds a role by ID
+func (r *RoleRepository) FindRole(ctx context.Context, id string) (models.Role, error) {
+	ctx, span := tracing.Start(ctx, "RoleRepository.FindRole")
+	defer span.End()
+
+	var role models.Role
+	result := r.db.WithContext(ctx).Preload("Permissions").Where("id = ?", id).First(&role)
+	if result.Error != nil {
+		return models.Role{}, result.Error
+	}
+	return role, nil
+}
+
+// FindRoleByName finds a role by name
+func (r *RoleRepository) FindRoleByName(ctx context.Context, name string) (models.Role, error) {
+	ctx, span := tracing.Start(ctx, "RoleRepository.FindRoleByName")
+	defer span.End()
+
+	var role models.Role
+	result := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role)
+	if result.Error != nil {
+		return models.Role{}, result.Error
+	}
+	return role, nil
+}
+
+// CreateRole creates a role together with its permissions
+func (r *RoleRepository) CreateRole(ctx context.Context, role models.Role) (models.Role, error) {
+	ctx, span := tracing.Start(ctx, "RoleRepository.CreateRole")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(&role).Error; err != nil {
+		return models.Role{}, err
+	}
+	return role, nil
+}
+
+// UpdateRole updates a role and, when permissions is not nil, replaces its permissions
+func (r *RoleRepository) UpdateRole(ctx context.Context, id string, data map[string]interface{}, permissions []string) (models.Role, error) {
+	ctx, span := tracing.Start(ctx, "RoleRepository.UpdateRole")
+	defer span.End()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(data) > 0 {
+			if err := tx.Model(&models.Role{ID: id}).Updates(data).Error; err != nil {
+				return err
+			}
+		}
+
+		if permissions == nil {
+			return nil
+		}
+
+		if err := tx.Where("role_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(permissions) == 0 {
+			return nil
+		}
+
+		grants := make([]models.RolePermission, 0, len(permissions))
+		for _, permission := range permissions {
+			grants = append(grants, models.RolePermission{RoleID: id, Permission: permission})
+		}
+		return tx.Create(&grants).Error
+	})
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	return r.FindRole(ctx, id)
+}
+
+// DeleteRole deletes a role, its permissions and its assignments
+func (r *RoleRepository) DeleteRole(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "RoleRepository.DeleteRole")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.Role{}).Error
+	})
+}
+
+// SetUserRoles replaces the roles assigned to a user
+func (r *RoleRepository) SetUserRoles(ctx context.Context, userID string, roleIDs []string) error {
+	ctx, span := tracing.Start(ctx, "RoleRepository.SetUserRoles")
+	defer span.End()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if len(roleIDs) == 0 {
+			return nil
+		}
+
+		assignments := make([]models.UserRole, 0, len(roleIDs))
+		for _, roleID := range roleIDs {
+			assignments = append(assignments, models.UserRole{UserID: userID, RoleID: roleID})
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignments).Error
+	})
+}
+
+// AddUserRole assigns a role to a user, keeping their other roles
+func (r *RoleRepository) AddUserRole(ctx context.Context, userID string, roleID string) error {
+	ctx, span := tracing.Start(ctx, "RoleRepository.AddUserRole")
+	defer span.End()
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserRole{UserID: userID, RoleID: roleID}).Error
+}
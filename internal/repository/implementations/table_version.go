@@ -0,0 +1,76 @@
+package implementations
+
+import (
+	"context"
+	"database/sql"
+
+	"beautyessentials.com/internal/models"
+	"gorm.io/gorm"
+)
+
+// tableVersion reads the row count and latest change of the model's table, soft deleted rows included
+func tableVersion(ctx context.Context, db *gorm.DB, model interface{}) (models.TableVersion, error) {
+	var row struct {
+		Count     int64
+		UpdatedAt sql.NullTime
+		DeletedAt sql.NullTime
+	}
+	err := db.WithContext(ctx).Unscoped().Model(model).
+		Select("COUNT(*) AS count, MAX(updated_at) AS updated_at, MAX(deleted_at) AS deleted_at").
+		Scan(&row).Error
+	if err != nil {
+		return models.TableVersion{}, err
+	}
+
+	version := models.TableVersion{Count: row.Count, LastModified: row.UpdatedAt.Time}
+	if row.DeletedAt.Valid && row.DeletedAt.Time.After(version.LastModified) {
+		version.LastModified = row.DeletedAt.Time
+	}
+	return version, nil
+}
+
+// mediaVersion reads the number of media attached to records of mediableType and
+// the latest change of those media. Mediables have no timestamps; attachments made
+// through the records also change the records, while detaching a media from the
+// media side changes the count.
+func mediaVersion(ctx context.Context, db *gorm.DB, mediableType string) (models.TableVersion, error) {
+	var row struct {
+		Count     int64
+		UpdatedAt sql.NullTime
+	}
+	err := db.WithContext(ctx).Table("mediables").
+		Select("COUNT(*) AS count, MAX(medias.updated_at) AS updated_at").
+		Joins("LEFT JOIN medias ON medias.id = mediables.media_id").
+		Where("mediables.mediable_type = ?", mediableType).
+		Scan(&row).Error
+	if err != nil {
+		return models.TableVersion{}, err
+	}
+	return models.TableVersion{Count: row.Count, LastModified: row.UpdatedAt.Time}, nil
+}
+
+// mediaTagVersion reads the number of tags on the media attached to records of
+// mediableType and the latest tagging or change of those tags. Untagging removes
+// pivot rows, which changes the count.
+func mediaTagVersion(ctx context.Context, db *gorm.DB, mediableType string) (models.TableVersion, error) {
+	var row struct {
+		Count     int64
+		CreatedAt sql.NullTime
+		UpdatedAt sql.NullTime
+	}
+	attached := db.Table("mediables").Select("media_id").Where("mediable_type = ?", mediableType)
+	err := db.WithContext(ctx).Table("media_tag").
+		Select("COUNT(*) AS count, MAX(media_tag.created_at) AS created_at, MAX(media_tags.updated_at) AS updated_at").
+		Joins("LEFT JOIN media_tags ON media_tags.id = media_tag.tag_id").
+		Where("media_tag.media_id IN (?)", attached).
+		Scan(&row).Error
+	if err != nil {
+		return models.TableVersion{}, err
+	}
+
+	version := models.TableVersion{Count: row.Count, LastModified: row.CreatedAt.Time}
+	if row.UpdatedAt.Valid && row.UpdatedAt.Time.After(version.LastModified) {
+		version.LastModified = row.UpdatedAt.Time
+	}
+	return version, nil
+}
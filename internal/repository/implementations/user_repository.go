@@ -0,0 +1,59 @@
+package implementations
+
+import (
+	"context"
+
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+)
+
+// UserRepository implements the UserRepository interface
+type UserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new instance of UserRepository
+func NewUserRepository(db *gorm.DB) interfaces.UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+// FindUser finds a user by ID
+func (r *UserRepository) FindUser(ctx context.Context, id string) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.FindUser")
+	defer span.End()
+
+	var user models.User
+	result := r.db.WithContext(ctx).Preload("Roles.Permissions").Where("id = ?", id).First(&user)
+	if result.Error != nil {
+		return models.User{}, result.Error
+	}
+	return user, nil
+}
+
+// FindUserByEmail finds a user by email address
+func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.FindUserByEmail")
+	defer span.End()
+
+	var user models.User
+	result := r.db.WithContext(ctx).Preload("Roles.Permissions").Where("email = ?", email).First(&user)
+	if result.Error != nil {
+		return models.User{}, result.Error
+	}
+	return user, nil
+}
+
+// CreateUser creates a new user
+func (r *UserRepository) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.CreateUser")
+	defer span.End()
+
+	if err := r.db.WithContext(ctx).Create(&user).Error; err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,29 @@
+package implementations
+
+import (
+	"beautyessentials.com/internal/repository/interfaces"
+	"gorm.io/gorm"
+)
+
+// versionedUpdate applies data to the record of query, incrementing its version.
+// A positive version restricts the update to that version, and
+// interfaces.ErrVersionConflict is returned when another write got there first.
+func versionedUpdate(query *gorm.DB, data map[string]interface{}, version int64) error {
+	updates := make(map[string]interface{}, len(data)+1)
+	for key, value := range data {
+		updates[key] = value
+	}
+	updates["version"] = gorm.Expr("version + 1")
+
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return interfaces.ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"beautyessentials.com/internal/models"
+)
+
+// ApiKeyRepository defines the interface for API key database operations
+type ApiKeyRepository interface {
+	GetAllApiKeys(ctx context.Context) ([]models.ApiKey, error)
+	FindApiKey(ctx context.Context, id string) (models.ApiKey, error)
+	FindApiKeyByPrefix(ctx context.Context, prefix string) (models.ApiKey, error)
+	CreateApiKey(ctx context.Context, key models.ApiKey) (models.ApiKey, error)
+	RevokeApiKey(ctx context.Context, id string) error
+	TouchApiKey(ctx context.Context, id string, usedAt time.Time, staleBefore time.Time) error
+}
@@ -9,10 +9,11 @@ import (
 // BrandRepository defines the interface for brand data operations
 type BrandRepository interface {
 	GetAllBrands(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error)
-	FindBrand(ctx context.Context, id string) (models.Brand, error)
+	FindBrand(ctx context.Context, id string, appends map[string]interface{}) (models.Brand, error)
 	CreateBrand(ctx context.Context, data map[string]interface{}) (models.Brand, error)
-	UpdateBrand(ctx context.Context, data map[string]interface{}, id string) (models.Brand, error)
+	UpdateBrand(ctx context.Context, data map[string]interface{}, id string, version int64) (models.Brand, error)
 	DeleteBrand(ctx context.Context, id string) error
 	GetActiveBrands(ctx context.Context) ([]models.Brand, error)
-	GetGroupedBrands(ctx context.Context) (map[string][]models.Brand, error)
+	GetGroupedBrands(ctx context.Context, appends map[string]interface{}) (map[string][]models.Brand, error)
+	GetVersion(ctx context.Context) (models.TableVersion, error)
 }
\ No newline at end of file
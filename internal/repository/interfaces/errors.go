@@ -0,0 +1,12 @@
+package interfaces
+
+import "errors"
+
+// ErrVersionConflict is returned when an update is based on a version that is no longer current
+var ErrVersionConflict = errors.New("record was modified since the given version")
+
+// ErrMediaInUse is returned when deleting a media that is still attached to other entities
+var ErrMediaInUse = errors.New("media is still attached to other entities")
+
+// ErrDuplicateContent is returned when creating a media whose content is already stored
+var ErrDuplicateContent = errors.New("media with the same content already exists")
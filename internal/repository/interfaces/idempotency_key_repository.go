@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"beautyessentials.com/internal/models"
+)
+
+// IdempotencyKeyRepository defines the interface for idempotency key database operations
+type IdempotencyKeyRepository interface {
+	// CreateIdempotencyKey stores a key unless its scope already holds it, reporting whether it was stored
+	CreateIdempotencyKey(ctx context.Context, key models.IdempotencyKey) (bool, error)
+	FindIdempotencyKey(ctx context.Context, scope string, key string) (models.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, scope string, key string, statusCode int, contentType string, body []byte) error
+	DeleteIdempotencyKey(ctx context.Context, id string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"beautyessentials.com/internal/models"
+)
+
+// MediaFolderRepository defines the interface for media folder database operations
+type MediaFolderRepository interface {
+	GetAllFolders(ctx context.Context, filters map[string]interface{}) ([]models.MediaFolder, error)
+	FindFolder(ctx context.Context, id string) (models.MediaFolder, error)
+	CreateFolder(ctx context.Context, data map[string]interface{}) (models.MediaFolder, error)
+	DeleteFolder(ctx context.Context, id string) error
+	IsFolderEmpty(ctx context.Context, id string) (bool, error)
+}
@@ -10,6 +10,16 @@ import (
 type MediaRepository interface {
 	GetAllMedia(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error)
 	CreateMedia(ctx context.Context, data map[string]interface{}) (models.Media, error)
-	DeleteMedia(ctx context.Context, id string) error
+	DeleteMedia(ctx context.Context, id string, force bool) (models.Media, []models.Mediable, error)
 	FindMedia(ctx context.Context, id string) (models.Media, error) // Needed for delete operation
+	FindMediaByFileID(ctx context.Context, fileID string) (models.Media, error)
+	GetMediaUsages(ctx context.Context, id string) ([]models.Mediable, error)
+	FindMediaByIDs(ctx context.Context, ids []string) ([]models.Media, error)
+	MoveMedia(ctx context.Context, media []models.Media, folderID *string) error
+	TagMedia(ctx context.Context, ids []string, tags []string) error
+	UntagMedia(ctx context.Context, ids []string, tags []string) error
+	GetAllTags(ctx context.Context) ([]models.MediaTag, error)
+	FindMediaByContentHash(ctx context.Context, hash string) (models.Media, error)
+	GetPerceptualHashCounts(ctx context.Context) (map[string]int64, error)
+	FindMediaByPerceptualHashes(ctx context.Context, hashes []string) ([]models.Media, error)
 }
\ No newline at end of file
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"beautyessentials.com/internal/models"
+)
+
+// ErrRefreshTokenRevoked is returned when rotating a refresh token that was already revoked
+var ErrRefreshTokenRevoked = errors.New("refresh token has already been revoked")
+
+// RefreshTokenRepository defines the interface for refresh token database operations
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error)
+	FindRefreshTokenByHash(ctx context.Context, hash string) (models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, id string, replacement models.RefreshToken) (models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id string) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID string) error
+}
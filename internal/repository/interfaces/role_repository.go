@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"beautyessentials.com/internal/models"
+)
+
+// RoleRepository defines the interface for role database operations
+type RoleRepository interface {
+	GetAllRoles(ctx context.Context) ([]models.Role, error)
+	FindRole(ctx context.Context, id string) (models.Role, error)
+	FindRoleByName(ctx context.Context, name string) (models.Role, error)
+	CreateRole(ctx context.Context, role models.Role) (models.Role, error)
+	UpdateRole(ctx context.Context, id string, data map[string]interface{}, permissions []string) (models.Role, error)
+	DeleteRole(ctx context.Context, id string) error
+	SetUserRoles(ctx context.Context, userID string, roleIDs []string) error
+	AddUserRole(ctx context.Context, userID string, roleID string) error
+}
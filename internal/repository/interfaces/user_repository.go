@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"beautyessentials.com/internal/models"
+)
+
+// UserRepository defines the interface for user database operations
+type UserRepository interface {
+	FindUser(ctx context.Context, id string) (models.User, error)
+	FindUserByEmail(ctx context.Context, email string) (models.User, error)
+	CreateUser(ctx context.Context, user models.User) (models.User, error)
+}
@@ -0,0 +1,11 @@
+package requests
+
+import "time"
+
+// ApiKeyCreateRequest represents the request to create an API key
+type ApiKeyCreateRequest struct {
+	Name      string     `json:"name" validate:"required,min=2,max=255"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	RateLimit int        `json:"rate_limit" validate:"omitempty,min=1,max=100000"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+}
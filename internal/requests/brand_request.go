@@ -5,7 +5,10 @@ type BrandCreateRequest struct {
 	Name string `json:"name" validate:"required,min=2,max=100"`
 }
 
-// BrandUpdateRequest represents the request structure for brand updates
+// BrandUpdateRequest represents the request structure for brand replacement,
+// also the document merge patches apply to
 type BrandUpdateRequest struct {
-	Name string `json:"name" validate:"omitempty,min=2,max=100"`
+	Name string `json:"name" validate:"required,min=2,max=100"`
+	// Version is the version the update is based on, unless sent as If-Match
+	Version int64 `json:"version" validate:"omitempty,min=1"`
 }
\ No newline at end of file
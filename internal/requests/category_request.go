@@ -8,10 +8,13 @@ type CategoryCreateRequest struct {
 	MediaID     string `json:"media_id" validate:"omitempty,ulid"`
 }
 
-// CategoryUpdateRequest represents the request to update a category
+// CategoryUpdateRequest represents the request to replace a category; omitted
+// fields are cleared, and it is also the document merge patches apply to
 type CategoryUpdateRequest struct {
-	Name        string `json:"name" validate:"omitempty,min=2,max=255"`
+	Name        string `json:"name" validate:"required,min=2,max=255"`
 	Description string `json:"description" validate:"omitempty"`
 	Status      string `json:"status" validate:"omitempty,oneof=active inactive"`
 	MediaID     string `json:"media_id" validate:"omitempty,ulid"`
+	// Version is the version the update is based on, unless sent as If-Match
+	Version int64 `json:"version" validate:"omitempty,min=1"`
 }
\ No newline at end of file
@@ -1,8 +1,45 @@
 package requests
 
+import "mime/multipart"
+
 // MediaCreateRequest represents the request to create a media
 type MediaCreateRequest struct {
 	FileID   string `json:"file_id" validate:"required"`
 	URL      string `json:"url" validate:"required,url"`
 	ThumbURL string `json:"thumb_url" validate:"omitempty,url"`
+	FolderID string `json:"folder_id" validate:"omitempty,ulid"`
+}
+
+// MediaUploadRequest represents the multipart request to upload a file through the API
+type MediaUploadRequest struct {
+	File     *multipart.FileHeader `form:"file" validate:"required"`
+	FolderID string                `form:"folder_id" validate:"omitempty,ulid"`
+}
+
+// MediaConfirmUploadRequest represents the request to register a file uploaded directly to
+// storage; token, expire and receipt are those of the credentials the file was uploaded with
+type MediaConfirmUploadRequest struct {
+	FileID   string `json:"file_id" validate:"required"`
+	FolderID string `json:"folder_id" validate:"omitempty,ulid"`
+	Token    string `json:"token" validate:"required"`
+	Expire   int64  `json:"expire" validate:"required"`
+	Receipt  string `json:"receipt" validate:"required,hexadecimal"`
+}
+
+// MediaFolderCreateRequest represents the request to create a media folder
+type MediaFolderCreateRequest struct {
+	Name     string `json:"name" validate:"required,min=1,max=255"`
+	ParentID string `json:"parent_id" validate:"omitempty,ulid"`
+}
+
+// MediaMoveRequest represents the request to move media into a folder
+type MediaMoveRequest struct {
+	MediaIDs []string `json:"media_ids" validate:"required,min=1,dive,ulid"`
+	FolderID string   `json:"folder_id" validate:"omitempty,ulid"`
+}
+
+// MediaTagRequest represents the request to tag or untag media in bulk
+type MediaTagRequest struct {
+	MediaIDs []string `json:"media_ids" validate:"required,min=1,dive,ulid"`
+	Tags     []string `json:"tags" validate:"required,min=1,dive,min=1,max=50"`
 }
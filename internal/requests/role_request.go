@@ -0,0 +1,20 @@
+package requests
+
+// RoleCreateRequest represents the request to create a role
+type RoleCreateRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=100"`
+	Description string   `json:"description" validate:"omitempty,max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+// RoleUpdateRequest represents the request to update a role; a nil field is left unchanged
+type RoleUpdateRequest struct {
+	Name        *string   `json:"name" validate:"omitempty,min=2,max=100"`
+	Description *string   `json:"description" validate:"omitempty,max=255"`
+	Permissions *[]string `json:"permissions" validate:"omitempty,dive,required"`
+}
+
+// UserRolesRequest represents the request to replace the roles of a user
+type UserRolesRequest struct {
+	RoleIDs []string `json:"role_ids" validate:"dive,ulid"`
+}
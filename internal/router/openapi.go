@@ -0,0 +1,240 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/constant"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/openapi"
+	"beautyessentials.com/internal/requests"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// undocumentedRoutes are served outside the JSON API and left out of the spec
+var undocumentedRoutes = map[string]bool{
+	"GET /metrics":          true,
+	"GET /api/openapi.json": true,
+	"GET /api/docs":         true,
+}
+
+// Query parameters shared by list endpoints
+var (
+	searchParam        = openapi.QueryParam("search", &openapi.Schema{Type: "string"}, "Case-insensitive name filter")
+	trashedParam       = openapi.QueryParam("trashed", &openapi.Schema{Type: "boolean"}, "Return only soft-deleted records")
+	sortByParam        = openapi.QueryParam("sort_by", &openapi.Schema{Type: "string", Default: "created_at"}, "Column to sort by")
+	sortDirectionParam = openapi.QueryParam("sort_direction", &openapi.Schema{Type: "string", Enum: []interface{}{"asc", "desc"}, Default: "desc"}, "Sort direction")
+	paginateParam      = openapi.QueryParam("paginate", &openapi.Schema{Type: "boolean", Default: false}, "Return a page with pagination metadata instead of every record")
+	perPageParam       = openapi.QueryParam("per_page", &openapi.Schema{Type: "integer", Default: 15}, "Page size when paginating")
+	pageParam          = openapi.QueryParam("page", &openapi.Schema{Type: "integer", Default: 1}, "Page number when paginating")
+	ifMatchParam       = openapi.HeaderParam("If-Match", &openapi.Schema{Type: "string"}, "ETag of the version being updated; alternatively send the version field")
+	idempotencyParam   = openapi.HeaderParam("Idempotency-Key", &openapi.Schema{Type: "string"}, "Unique key of at most 255 characters making retries of the request safe")
+)
+
+// Sparse fieldset and include parameters of catalog reads
+var (
+	brandFieldsParam    = fieldsParam("brands")
+	categoryFieldsParam = fieldsParam("categories")
+	mediaFieldsParam    = fieldsParam("media")
+	includeMediaParam   = openapi.QueryParam("include", &openapi.Schema{Type: "string", Enum: []interface{}{"media"}}, "Comma separated relationships to embed")
+)
+
+// fieldsParam documents the sparse fieldset of a resource type
+func fieldsParam(resourceType string) openapi.Parameter {
+	return openapi.QueryParam("fields["+resourceType+"]", &openapi.Schema{Type: "string"}, "Comma separated fields of "+resourceType+" to return; id is always returned")
+}
+
+// versionedUpdate describes the optimistic concurrency check of updates
+const versionedUpdate = "Send the version being updated as If-Match or the version field; a stale version fails with 409 and the current record as data."
+
+// mergePatch describes JSON merge patch updates
+const mergePatch = "Send a JSON Merge Patch (RFC 7396): only the members sent change and null clears a field. The patched record is validated like a replacement."
+
+// idempotent describes Idempotency-Key handling of creations
+const idempotent = "Retries sent with the same Idempotency-Key replay the first response with Idempotent-Replayed: true; reusing a key with a different body fails with 422 and a retry while the first request is still running with 409."
+
+// healthStatus is the payload of the health check
+type healthStatus struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// apiRoutes documents every route registered by NewRouter under its versioned
+// path; CheckAPIDocument fails when they drift apart
+var apiRoutes = []openapi.Route{
+	{Method: http.MethodGet, Path: "/ping", Operation: "healthCheck", Tag: "Health", Summary: "Check service health", Response: healthStatus{}},
+
+	// Auth
+	{Method: http.MethodPost, Path: "/api/v1/auth/register", Operation: "register", Tag: "Auth", Summary: "Create an account", Body: requests.RegisterRequest{}, Statuses: []int{http.StatusCreated}, Response: dto.AuthTokensDTO{}, Errors: []int{http.StatusConflict}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/auth/login", Operation: "login", Tag: "Auth", Summary: "Sign in with email and password", Body: requests.LoginRequest{}, Response: dto.AuthTokensDTO{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/auth/refresh", Operation: "refresh", Tag: "Auth", Summary: "Rotate a refresh token", Body: requests.RefreshTokenRequest{}, Response: dto.AuthTokensDTO{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/auth/logout", Operation: "logout", Tag: "Auth", Summary: "Revoke a refresh token", Body: requests.RefreshTokenRequest{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/auth/logout-all", Operation: "logoutAll", Tag: "Auth", Summary: "Revoke every session of the current user", Auth: openapi.AuthUser, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/auth/me", Operation: "me", Tag: "Auth", Summary: "Get the current user", Auth: openapi.AuthUser, Response: dto.UserDTO{}, RateLimited: true},
+
+	// Brands
+	{Method: http.MethodGet, Path: "/api/v1/brands", Operation: "getAllBrands", Tag: "Brands", Summary: "List brands", Query: []openapi.Parameter{searchParam, trashedParam, sortByParam, sortDirectionParam, paginateParam, perPageParam, pageParam, brandFieldsParam}, Response: []dto.BrandDTO{}, Paginated: true, Errors: []int{http.StatusBadRequest}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/brands/:id", Operation: "getBrand", Tag: "Brands", Summary: "Get a brand", Query: []openapi.Parameter{brandFieldsParam}, Response: dto.BrandDTO{}, Errors: []int{http.StatusBadRequest}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/brands", Operation: "createBrand", Tag: "Brands", Summary: "Create a brand", Description: idempotent, Query: []openapi.Parameter{idempotencyParam}, Auth: openapi.AuthAny, Permission: constant.PermissionBrandsWrite, Body: requests.BrandCreateRequest{}, Statuses: []int{http.StatusCreated}, Response: dto.BrandDTO{}, Errors: []int{http.StatusConflict}, RateLimited: true},
+	{Method: http.MethodPut, Path: "/api/v1/brands/:id", Operation: "updateBrand", Tag: "Brands", Summary: "Replace a brand", Description: versionedUpdate, Query: []openapi.Parameter{ifMatchParam}, Auth: openapi.AuthAny, Permission: constant.PermissionBrandsWrite, Body: requests.BrandUpdateRequest{}, Response: dto.BrandDTO{}, Errors: []int{http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired}, RateLimited: true},
+	{Method: http.MethodPatch, Path: "/api/v1/brands/:id", Operation: "patchBrand", Tag: "Brands", Summary: "Patch a brand", Description: mergePatch + " " + versionedUpdate, Query: []openapi.Parameter{ifMatchParam}, Auth: openapi.AuthAny, Permission: constant.PermissionBrandsWrite, Patch: requests.BrandUpdateRequest{}, Response: dto.BrandDTO{}, Errors: []int{http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnsupportedMediaType, http.StatusPreconditionRequired}, RateLimited: true},
+	{Method: http.MethodDelete, Path: "/api/v1/brands/:id", Operation: "deleteBrand", Tag: "Brands", Summary: "Delete a brand", Auth: openapi.AuthAny, Permission: constant.PermissionBrandsDelete, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/brands/grouped", Operation: "getGroupedBrands", Tag: "Brands", Summary: "List brands grouped by initial", Query: []openapi.Parameter{brandFieldsParam}, Response: map[string][]dto.BrandDTO{}, Errors: []int{http.StatusBadRequest}, RateLimited: true, Conditional: true},
+
+	// Categories
+	{Method: http.MethodGet, Path: "/api/v1/categories", Operation: "getAllCategories", Tag: "Categories", Summary: "List categories", Query: []openapi.Parameter{searchParam, trashedParam, sortByParam, sortDirectionParam, paginateParam, perPageParam, pageParam, categoryFieldsParam, mediaFieldsParam, includeMediaParam}, Response: []dto.CategoryDTO{}, Paginated: true, Errors: []int{http.StatusBadRequest}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/categories/:id", Operation: "getCategory", Tag: "Categories", Summary: "Get a category", Query: []openapi.Parameter{categoryFieldsParam, mediaFieldsParam, includeMediaParam}, Response: dto.CategoryDTO{}, Errors: []int{http.StatusBadRequest}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/categories", Operation: "createCategory", Tag: "Categories", Summary: "Create a category", Description: idempotent, Query: []openapi.Parameter{idempotencyParam}, Auth: openapi.AuthAny, Permission: constant.PermissionCategoriesWrite, Body: requests.CategoryCreateRequest{}, Statuses: []int{http.StatusCreated}, Response: dto.CategoryDTO{}, Errors: []int{http.StatusConflict}, RateLimited: true},
+	{Method: http.MethodPut, Path: "/api/v1/categories/:id", Operation: "updateCategory", Tag: "Categories", Summary: "Replace a category", Description: "Omitted fields are cleared. " + versionedUpdate, Query: []openapi.Parameter{ifMatchParam}, Auth: openapi.AuthAny, Permission: constant.PermissionCategoriesWrite, Body: requests.CategoryUpdateRequest{}, Response: dto.CategoryDTO{}, Errors: []int{http.StatusConflict, http.StatusPreconditionFailed, http.StatusPreconditionRequired}, RateLimited: true},
+	{Method: http.MethodPatch, Path: "/api/v1/categories/:id", Operation: "patchCategory", Tag: "Categories", Summary: "Patch a category", Description: mergePatch + " " + versionedUpdate, Query: []openapi.Parameter{ifMatchParam}, Auth: openapi.AuthAny, Permission: constant.PermissionCategoriesWrite, Patch: requests.CategoryUpdateRequest{}, Response: dto.CategoryDTO{}, Errors: []int{http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnsupportedMediaType, http.StatusPreconditionRequired}, RateLimited: true},
+	{Method: http.MethodDelete, Path: "/api/v1/categories/:id", Operation: "deleteCategory", Tag: "Categories", Summary: "Delete a category", Auth: openapi.AuthAny, Permission: constant.PermissionCategoriesDelete, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/categories/active", Operation: "getActiveCategories", Tag: "Categories", Summary: "List active categories", Query: []openapi.Parameter{categoryFieldsParam, mediaFieldsParam, includeMediaParam}, Response: []dto.CategoryDTO{}, Errors: []int{http.StatusBadRequest}, RateLimited: true, Conditional: true},
+	{Method: http.MethodGet, Path: "/api/v1/categories/slug/:slug", Operation: "findCategoryBySlug", Tag: "Categories", Summary: "Find categories by slug", Query: []openapi.Parameter{categoryFieldsParam, mediaFieldsParam, includeMediaParam}, Response: []dto.CategoryDTO{}, Errors: []int{http.StatusBadRequest}, RateLimited: true},
+
+	// Media
+	{Method: http.MethodGet, Path: "/api/v1/media", Operation: "getAllMedia", Tag: "Media", Summary: "List media", Auth: openapi.AuthAny, Permission: constant.PermissionMediaRead, Query: []openapi.Parameter{
+		trashedParam,
+		openapi.QueryParam("folder_id", &openapi.Schema{Type: "string"}, `Folder to list; "root" selects media outside any folder`),
+		openapi.QueryParam("tags", &openapi.Schema{Type: "string"}, "Comma separated tag slugs the media must carry"),
+		paginateParam, perPageParam, pageParam, mediaFieldsParam,
+	}, Response: []dto.MediaDTO{}, Paginated: true, Errors: []int{http.StatusBadRequest}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/media", Operation: "createMedia", Tag: "Media", Summary: "Register an existing storage file", Description: idempotent, Query: []openapi.Parameter{idempotencyParam}, Auth: openapi.AuthAny, Permission: constant.PermissionMediaWrite, Body: requests.MediaCreateRequest{}, Statuses: []int{http.StatusCreated}, Response: dto.MediaDTO{}, Errors: []int{http.StatusConflict}, RateLimited: true},
+	{Method: http.MethodDelete, Path: "/api/v1/media/:id", Operation: "deleteMedia", Tag: "Media", Summary: "Delete media", Description: "Fails with 409 while the media is attached unless force is set.", Auth: openapi.AuthAny, Permission: constant.PermissionMediaDelete, Query: []openapi.Parameter{
+		openapi.QueryParam("force", &openapi.Schema{Type: "boolean", Default: false}, "Detach and delete media that is still in use"),
+	}, Errors: []int{http.StatusConflict, http.StatusServiceUnavailable}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/media/:id/usages", Operation: "getMediaUsages", Tag: "Media", Summary: "List entities using a media", Auth: openapi.AuthAny, Permission: constant.PermissionMediaRead, Response: []dto.MediaUsageDTO{}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/media/upload-auth", Operation: "getUploadAuth", Tag: "Media", Summary: "Get credentials for a direct upload", Description: "The file must be uploaded to the returned folder before the credentials expire; the receipt is sent back on confirmation.", Auth: openapi.AuthAny, Permission: constant.PermissionMediaWrite, Query: []openapi.Parameter{
+		openapi.QueryParam("folder_id", &openapi.Schema{Type: "string"}, "Folder the file will be uploaded to"),
+	}, Response: dto.MediaUploadAuthDTO{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/media/confirm", Operation: "confirmUpload", Tag: "Media", Summary: "Register a direct upload", Description: "Fails with 422 unless the file was uploaded with the given credentials, to their folder, while they were valid. Returns 200 with the existing media when the content was uploaded before. " + idempotent, Query: []openapi.Parameter{idempotencyParam}, Auth: openapi.AuthAny, Permission: constant.PermissionMediaWrite, Body: requests.MediaConfirmUploadRequest{}, Statuses: []int{http.StatusCreated, http.StatusOK}, Response: dto.MediaDTO{}, Errors: []int{http.StatusConflict, http.StatusBadGateway, http.StatusServiceUnavailable}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/media/upload", Operation: "uploadMedia", Tag: "Media", Summary: "Upload a file", Description: "The file is stored under the folder's path. Returns 200 with the existing media when the content was uploaded before.", Auth: openapi.AuthAny, Permission: constant.PermissionMediaWrite, Form: requests.MediaUploadRequest{}, Statuses: []int{http.StatusCreated, http.StatusOK}, Response: dto.MediaDTO{}, Errors: []int{http.StatusBadGateway, http.StatusServiceUnavailable}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/media/duplicates", Operation: "getDuplicateReport", Tag: "Media", Summary: "Find visually similar media", Auth: openapi.AuthAny, Permission: constant.PermissionMediaRead, Query: []openapi.Parameter{
+		openapi.QueryParam("threshold", &openapi.Schema{Type: "integer", Default: 5}, "Maximum perceptual hash distance, 0 to 64"),
+	}, Response: []dto.MediaDuplicateGroupDTO{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/media/sync", Operation: "syncRemoteMedia", Tag: "Media", Summary: "Import storage files missing from the library", Auth: openapi.AuthAny, Permission: constant.PermissionMediaSync, Query: []openapi.Parameter{
+		openapi.QueryParam("dry_run", &openapi.Schema{Type: "boolean", Default: false}, "Report what would be imported without importing"),
+	}, Response: dto.MediaSyncReportDTO{}, Errors: []int{http.StatusBadGateway, http.StatusServiceUnavailable}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/media/move", Operation: "moveMedia", Tag: "Media", Summary: "Move media into a folder", Description: "Files are moved to the folder's path in storage too, which changes their URLs.", Auth: openapi.AuthAny, Permission: constant.PermissionMediaWrite, Body: requests.MediaMoveRequest{}, Errors: []int{http.StatusBadGateway, http.StatusServiceUnavailable}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/media/tags", Operation: "getAllTags", Tag: "Media", Summary: "List media tags", Auth: openapi.AuthAny, Permission: constant.PermissionMediaRead, Response: []dto.MediaTagDTO{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/media/tag", Operation: "tagMedia", Tag: "Media", Summary: "Tag media", Auth: openapi.AuthAny, Permission: constant.PermissionMediaWrite, Body: requests.MediaTagRequest{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/media/untag", Operation: "untagMedia", Tag: "Media", Summary: "Remove tags from media", Auth: openapi.AuthAny, Permission: constant.PermissionMediaWrite, Body: requests.MediaTagRequest{}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/media/folders", Operation: "getAllFolders", Tag: "Media folders", Summary: "List media folders", Auth: openapi.AuthAny, Permission: constant.PermissionMediaRead, Query: []openapi.Parameter{
+		openapi.QueryParam("parent_id", &openapi.Schema{Type: "string"}, `Parent folder; "root" selects top-level folders`),
+	}, Response: []dto.MediaFolderDTO{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/media/folders", Operation: "createFolder", Tag: "Media folders", Summary: "Create a media folder", Auth: openapi.AuthAny, Permission: constant.PermissionMediaWrite, Body: requests.MediaFolderCreateRequest{}, Statuses: []int{http.StatusCreated}, Response: dto.MediaFolderDTO{}, Errors: []int{http.StatusConflict, http.StatusBadGateway, http.StatusServiceUnavailable}, RateLimited: true},
+	{Method: http.MethodDelete, Path: "/api/v1/media/folders/:id", Operation: "deleteFolder", Tag: "Media folders", Summary: "Delete an empty media folder", Auth: openapi.AuthAny, Permission: constant.PermissionMediaDelete, Errors: []int{http.StatusConflict, http.StatusBadGateway, http.StatusServiceUnavailable}, RateLimited: true},
+
+	// Roles
+	{Method: http.MethodGet, Path: "/api/v1/roles", Operation: "getAllRoles", Tag: "Roles", Summary: "List roles", Auth: openapi.AuthAny, Permission: constant.PermissionRolesManage, Response: []dto.RoleDTO{}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/roles/:id", Operation: "getRole", Tag: "Roles", Summary: "Get a role", Auth: openapi.AuthAny, Permission: constant.PermissionRolesManage, Response: dto.RoleDTO{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/roles", Operation: "createRole", Tag: "Roles", Summary: "Create a role", Auth: openapi.AuthAny, Permission: constant.PermissionRolesManage, Body: requests.RoleCreateRequest{}, Statuses: []int{http.StatusCreated}, Response: dto.RoleDTO{}, Errors: []int{http.StatusConflict}, RateLimited: true},
+	{Method: http.MethodPut, Path: "/api/v1/roles/:id", Operation: "updateRole", Tag: "Roles", Summary: "Update a role", Auth: openapi.AuthAny, Permission: constant.PermissionRolesManage, Body: requests.RoleUpdateRequest{}, Response: dto.RoleDTO{}, Errors: []int{http.StatusConflict}, RateLimited: true},
+	{Method: http.MethodDelete, Path: "/api/v1/roles/:id", Operation: "deleteRole", Tag: "Roles", Summary: "Delete a role", Auth: openapi.AuthAny, Permission: constant.PermissionRolesManage, Errors: []int{http.StatusConflict}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/permissions", Operation: "getAllPermissions", Tag: "Roles", Summary: "List grantable permissions", Auth: openapi.AuthAny, Permission: constant.PermissionRolesManage, Response: []dto.PermissionDTO{}, RateLimited: true},
+	{Method: http.MethodPut, Path: "/api/v1/users/:id/roles", Operation: "setUserRoles", Tag: "Roles", Summary: "Replace the roles of a user", Auth: openapi.AuthAny, Permission: constant.PermissionRolesManage, Body: requests.UserRolesRequest{}, Response: dto.UserDTO{}, RateLimited: true},
+
+	// API keys
+	{Method: http.MethodGet, Path: "/api/v1/api-keys", Operation: "getAllApiKeys", Tag: "API keys", Summary: "List API keys", Auth: openapi.AuthAny, Permission: constant.PermissionApiKeysManage, Response: []dto.ApiKeyDTO{}, RateLimited: true},
+	{Method: http.MethodGet, Path: "/api/v1/api-keys/:id", Operation: "getApiKey", Tag: "API keys", Summary: "Get an API key", Auth: openapi.AuthAny, Permission: constant.PermissionApiKeysManage, Response: dto.ApiKeyDTO{}, RateLimited: true},
+	{Method: http.MethodPost, Path: "/api/v1/api-keys", Operation: "createApiKey", Tag: "API keys", Summary: "Create an API key", Description: "The key is only returned by this call.", Auth: openapi.AuthAny, Permission: constant.PermissionApiKeysManage, Body: requests.ApiKeyCreateRequest{}, Statuses: []int{http.StatusCreated}, Response: dto.ApiKeyCreatedDTO{}, RateLimited: true},
+	{Method: http.MethodDelete, Path: "/api/v1/api-keys/:id", Operation: "revokeApiKey", Tag: "API keys", Summary: "Revoke an API key", Auth: openapi.AuthAny, Permission: constant.PermissionApiKeysManage, RateLimited: true},
+}
+
+// APIDocument generates the OpenAPI document of the routes registered by NewRouter,
+// marking the routes versioning deprecates
+func APIDocument(versioning config.VersioningConfig) *openapi.Document {
+	schemas := openapi.NewSchemas()
+	schemas.Override(gorm.DeletedAt{}, &openapi.Schema{Type: "string", Format: "date-time", Nullable: true})
+
+	meta := &openapi.Schema{Type: "object", Required: []string{"total", "perPage", "currentPage", "lastPage"}, Properties: map[string]*openapi.Schema{
+		"total":       {Type: "integer", Format: "int64"},
+		"perPage":     {Type: "integer"},
+		"currentPage": {Type: "integer"},
+		"lastPage":    {Type: "integer"},
+	}}
+
+	return openapi.Build(openapi.Spec{
+		Info: openapi.Info{
+			Title:       "Beauty Essentials API",
+			Description: "JSON keys are camelCase by default; send `Accept-Case: snake_case` or `?case=snake_case` for snake_case keys, echoed in the Content-Case response header. Request bodies may use either casing. Errors carry a stable machine-readable code and are sent as RFC 7807 problem details to clients accepting application/problem+json. Every response is wrapped in an envelope with success and message fields. Routes are versioned under /api/v1, with the unversioned /api paths kept as an alias of v1; deprecated routes send Deprecation and Sunset headers.",
+			Version:     "1.0.0",
+		},
+		Servers: []openapi.Server{{URL: "/"}},
+		Tags: []openapi.Tag{
+			{Name: "Health"}, {Name: "Auth"}, {Name: "Brands"}, {Name: "Categories"},
+			{Name: "Media"}, {Name: "Media folders"}, {Name: "Roles"}, {Name: "API keys"},
+		},
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Access token from /api/v1/auth/login"},
+			"apiKeyAuth": {Type: "apiKey", In: "header", Name: "Authorization", Description: "Send `Authorization: ApiKey <key>`"},
+		},
+		Security: map[openapi.Auth][]map[string][]string{
+			openapi.AuthAny:  {{"bearerAuth": {}}, {"apiKeyAuth": {}}},
+			openapi.AuthUser: {{"bearerAuth": {}}},
+		},
+		Envelope: func(data *openapi.Schema) *openapi.Schema {
+			schema := &openapi.Schema{Type: "object", Required: []string{"success", "message"}, Properties: map[string]*openapi.Schema{
+				"success": {Type: "boolean"},
+				"message": {Type: "string"},
+			}}
+			if data != nil {
+				schema.Properties["data"] = data
+				schema.Required = append(schema.Required, "data")
+			}
+			return schema
+		},
+		PaginatedEnvelope: func(items *openapi.Schema) *openapi.Schema {
+			return &openapi.Schema{Type: "object", Required: []string{"success", "message", "data", "meta"}, Properties: map[string]*openapi.Schema{
+				"success": {Type: "boolean"},
+				"message": {Type: "string"},
+				"data":    {Type: "array", Items: items},
+				"meta":    meta,
+			}}
+		},
+		Error:   schemas.JSON(responses.ErrorResponse{}),
+		Problem: schemas.JSON(responses.Problem{}),
+	}, schemas, documentedRoutes(versioning.Deprecations))
+}
+
+// documentedRoutes returns apiRoutes with the deprecation schedule applied
+func documentedRoutes(deprecations map[string]config.RouteDeprecation) []openapi.Route {
+	routes := make([]openapi.Route, len(apiRoutes))
+	copy(routes, apiRoutes)
+	for i, route := range routes {
+		path := strings.TrimPrefix(route.Path, apiV1Prefix)
+		if path == route.Path {
+			continue
+		}
+		deprecation, ok := deprecations[route.Method+" "+path]
+		if !ok {
+			continue
+		}
+		routes[i].Deprecated = true
+		if !deprecation.Sunset.IsZero() {
+			routes[i].Description = strings.TrimSpace(route.Description + "\n\nRemoved after " + deprecation.Sunset.Format("2006-01-02") + ".")
+		}
+	}
+	return routes
+}
+
+// CheckAPIDocument reports routes registered on engine but missing from the spec, and the reverse
+func CheckAPIDocument(engine *gin.Engine) error {
+	var registered []string
+	seen := make(map[string]bool)
+	for _, route := range engine.Routes() {
+		if undocumentedRoutes[route.Method+" "+route.Path] {
+			continue
+		}
+		// The unversioned alias shares the v1 documentation
+		key := route.Method + " " + canonicalPath(route.Path)
+		if !seen[key] {
+			seen[key] = true
+			registered = append(registered, key)
+		}
+	}
+	return openapi.Diff(apiRoutes, registered)
+}
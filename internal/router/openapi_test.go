@@ -0,0 +1,112 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"beautyessentials.com/internal/api/handlers"
+	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/openapi"
+	"beautyessentials.com/internal/utils/ratelimit"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// newTestEngine builds the real router; handlers are only referenced while
+// registering routes, so zero values suffice
+func newTestEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	return NewRouter(
+		responses.NewResponseHelper(),
+		&handlers.HealthHandler{},
+		&handlers.BrandHandler{},
+		&handlers.CategoryHandler{},
+		&handlers.MediaHandler{},
+		&handlers.MediaFolderHandler{},
+		&handlers.AuthHandler{},
+		&handlers.RoleHandler{},
+		&handlers.ApiKeyHandler{},
+		nil,
+		nil,
+		nil,
+		ratelimit.NewMemoryStore(),
+		&config.Config{},
+		zap.NewNop(),
+	)
+}
+
+func TestAPIDocumentMatchesRoutes(t *testing.T) {
+	engine := newTestEngine(t)
+
+	// Operations of the generated document, keyed like the registered routes
+	documented := make(map[string]bool)
+	for path, item := range APIDocument(config.VersioningConfig{}).Paths {
+		for method := range *item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range engine.Routes() {
+		if undocumentedRoutes[route.Method+" "+route.Path] {
+			continue
+		}
+		// The unversioned alias shares the v1 documentation
+		registered[route.Method+" "+openapi.Path(canonicalPath(route.Path))] = true
+	}
+
+	var problems []string
+	for key := range registered {
+		if !documented[key] {
+			problems = append(problems, "route missing from the spec: "+key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			problems = append(problems, "spec path without a route: "+key)
+		}
+	}
+	sort.Strings(problems)
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+
+	if err := CheckAPIDocument(engine); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnversionedAliasMirrorsV1(t *testing.T) {
+	engine := newTestEngine(t)
+
+	registered := make(map[string]bool)
+	for _, route := range engine.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, route := range apiRoutes {
+		if !strings.HasPrefix(route.Path, apiV1Prefix+"/") {
+			continue
+		}
+		alias := apiPrefix + strings.TrimPrefix(route.Path, apiV1Prefix)
+		if !registered[route.Method+" "+alias] {
+			t.Errorf("%s %s has no unversioned alias %s", route.Method, route.Path, alias)
+		}
+	}
+}
+
+func TestCheckAPIDocumentReportsDrift(t *testing.T) {
+	engine := newTestEngine(t)
+	engine.GET(apiV1Prefix+"/undocumented", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	err := CheckAPIDocument(engine)
+	if err == nil {
+		t.Fatal("CheckAPIDocument accepted an undocumented route")
+	}
+	if !strings.Contains(err.Error(), "GET "+apiV1Prefix+"/undocumented") {
+		t.Errorf("error does not name the undocumented route: %v", err)
+	}
+}
@@ -1,13 +1,17 @@
 package router
 
 import (
-	"fmt"
-	"time"
-
 	"beautyessentials.com/internal/api/handlers"
 	"beautyessentials.com/internal/api/middlewares"
 	"beautyessentials.com/internal/api/responses"
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/constant"
+	"beautyessentials.com/internal/openapi"
+	"beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/metrics"
+	"beautyessentials.com/internal/utils/ratelimit"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // Router interface defines methods for the router
@@ -23,31 +27,33 @@ func NewRouter(
 	brandHandler *handlers.BrandHandler,
 	categoryHandler *handlers.CategoryHandler,
 	mediaHandler *handlers.MediaHandler,
+	mediaFolderHandler *handlers.MediaFolderHandler,
+	authHandler *handlers.AuthHandler,
+	roleHandler *handlers.RoleHandler,
+	apiKeyHandler *handlers.ApiKeyHandler,
+	authService interfaces.AuthService,
+	apiKeyService interfaces.ApiKeyService,
+	idempotencyService interfaces.IdempotencyService,
+	rateLimitStore ratelimit.Store,
+	cfg *config.Config,
+	logger *zap.Logger,
 ) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+
+	// Let handlers pass the gin context to services so request cancellation
+	// and the request ID reach outbound calls and query logs
+	router.ContextWithFallback = true
 
-	// Add middleware
+	// Add middleware; recovery runs innermost so its response is still sent in the negotiated key casing,
+	// and idempotent responses are recorded once the error handler has answered
+	router.Use(middlewares.RequestID())
+	router.Use(middlewares.Tracing())
+	router.Use(middlewares.RequestLogger(logger))
+	router.Use(middlewares.Metrics())
 	router.Use(middlewares.CaseConverterMiddleware())
-	router.Use(middlewares.ErrorHandler(respHelper))
-
-	// Add custom logger middleware
-	router.Use(func(c *gin.Context) {
-		// Start timer
-		t := time.Now()
-
-		// Process request
-		c.Next()
-
-		// Log request details
-		latency := time.Since(t)
-		status := c.Writer.Status()
-		fmt.Printf("[GIN] %s | %3d | %s | %s\n",
-			c.Request.Method,
-			status,
-			latency,
-			c.Request.URL.Path,
-		)
-	})
+	router.Use(middlewares.IdempotencyRecorder(logger, idempotencyService))
+	router.Use(middlewares.ErrorHandler(respHelper, logger))
+	router.Use(middlewares.Recovery(respHelper, logger))
 
 	// Handle 404 Not Found
 	router.NoRoute(func(c *gin.Context) {
@@ -59,40 +65,129 @@ func NewRouter(
 	// Register routes
 	router.GET("/ping", healthHandler.HealthCheck)
 
-	// API routes
-	api := router.Group("/api")
+	// Prometheus scrape endpoint
+	if metricsConfig := cfg.Metrics(); metricsConfig.Enabled {
+		router.GET("/metrics", middlewares.MetricsToken(metricsConfig.Token), gin.WrapH(metrics.Handler()))
+	}
+
+	// Catalog mutations and the media library require a user or API key holding the route's permission
+	requireAuth := middlewares.Authenticate(authService, apiKeyService)
+	can := middlewares.RequirePermission
+
+	// Rate limits per route group; a zero limit disables the middleware
+	rateLimitConfig := cfg.RateLimit()
+	limit := func(name string, perMinute int, keyFunc middlewares.RateLimitKeyFunc) gin.HandlerFunc {
+		if !rateLimitConfig.Enabled {
+			perMinute = 0
+		}
+		return middlewares.RateLimit(respHelper, logger, rateLimitStore, name, ratelimit.PerMinute(perMinute), keyFunc)
+	}
+	publicLimit := limit("public", rateLimitConfig.Public, middlewares.KeyByIP)
+	authLimit := limit("auth", rateLimitConfig.Auth, middlewares.KeyByIP)
+	apiLimit := limit("api", rateLimitConfig.API, middlewares.KeyByPrincipal)
+
+	// Creations retried with the same Idempotency-Key replay the first response
+	idempotent := middlewares.Idempotency(respHelper, logger, idempotencyService)
+
+	// Conditional requests and caching of catalog listings
+	cacheConfig := cfg.HTTPCache()
+	groupedBrandsCache := middlewares.Conditional(middlewares.CachePolicy{CacheControl: cacheConfig.GroupedBrands, Validator: brandHandler.BrandsVersion})
+	activeCategoriesCache := middlewares.Conditional(middlewares.CachePolicy{CacheControl: cacheConfig.ActiveCategories, Validator: categoryHandler.CategoriesVersion})
+
+	// API documentation
+	docs := router.Group(apiPrefix)
+	{
+		docs.GET("/openapi.json", gin.WrapH(openapi.JSONHandler(APIDocument(cfg.Versioning()))))
+		docs.GET("/docs", gin.WrapH(openapi.DocsHandler("Beauty Essentials API", "/api/openapi.json")))
+	}
+
+	// Handlers that replace their v1 counterpart under /api/v2, keyed by method and
+	// path relative to the version prefix; every other route is only served by v1
+	v2Handlers := map[string]gin.HandlerFunc{}
+
+	// API routes, served under /api/v1 and the unversioned /api alias, with the
+	// configured v1 deprecations announced on their responses
+	versioning := cfg.Versioning()
+	api := newVersionedGroup(router, v2Handlers, versioning.Deprecations)
 	{
+		// Auth routes
+		auth := api.Group("/auth")
+		{
+			auth.POST("/register", authLimit, authHandler.Register)
+			auth.POST("/login", authLimit, authHandler.Login)
+			auth.POST("/refresh", authLimit, authHandler.Refresh)
+			auth.POST("/logout", authLimit, authHandler.Logout)
+			auth.POST("/logout-all", requireAuth, apiLimit, middlewares.RequireUser(), authHandler.LogoutAll)
+			auth.GET("/me", requireAuth, apiLimit, middlewares.RequireUser(), authHandler.Me)
+		}
+
 		// Brand routes
-		brands := api.Group("/brands")
+		brands := api.Group("/brands", publicLimit)
 		{
 			brands.GET("", brandHandler.GetAllBrands)
 			brands.GET("/:id", brandHandler.GetBrand)
-			brands.POST("", brandHandler.CreateBrand)
-			brands.PUT("/:id", brandHandler.UpdateBrand)
-			brands.DELETE("/:id", brandHandler.DeleteBrand)			
-			brands.GET("/grouped", brandHandler.GetGroupedBrands)
+			brands.POST("", requireAuth, can(constant.PermissionBrandsWrite), idempotent, brandHandler.CreateBrand)
+			brands.PUT("/:id", requireAuth, can(constant.PermissionBrandsWrite), brandHandler.UpdateBrand)
+			brands.PATCH("/:id", requireAuth, can(constant.PermissionBrandsWrite), brandHandler.PatchBrand)
+			brands.DELETE("/:id", requireAuth, can(constant.PermissionBrandsDelete), brandHandler.DeleteBrand)
+			brands.GET("/grouped", groupedBrandsCache, brandHandler.GetGroupedBrands)
 		}
 
 		// Category routes
-		categories := api.Group("/categories")
+		categories := api.Group("/categories", publicLimit)
 		{
 			categories.GET("", categoryHandler.GetAllCategories)
 			categories.GET("/:id", categoryHandler.GetCategory)
-			categories.POST("", categoryHandler.CreateCategory)
-			categories.PUT("/:id", categoryHandler.UpdateCategory)
-			categories.DELETE("/:id", categoryHandler.DeleteCategory)
-			categories.GET("/active", categoryHandler.GetActiveCategories)
+			categories.POST("", requireAuth, can(constant.PermissionCategoriesWrite), idempotent, categoryHandler.CreateCategory)
+			categories.PUT("/:id", requireAuth, can(constant.PermissionCategoriesWrite), categoryHandler.UpdateCategory)
+			categories.PATCH("/:id", requireAuth, can(constant.PermissionCategoriesWrite), categoryHandler.PatchCategory)
+			categories.DELETE("/:id", requireAuth, can(constant.PermissionCategoriesDelete), categoryHandler.DeleteCategory)
+			categories.GET("/active", activeCategoriesCache, categoryHandler.GetActiveCategories)
 			categories.GET("/slug/:slug", categoryHandler.FindCategoryBySlug)
 		}
 		
 		// Media routes
-		media := api.Group("/media")
+		media := api.Group("/media", requireAuth, apiLimit)
 		{
-			media.GET("", mediaHandler.GetAllMedia)         // index
-			media.POST("", mediaHandler.CreateMedia)        // store
-			media.DELETE("/:id", mediaHandler.DeleteMedia)  // destroy
+			media.GET("", can(constant.PermissionMediaRead), mediaHandler.GetAllMedia)               // index
+			media.POST("", can(constant.PermissionMediaWrite), idempotent, mediaHandler.CreateMedia) // store
+			media.DELETE("/:id", can(constant.PermissionMediaDelete), mediaHandler.DeleteMedia)      // destroy
+			media.GET("/:id/usages", can(constant.PermissionMediaRead), mediaHandler.GetMediaUsages)
+			media.GET("/upload-auth", can(constant.PermissionMediaWrite), mediaHandler.GetUploadAuth)
+			media.POST("/confirm", can(constant.PermissionMediaWrite), idempotent, mediaHandler.ConfirmUpload)
+			media.POST("/upload", can(constant.PermissionMediaWrite), mediaHandler.UploadMedia)
+			media.GET("/duplicates", can(constant.PermissionMediaRead), mediaHandler.GetDuplicateReport)
+			media.POST("/sync", can(constant.PermissionMediaSync), mediaHandler.SyncRemoteMedia)
+			media.POST("/move", can(constant.PermissionMediaWrite), mediaHandler.MoveMedia)
+			media.GET("/tags", can(constant.PermissionMediaRead), mediaHandler.GetAllTags)
+			media.POST("/tag", can(constant.PermissionMediaWrite), mediaHandler.TagMedia)
+			media.POST("/untag", can(constant.PermissionMediaWrite), mediaHandler.UntagMedia)
+			media.GET("/folders", can(constant.PermissionMediaRead), mediaFolderHandler.GetAllFolders)
+			media.POST("/folders", can(constant.PermissionMediaWrite), mediaFolderHandler.CreateFolder)
+			media.DELETE("/folders/:id", can(constant.PermissionMediaDelete), mediaFolderHandler.DeleteFolder)
 			// Remove other routes that don't match Laravel's apiResource except update
 		}
+
+		// Role administration routes
+		roles := api.Group("/roles", requireAuth, apiLimit, can(constant.PermissionRolesManage))
+		{
+			roles.GET("", roleHandler.GetAllRoles)
+			roles.GET("/:id", roleHandler.GetRole)
+			roles.POST("", roleHandler.CreateRole)
+			roles.PUT("/:id", roleHandler.UpdateRole)
+			roles.DELETE("/:id", roleHandler.DeleteRole)
+		}
+		api.GET("/permissions", requireAuth, apiLimit, can(constant.PermissionRolesManage), roleHandler.GetAllPermissions)
+		api.PUT("/users/:id/roles", requireAuth, apiLimit, can(constant.PermissionRolesManage), roleHandler.SetUserRoles)
+
+		// API key administration routes
+		apiKeys := api.Group("/api-keys", requireAuth, apiLimit, can(constant.PermissionApiKeysManage))
+		{
+			apiKeys.GET("", apiKeyHandler.GetAllApiKeys)
+			apiKeys.GET("/:id", apiKeyHandler.GetApiKey)
+			apiKeys.POST("", apiKeyHandler.CreateApiKey)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeApiKey)
+		}
 	}
 
 	return router
@@ -0,0 +1,118 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"beautyessentials.com/internal/api/middlewares"
+	"beautyessentials.com/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// API prefixes; the unversioned prefix is an alias of v1 kept for existing clients
+const (
+	apiPrefix   = "/api"
+	apiV1Prefix = "/api/v1"
+	apiV2Prefix = "/api/v2"
+)
+
+// versionedGroup registers each route on every group serving v1 and, when a
+// v2 handler exists for it, on the v2 group behind the same middleware
+type versionedGroup struct {
+	v1 []*gin.RouterGroup
+	v2 *gin.RouterGroup
+	// path is relative to the version prefix
+	path string
+	// v2Handlers are keyed by method and path relative to the version prefix, e.g. "GET /brands/grouped"
+	v2Handlers map[string]gin.HandlerFunc
+	// deprecations schedule v1 routes for removal, keyed like v2Handlers
+	deprecations map[string]config.RouteDeprecation
+}
+
+// newVersionedGroup creates the /api, /api/v1 and /api/v2 groups
+func newVersionedGroup(engine *gin.Engine, v2Handlers map[string]gin.HandlerFunc, deprecations map[string]config.RouteDeprecation) *versionedGroup {
+	return &versionedGroup{
+		v1:           []*gin.RouterGroup{engine.Group(apiPrefix), engine.Group(apiV1Prefix)},
+		v2:           engine.Group(apiV2Prefix),
+		v2Handlers:   v2Handlers,
+		deprecations: deprecations,
+	}
+}
+
+// Group creates a sub group on every version
+func (g *versionedGroup) Group(path string, middleware ...gin.HandlerFunc) *versionedGroup {
+	group := &versionedGroup{
+		v2:           g.v2.Group(path, middleware...),
+		path:         g.path + path,
+		v2Handlers:   g.v2Handlers,
+		deprecations: g.deprecations,
+	}
+	for _, v1 := range g.v1 {
+		group.v1 = append(group.v1, v1.Group(path, middleware...))
+	}
+	return group
+}
+
+// Handle registers a route; the last handler is swapped for its v2 replacement on /api/v2
+func (g *versionedGroup) Handle(method, path string, handlers ...gin.HandlerFunc) {
+	key := method + " " + g.path + path
+
+	v1Handlers := handlers
+	if deprecation, ok := g.deprecations[key]; ok {
+		v1Handlers = append([]gin.HandlerFunc{middlewares.Deprecated(middlewares.Deprecation(deprecation))}, handlers...)
+	}
+	for _, v1 := range g.v1 {
+		v1.Handle(method, path, v1Handlers...)
+	}
+
+	if handler, ok := g.v2Handlers[key]; ok {
+		v2Handlers := append(append([]gin.HandlerFunc{}, handlers[:len(handlers)-1]...), handler)
+		g.v2.Handle(method, path, v2Handlers...)
+	}
+}
+
+// GET registers a GET route on every version
+func (g *versionedGroup) GET(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodGet, path, handlers...)
+}
+
+// POST registers a POST route on every version
+func (g *versionedGroup) POST(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPost, path, handlers...)
+}
+
+// PUT registers a PUT route on every version
+func (g *versionedGroup) PUT(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPut, path, handlers...)
+}
+
+// PATCH registers a PATCH route on every version
+func (g *versionedGroup) PATCH(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPatch, path, handlers...)
+}
+
+// DELETE registers a DELETE route on every version
+func (g *versionedGroup) DELETE(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodDelete, path, handlers...)
+}
+
+// canonicalPath maps a route on the unversioned alias to its v1 path
+func canonicalPath(path string) string {
+	if path == apiPrefix || strings.HasPrefix(path, apiPrefix+"/") {
+		if rest := strings.TrimPrefix(path, apiPrefix); !isVersioned(rest) {
+			return apiV1Prefix + rest
+		}
+	}
+	return path
+}
+
+// isVersioned reports whether a path below /api starts with a version segment
+func isVersioned(rest string) bool {
+	for _, prefix := range []string{apiV1Prefix, apiV2Prefix} {
+		version := strings.TrimPrefix(prefix, apiPrefix)
+		if rest == version || strings.HasPrefix(rest, version+"/") {
+			return true
+		}
+	}
+	return false
+}
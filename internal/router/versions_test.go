@@ -0,0 +1,106 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"beautyessentials.com/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// respond returns a handler answering with body
+func respond(body string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	}
+}
+
+// get serves a GET request for path on engine
+func get(engine *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestV2HandlersReplaceTheirV1Counterpart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	api := newVersionedGroup(engine, map[string]gin.HandlerFunc{"GET /brands/grouped": respond("v2 grouped")}, nil)
+	brands := api.Group("/brands", func(c *gin.Context) {
+		c.Header("X-Group", "brands")
+	})
+	brands.GET("/grouped", func(c *gin.Context) {
+		c.Header("X-Route", "grouped")
+	}, respond("v1 grouped"))
+	brands.GET("", respond("v1 list"))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{apiV1Prefix + "/brands/grouped", http.StatusOK, "v1 grouped"},
+		{apiPrefix + "/brands/grouped", http.StatusOK, "v1 grouped"},
+		{apiV2Prefix + "/brands/grouped", http.StatusOK, "v2 grouped"},
+		{apiV1Prefix + "/brands", http.StatusOK, "v1 list"},
+		// Routes without a replacement are only served by v1
+		{apiV2Prefix + "/brands", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := get(engine, tt.path)
+		if w.Code != tt.wantStatus || (tt.wantBody != "" && w.Body.String() != tt.wantBody) {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.wantStatus, tt.wantBody)
+		}
+	}
+
+	// The v2 handler runs behind the same group and route middleware
+	w := get(engine, apiV2Prefix+"/brands/grouped")
+	if w.Header().Get("X-Group") != "brands" || w.Header().Get("X-Route") != "grouped" {
+		t.Errorf("v2 route headers = %v, want the v1 middleware applied", w.Header())
+	}
+}
+
+func TestConfiguredDeprecationsAreAnnounced(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{APIDeprecations: "GET /brands/grouped|2026-01-01|2026-07-01|https://example.com/migrate; GET /categories|2026-02-01"}
+	deprecations := cfg.Versioning().Deprecations
+
+	engine := gin.New()
+	api := newVersionedGroup(engine, map[string]gin.HandlerFunc{"GET /brands/grouped": respond("v2 grouped")}, deprecations)
+	api.Group("/brands").GET("/grouped", respond("v1 grouped"))
+	api.Group("/categories").GET("", respond("v1 categories"))
+	api.Group("/categories").GET("/active", respond("v1 active"))
+
+	tests := []struct {
+		path            string
+		wantDeprecation string
+		wantSunset      string
+		wantLink        string
+	}{
+		{apiV1Prefix + "/brands/grouped", "@1767225600", "Wed, 01 Jul 2026 00:00:00 GMT", `<https://example.com/migrate>; rel="deprecation"`},
+		{apiPrefix + "/brands/grouped", "@1767225600", "Wed, 01 Jul 2026 00:00:00 GMT", `<https://example.com/migrate>; rel="deprecation"`},
+		{apiV1Prefix + "/categories", "@1769904000", "", ""},
+		// The v2 replacement and routes without a schedule are not deprecated
+		{apiV2Prefix + "/brands/grouped", "", "", ""},
+		{apiV1Prefix + "/categories/active", "", "", ""},
+	}
+	for _, tt := range tests {
+		w := get(engine, tt.path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want 200", tt.path, w.Code)
+		}
+		header := w.Header()
+		if header.Get("Deprecation") != tt.wantDeprecation || header.Get("Sunset") != tt.wantSunset || header.Get("Link") != tt.wantLink {
+			t.Errorf("GET %s headers Deprecation=%q Sunset=%q Link=%q, want %q %q %q", tt.path,
+				header.Get("Deprecation"), header.Get("Sunset"), header.Get("Link"), tt.wantDeprecation, tt.wantSunset, tt.wantLink)
+		}
+	}
+
+	// The document marks the same routes deprecated
+	operation := (*APIDocument(cfg.Versioning()).Paths[apiV1Prefix+"/brands/grouped"])["get"]
+	if operation == nil || !operation.Deprecated || !strings.Contains(operation.Description, "Removed after 2026-07-01") {
+		t.Errorf("documented operation = %+v, want it deprecated with its sunset", operation)
+	}
+}
@@ -2,26 +2,101 @@ package external
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
+	mathrand "math/rand/v2"
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/utils/logging"
+	"beautyessentials.com/internal/utils/metrics"
+	"beautyessentials.com/internal/utils/tracing"
+	"go.uber.org/zap"
 )
 
+// ListFilesPageSize is the number of files requested per page from the list API (ImageKit allows up to 1000)
+const ListFilesPageSize = 100
+
+// MaxFetchSize bounds the size of a file downloaded with FetchFile
+const MaxFetchSize = 32 << 20
+
+// ErrServiceUnavailable is returned while ImageKit is considered down and calls are short-circuited
+var ErrServiceUnavailable = errors.New("image storage is temporarily unavailable")
+
+// errResponseTooLarge is returned for responses over the size limit of the request
+var errResponseTooLarge = errors.New("response is too large")
+
+// UnavailableError is the ErrServiceUnavailable of a short-circuited call, telling
+// when the circuit lets calls through again
+type UnavailableError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *UnavailableError) Error() string {
+	return ErrServiceUnavailable.Error()
+}
+
+// Is makes errors.Is match ErrServiceUnavailable
+func (e *UnavailableError) Is(target error) bool {
+	return target == ErrServiceUnavailable
+}
+
+// Error reports a failed ImageKit call: an error response, or no response at all
+type Error struct {
+	// Status is the status code ImageKit answered with, 0 when it did not answer
+	Status  int
+	Message string
+	Err     error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the transport error of calls ImageKit did not answer
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound reports whether ImageKit answered that the requested file or folder does not exist
+func IsNotFound(err error) bool {
+	var imageKitErr *Error
+	return errors.As(err, &imageKitErr) && imageKitErr.Status == http.StatusNotFound
+}
+
 // ImageKitService handles interactions with the ImageKit API
 type ImageKitService struct {
-	publicKey   string
-	privateKey  string
-	urlEndpoint string
-	client      *http.Client
+	publicKey     string
+	privateKey    string
+	urlEndpoint   string
+	uploadBaseURL string
+	apiBaseURL    string
+	uploadTTL     time.Duration
+	client        *http.Client
+	settings      config.ImageKitClientConfig
+	breaker       *circuitBreaker
+	// cdnBreaker guards file downloads, which are served by the CDN rather than the API
+	cdnBreaker *circuitBreaker
+	logger     *zap.Logger
 }
 
 // ImageKitUploadResponse represents the response from ImageKit upload API
@@ -34,96 +109,127 @@ type ImageKitUploadResponse struct {
 	FileType     string `json:"fileType"`
 }
 
+// ImageKitFileDetails represents the response from ImageKit file details API
+type ImageKitFileDetails struct {
+	FileID       string    `json:"fileId"`
+	Name         string    `json:"name"`
+	FilePath     string    `json:"filePath"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail"`
+	Size         int64     `json:"size"`
+	FileType     string    `json:"fileType"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// ImageKitAuthParameters holds the values a client needs to upload directly to ImageKit
+type ImageKitAuthParameters struct {
+	Token     string
+	Expire    int64
+	Signature string
+}
+
 // ImageKitErrorResponse represents an error response from ImageKit API
 type ImageKitErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// ImageKit call metrics; requests and latency are recorded per attempt, failures per call
+var (
+	imageKitRequestsTotal = metrics.NewCounterVec(
+		"imagekit_requests_total",
+		"Number of HTTP attempts made to ImageKit, by operation and response status.",
+		"operation", "status",
+	)
+	imageKitRequestDuration = metrics.NewHistogramVec(
+		"imagekit_request_duration_seconds",
+		"Latency of HTTP attempts made to ImageKit.",
+		nil,
+		"operation",
+	)
+	imageKitFailuresTotal = metrics.NewCounterVec(
+		"imagekit_failures_total",
+		"Number of ImageKit calls that failed after retries, by operation and reason.",
+		"operation", "reason",
+	)
+)
+
+// statusLabel returns the status label for an attempt; transport errors have no status code
+func statusLabel(status int, err error) string {
+	if err != nil || status == 0 {
+		return "error"
+	}
+	return strconv.Itoa(status)
+}
+
+// imageKitRequest describes a single logical call to ImageKit
+type imageKitRequest struct {
+	// operation names the call in metrics
+	operation   string
+	method      string
+	url         string
+	body        []byte
+	contentType string
+	// authenticated calls carry the private key as basic auth
+	authenticated bool
+	// idempotent calls are retried on 429 and 5xx; others only on 429
+	idempotent bool
+	timeout    time.Duration
+	// maxBytes bounds the response body; 0 leaves it unbounded
+	maxBytes int64
+	// breaker overrides the circuit breaker of the API
+	breaker *circuitBreaker
+}
+
 // NewImageKitService creates a new instance of ImageKitService
-func NewImageKitService(cfg *config.Config) *ImageKitService {
+func NewImageKitService(cfg *config.Config, logger *zap.Logger) *ImageKitService {
 	imageKitConfig := cfg.ImageKit()
 	return &ImageKitService{
-		publicKey:   imageKitConfig.PublicKey,
-		privateKey:  imageKitConfig.PrivateKey,
-		urlEndpoint: imageKitConfig.URLEndpoint,
-		client:      &http.Client{},
+		publicKey:     imageKitConfig.PublicKey,
+		privateKey:    imageKitConfig.PrivateKey,
+		urlEndpoint:   imageKitConfig.URLEndpoint,
+		uploadBaseURL: strings.TrimRight(imageKitConfig.UploadBaseURL, "/"),
+		apiBaseURL:    strings.TrimRight(imageKitConfig.APIBaseURL, "/"),
+		uploadTTL:     imageKitConfig.UploadTTL,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		},
+		settings:   imageKitConfig.Client,
+		breaker:    newCircuitBreaker(imageKitConfig.Client.BreakerThreshold, imageKitConfig.Client.BreakerCooldown),
+		cdnBreaker: newCircuitBreaker(imageKitConfig.Client.BreakerThreshold, imageKitConfig.Client.BreakerCooldown),
+		logger:     logger.Named("imagekit"),
 	}
 }
 
-// UploadFile uploads a file to ImageKit
-func (s *ImageKitService) UploadFile(file *multipart.FileHeader) (map[string]interface{}, error) {
-	// Open the file
-	src, err := file.Open()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer src.Close()
-
-	// Read the file content
-	fileBytes, err := io.ReadAll(src)
-	if err != nil {
+// UploadFile uploads the content read from file to ImageKit as fileName, into folder,
+// a path such as "/a/b"; an empty folder is the root
+func (s *ImageKitService) UploadFile(ctx context.Context, file io.Reader, fileName string, folder string) (map[string]interface{}, error) {
+	// Encode the file content to base64 as it is read
+	var encodedFile strings.Builder
+	encoder := base64.NewEncoder(base64.StdEncoding, &encodedFile)
+	if _, err := io.Copy(encoder, file); err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
-
-	// Encode file content to base64
-	encodedFile := base64.StdEncoding.EncodeToString(fileBytes)
+	_ = encoder.Close()
 
 	// Prepare form data
 	formData := url.Values{}
-	formData.Set("file", encodedFile)
-	formData.Set("fileName", file.Filename)
+	formData.Set("file", encodedFile.String())
+	formData.Set("fileName", fileName)
 	formData.Set("useUniqueFileName", "true")
-
-	// Create request
-	req, err := http.NewRequest("POST", "https://upload.imagekit.io/api/v1/files/upload", strings.NewReader(formData.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.SetBasicAuth(s.privateKey, "")
-
-	// Send request
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Check for error response
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		var errorResp ImageKitErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return nil, errors.New(errorResp.Message)
-		}
-		return nil, fmt.Errorf("failed to upload file: status code %d", resp.StatusCode)
-	}
-
-	// Parse response
-	var uploadResp ImageKitUploadResponse
-	if err := json.Unmarshal(body, &uploadResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if folder != "" {
+		formData.Set("folder", folder)
 	}
 
-	// Return result
-	return map[string]interface{}{
-		"fileId":    uploadResp.FileID,
-		"url":       uploadResp.URL,
-		"thumbUrl":  uploadResp.ThumbnailURL,
-		"size":      uploadResp.Size,
-		"file_type": uploadResp.FileType,
-	}, nil
+	return s.upload(ctx, formData, "failed to upload file")
 }
 
 // UploadFromURL uploads a file from a URL to ImageKit
-func (s *ImageKitService) UploadFromURL(sourceURL string, fileName string) (map[string]interface{}, error) {
+func (s *ImageKitService) UploadFromURL(ctx context.Context, sourceURL string, fileName string) (map[string]interface{}, error) {
 	// If fileName is empty, extract it from URL
 	if fileName == "" {
 		parsedURL, err := url.Parse(sourceURL)
@@ -140,36 +246,28 @@ func (s *ImageKitService) UploadFromURL(sourceURL string, fileName string) (map[
 	formData.Set("fileName", fileName)
 	formData.Set("useUniqueFileName", "true")
 
-	// Create request
-	req, err := http.NewRequest("POST", "https://upload.imagekit.io/api/v1/files/upload", strings.NewReader(formData.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.SetBasicAuth(s.privateKey, "")
-
-	// Send request
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+	return s.upload(ctx, formData, "failed to upload file from URL")
+}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+// upload sends an upload form to ImageKit and returns the uploaded file attributes
+func (s *ImageKitService) upload(ctx context.Context, formData url.Values, failure string) (map[string]interface{}, error) {
+	// Uploads are not idempotent: a retried upload creates a second file
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:     "upload",
+		method:        http.MethodPost,
+		url:           s.uploadBaseURL + "/api/v1/files/upload",
+		body:          []byte(formData.Encode()),
+		contentType:   "application/x-www-form-urlencoded",
+		authenticated: true,
+		timeout:       s.settings.UploadTimeout,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	// Check for error response
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		var errorResp ImageKitErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return nil, errors.New(errorResp.Message)
-		}
-		return nil, fmt.Errorf("failed to upload file from URL: status code %d", resp.StatusCode)
+	if status != http.StatusOK && status != http.StatusCreated {
+		return nil, errorFromResponse(status, body, failure)
 	}
 
 	// Parse response
@@ -189,38 +287,28 @@ func (s *ImageKitService) UploadFromURL(sourceURL string, fileName string) (map[
 }
 
 // DeleteFile deletes a file from ImageKit
-func (s *ImageKitService) DeleteFile(fileID string) error {
-	// Create request
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("https://api.imagekit.io/v1/files/%s", fileID), nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.SetBasicAuth(s.privateKey, "")
-
-	// Send request
-	resp, err := s.client.Do(req)
+func (s *ImageKitService) DeleteFile(ctx context.Context, fileID string) error {
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:     "delete_file",
+		method:        http.MethodDelete,
+		url:           s.apiBaseURL + "/v1/files/" + url.PathEscape(fileID),
+		authenticated: true,
+		idempotent:    true,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 
 	// Check for success (204 No Content)
-	if resp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(resp.Body)
-		var errorResp ImageKitErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return errors.New(errorResp.Message)
-		}
-		return fmt.Errorf("failed to delete file: status code %d", resp.StatusCode)
+	if status != http.StatusNoContent {
+		return errorFromResponse(status, body, "failed to delete file")
 	}
 
 	return nil
 }
 
 // DeleteBulkFiles deletes multiple files from ImageKit
-func (s *ImageKitService) DeleteBulkFiles(fileIDs []string) error {
+func (s *ImageKitService) DeleteBulkFiles(ctx context.Context, fileIDs []string) error {
 	// If more than 100 files, chunk them into batches of 100
 	if len(fileIDs) > 100 {
 		chunks := make([][]string, 0)
@@ -231,22 +319,22 @@ func (s *ImageKitService) DeleteBulkFiles(fileIDs []string) error {
 			}
 			chunks = append(chunks, fileIDs[i:end])
 		}
-		
+
 		for _, chunk := range chunks {
 			// Process each chunk with a single API call
-			if err := s.bulkDeleteFilesRequest(chunk); err != nil {
+			if err := s.bulkDeleteFilesRequest(ctx, chunk); err != nil {
 				return err
 			}
 		}
 		return nil
 	}
-	
+
 	// For 100 or fewer files, process them in a single request
-	return s.bulkDeleteFilesRequest(fileIDs)
+	return s.bulkDeleteFilesRequest(ctx, fileIDs)
 }
 
 // bulkDeleteFilesRequest makes the actual API request to delete files in bulk
-func (s *ImageKitService) bulkDeleteFilesRequest(fileIDs []string) error {
+func (s *ImageKitService) bulkDeleteFilesRequest(ctx context.Context, fileIDs []string) error {
 	// Prepare request body
 	type requestBody struct {
 		FileIDs []string `json:"fileIds"`
@@ -257,74 +345,471 @@ func (s *ImageKitService) bulkDeleteFilesRequest(fileIDs []string) error {
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	// Create request
-	req, err := http.NewRequest("POST", "https://api.imagekit.io/v1/files/batch/deleteByFileIds", bytes.NewBuffer(jsonBody))
+	// Deleting the same IDs twice is harmless, so the batch call is safe to retry
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:     "bulk_delete_files",
+		method:        http.MethodPost,
+		url:           s.apiBaseURL + "/v1/files/batch/deleteByFileIds",
+		body:          jsonBody,
+		contentType:   "application/json",
+		authenticated: true,
+		idempotent:    true,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(s.privateKey, "")
+	// Check for success (200 OK with the deleted IDs, or 204 No Content)
+	if status != http.StatusOK && status != http.StatusNoContent {
+		return errorFromResponse(status, body, "failed to delete files in bulk")
+	}
 
-	// Send request
-	resp, err := s.client.Do(req)
+	return nil
+}
+
+// MoveFile moves the file at sourceFilePath into the folder destinationPath. The
+// file keeps its ID, but its URL changes with its path.
+func (s *ImageKitService) MoveFile(ctx context.Context, sourceFilePath string, destinationPath string) error {
+	// Prepare request body
+	type requestBody struct {
+		SourceFilePath  string `json:"sourceFilePath"`
+		DestinationPath string `json:"destinationPath"`
+	}
+	if destinationPath == "" {
+		destinationPath = "/"
+	}
+	jsonBody, err := json.Marshal(requestBody{SourceFilePath: sourceFilePath, DestinationPath: destinationPath})
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	// A repeated move finds no file at the source path, so the call is not retried
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:     "move_file",
+		method:        http.MethodPost,
+		url:           s.apiBaseURL + "/v1/files/move",
+		body:          jsonBody,
+		contentType:   "application/json",
+		authenticated: true,
+	})
+	if err != nil {
+		return err
 	}
-	defer resp.Body.Close()
 
 	// Check for success (204 No Content)
-	if resp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(resp.Body)
-		var errorResp ImageKitErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return errors.New(errorResp.Message)
+	if status != http.StatusNoContent && status != http.StatusOK {
+		return errorFromResponse(status, body, "failed to move file")
+	}
+
+	return nil
+}
+
+// ListFiles lists a single page of files from ImageKit
+func (s *ImageKitService) ListFiles(ctx context.Context, skip int, limit int) ([]ImageKitFileDetails, error) {
+	// Only list files, not folders
+	query := url.Values{}
+	query.Set("type", "file")
+	query.Set("sort", "ASC_CREATED")
+	query.Set("skip", strconv.Itoa(skip))
+	query.Set("limit", strconv.Itoa(limit))
+
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:     "list_files",
+		method:        http.MethodGet,
+		url:           s.apiBaseURL + "/v1/files?" + query.Encode(),
+		authenticated: true,
+		idempotent:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for error response
+	if status != http.StatusOK {
+		return nil, errorFromResponse(status, body, "failed to list files")
+	}
+
+	// Parse response
+	var files []ImageKitFileDetails
+	if err := json.Unmarshal(body, &files); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return files, nil
+}
+
+// ListAllFiles lists all files from ImageKit, following pagination until the last page
+func (s *ImageKitService) ListAllFiles(ctx context.Context) ([]ImageKitFileDetails, error) {
+	var files []ImageKitFileDetails
+	for skip := 0; ; skip += ListFilesPageSize {
+		page, err := s.ListFiles(ctx, skip, ListFilesPageSize)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, page...)
+
+		// A short page is the last one
+		if len(page) < ListFilesPageSize {
+			return files, nil
 		}
-		return fmt.Errorf("failed to delete files in bulk: status code %d", resp.StatusCode)
+	}
+}
+
+// PublicKey returns the ImageKit public key used by clients for direct uploads
+func (s *ImageKitService) PublicKey() string {
+	return s.publicKey
+}
+
+// URLEndpoint returns the ImageKit URL endpoint files are served from
+func (s *ImageKitService) URLEndpoint() string {
+	return s.urlEndpoint
+}
+
+// GetAuthenticationParameters issues a short-lived signature for client-side uploads.
+// The signature is HMAC-SHA1(privateKey, token+expire) as required by ImageKit.
+func (s *ImageKitService) GetAuthenticationParameters() (ImageKitAuthParameters, error) {
+	// Generate a random one-time token
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return ImageKitAuthParameters{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	expire := time.Now().Add(s.UploadTTL()).Unix()
+
+	// Sign token and expiry with the private key
+	mac := hmac.New(sha1.New, []byte(s.privateKey))
+	mac.Write([]byte(fmt.Sprintf("%s%d", token, expire)))
+
+	return ImageKitAuthParameters{
+		Token:     token,
+		Expire:    expire,
+		Signature: hex.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+// UploadTTL returns how long upload credentials stay valid
+func (s *ImageKitService) UploadTTL() time.Duration {
+	// ImageKit rejects expiry times more than an hour ahead
+	if s.uploadTTL <= 0 || s.uploadTTL > time.Hour {
+		return 30 * time.Minute
+	}
+	return s.uploadTTL
+}
+
+// UploadReceipt signs the upload credentials token and expire together with the
+// folder the upload was authorized for, so the upload's confirmation can be
+// checked against them without keeping state
+func (s *ImageKitService) UploadReceipt(token string, expire int64, folder string) string {
+	mac := hmac.New(sha256.New, []byte(s.privateKey))
+	mac.Write([]byte(fmt.Sprintf("upload-receipt\x00%s\x00%d\x00%s", token, expire, folder)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetFileDetails retrieves the details of a single file from ImageKit
+func (s *ImageKitService) GetFileDetails(ctx context.Context, fileID string) (*ImageKitFileDetails, error) {
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:     "get_file_details",
+		method:        http.MethodGet,
+		url:           s.apiBaseURL + "/v1/files/" + url.PathEscape(fileID) + "/details",
+		authenticated: true,
+		idempotent:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for error response
+	if status != http.StatusOK {
+		return nil, errorFromResponse(status, body, "failed to get file details")
+	}
+
+	// Parse response
+	var details ImageKitFileDetails
+	if err := json.Unmarshal(body, &details); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &details, nil
+}
+
+// CreateFolder creates a folder in ImageKit under the given parent path
+func (s *ImageKitService) CreateFolder(ctx context.Context, folderName string, parentFolderPath string) error {
+	// Prepare request body
+	type requestBody struct {
+		FolderName       string `json:"folderName"`
+		ParentFolderPath string `json:"parentFolderPath"`
+	}
+	if parentFolderPath == "" {
+		parentFolderPath = "/"
+	}
+	jsonBody, err := json.Marshal(requestBody{FolderName: folderName, ParentFolderPath: parentFolderPath})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	// Creating an existing folder is a no-op, so the call is safe to retry
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:     "create_folder",
+		method:        http.MethodPost,
+		url:           s.apiBaseURL + "/v1/folder",
+		body:          jsonBody,
+		contentType:   "application/json",
+		authenticated: true,
+		idempotent:    true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Check for success (201 Created)
+	if status != http.StatusCreated && status != http.StatusOK {
+		return errorFromResponse(status, body, "failed to create folder")
+	}
+
+	return nil
+}
+
+// DeleteFolder deletes a folder and its contents from ImageKit
+func (s *ImageKitService) DeleteFolder(ctx context.Context, folderPath string) error {
+	// Prepare request body
+	type requestBody struct {
+		FolderPath string `json:"folderPath"`
+	}
+	jsonBody, err := json.Marshal(requestBody{FolderPath: folderPath})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:     "delete_folder",
+		method:        http.MethodDelete,
+		url:           s.apiBaseURL + "/v1/folder",
+		body:          jsonBody,
+		contentType:   "application/json",
+		authenticated: true,
+		idempotent:    true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Check for success (204 No Content); a missing folder is already gone
+	if status != http.StatusNoContent && status != http.StatusNotFound {
+		return errorFromResponse(status, body, "failed to delete folder")
 	}
 
 	return nil
 }
 
-// ListAllFiles lists all files from ImageKit
-func (s *ImageKitService) ListAllFiles() ([]map[string]interface{}, error) {
+// FetchFile downloads the content of a file served from ImageKit, up to MaxFetchSize bytes.
+// Downloads go through a circuit breaker of their own, so a failing CDN does not
+// short-circuit the API and the other way round.
+func (s *ImageKitService) FetchFile(ctx context.Context, fileURL string) ([]byte, error) {
+	status, body, err := s.do(ctx, imageKitRequest{
+		operation:  "fetch_file",
+		method:     http.MethodGet,
+		url:        fileURL,
+		idempotent: true,
+		timeout:    s.settings.UploadTimeout,
+		maxBytes:   MaxFetchSize,
+		breaker:    s.cdnBreaker,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, &Error{Status: status, Message: fmt.Sprintf("failed to fetch file: status code %d", status)}
+	}
+
+	return body, nil
+}
+
+// do sends a request through the circuit breaker, retrying transient failures
+// with exponential backoff and jitter. It returns the final status code and body.
+func (s *ImageKitService) do(ctx context.Context, r imageKitRequest) (int, []byte, error) {
+	logger := logging.For(ctx, s.logger).With(zap.String("operation", r.operation), zap.String("method", r.method), zap.String("url", r.url))
+
+	breaker := s.breaker
+	if r.breaker != nil {
+		breaker = r.breaker
+	}
+	if ok, retryAfter := breaker.Allow(); !ok {
+		logger.Warn("imagekit call short-circuited", zap.Duration("retry_after", retryAfter))
+		imageKitFailuresTotal.Inc(r.operation, "circuit_open")
+		return 0, nil, &UnavailableError{RetryAfter: retryAfter}
+	}
+
+	maxRetries := max(s.settings.MaxRetries, 0)
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		status, body, retryAfter, err := s.send(ctx, r)
+		latency := time.Since(start)
+		logger := logger.With(zap.Int("attempt", attempt+1), zap.Int("status", status), zap.Duration("latency", latency))
+		imageKitRequestsTotal.Inc(r.operation, statusLabel(status, err))
+		imageKitRequestDuration.Observe(latency.Seconds(), r.operation)
+
+		// Decide whether this outcome counts against the dependency
+		transient := (err != nil && !errors.Is(err, errResponseTooLarge)) || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+		if ctx.Err() != nil {
+			// The caller gave up; that says nothing about ImageKit's health
+			breaker.Abandon()
+			logger.Info("imagekit call abandoned", zap.Error(ctx.Err()))
+			return 0, nil, &Error{Message: "failed to send request", Err: ctx.Err()}
+		}
+		if !transient {
+			breaker.Success()
+			logger.Debug("imagekit call completed")
+			return status, body, err
+		}
+
+		// Only idempotent calls are retried after a server error; a 429 means the request was not processed.
+		// A Retry-After beyond the longest backoff is not waited out.
+		retryable := (r.idempotent || status == http.StatusTooManyRequests) && retryAfter <= s.maxDelay()
+		if !retryable || attempt >= maxRetries {
+			breaker.Failure()
+			logger.Error("imagekit call failed", zap.Error(err))
+			if err != nil {
+				imageKitFailuresTotal.Inc(r.operation, "transport")
+				return 0, nil, &Error{Message: "failed to send request", Err: err}
+			}
+			imageKitFailuresTotal.Inc(r.operation, "status")
+			return status, body, nil
+		}
+
+		// Wait before retrying, honouring Retry-After when ImageKit sends one
+		delay := s.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		logger.Warn("imagekit call failed, retrying", zap.Duration("delay", delay), zap.Error(err))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			breaker.Abandon()
+			return 0, nil, &Error{Message: "failed to send request", Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
+
+// send performs a single HTTP attempt bounded by the call timeout
+func (s *ImageKitService) send(ctx context.Context, r imageKitRequest) (status int, body []byte, retryAfter time.Duration, err error) {
+	ctx, span := tracing.Start(ctx, "imagekit "+r.operation,
+		tracing.WithKind(tracing.KindClient),
+		tracing.WithAttributes(
+			tracing.String("http.request.method", r.method),
+			tracing.String("url.full", r.url),
+			tracing.String("imagekit.operation", r.operation),
+		),
+	)
+	defer func() {
+		if status != 0 {
+			span.SetAttributes(tracing.Int("http.response.status_code", status))
+		}
+		if err != nil {
+			span.RecordError(err)
+		} else if status >= http.StatusBadRequest {
+			span.SetStatus(tracing.StatusError, http.StatusText(status))
+		}
+		span.End()
+	}()
+
+	timeout := r.timeout
+	if timeout <= 0 {
+		timeout = s.settings.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// Create request
-	req, err := http.NewRequest("GET", "https://api.imagekit.io/v1/files", nil)
+	var reqBody io.Reader
+	if r.body != nil {
+		reqBody = bytes.NewReader(r.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return 0, nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
-	req.SetBasicAuth(s.privateKey, "")
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	if r.authenticated {
+		req.SetBasicAuth(s.privateKey, "")
+	}
+	tracing.Inject(ctx, req.Header)
 
 	// Send request
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return 0, nil, 0, err
 	}
 	defer resp.Body.Close()
 
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	reader := io.Reader(resp.Body)
+	if r.maxBytes > 0 {
+		reader = io.LimitReader(resp.Body, r.maxBytes+1)
+	}
+	body, err = io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return 0, nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if r.maxBytes > 0 && int64(len(body)) > r.maxBytes {
+		return resp.StatusCode, nil, 0, fmt.Errorf("%w: over %d bytes", errResponseTooLarge, r.maxBytes)
 	}
 
-	// Check for error response
-	if resp.StatusCode != http.StatusOK {
-		var errorResp ImageKitErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return nil, errors.New(errorResp.Message)
-		}
-		return nil, fmt.Errorf("failed to list files: status code %d", resp.StatusCode)
+	return resp.StatusCode, body, parseRetryAfter(resp.Header.Get("Retry-After")), nil
+}
+
+// backoff returns the delay before the given retry using exponential backoff with full jitter
+func (s *ImageKitService) backoff(attempt int) time.Duration {
+	base := s.settings.RetryBaseDelay
+	if base <= 0 {
+		base = 200 * time.Millisecond
 	}
+	ceiling := s.maxDelay()
 
-	// Parse response
-	var files []map[string]interface{}
-	if err := json.Unmarshal(body, &files); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	delay := ceiling
+	if attempt < 30 {
+		delay = min(base<<attempt, ceiling)
+	}
+	return time.Duration(mathrand.Int64N(int64(delay) + 1))
+}
+
+// maxDelay returns the longest wait before a retry
+func (s *ImageKitService) maxDelay() time.Duration {
+	if s.settings.RetryMaxDelay <= 0 {
+		return 5 * time.Second
 	}
+	return s.settings.RetryMaxDelay
+}
 
-	return files, nil
-}
\ No newline at end of file
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// errorFromResponse builds an error from an ImageKit error response
+func errorFromResponse(status int, body []byte, failure string) error {
+	var errorResp ImageKitErrorResponse
+	if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
+		return &Error{Status: status, Message: errorResp.Message}
+	}
+	return &Error{Status: status, Message: fmt.Sprintf("%s: status code %d", failure, status)}
+}
@@ -0,0 +1,387 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/emulator/imagekit"
+	"go.uber.org/zap"
+)
+
+const testPrivateKey = "private_test"
+
+// newTestImageKit returns a service calling server with the given client settings
+func newTestImageKit(server *httptest.Server, client config.ImageKitClientConfig) *ImageKitService {
+	return NewImageKitService(&config.Config{
+		ImageKitPrivateKey:       testPrivateKey,
+		ImageKitURLEndpoint:      server.URL + "/files",
+		ImageKitUploadURL:        server.URL,
+		ImageKitAPIURL:           server.URL,
+		ImageKitTimeout:          client.Timeout,
+		ImageKitUploadTimeout:    client.UploadTimeout,
+		ImageKitMaxRetries:       client.MaxRetries,
+		ImageKitRetryBaseDelay:   client.RetryBaseDelay,
+		ImageKitRetryMaxDelay:    client.RetryMaxDelay,
+		ImageKitBreakerThreshold: client.BreakerThreshold,
+		ImageKitBreakerCooldown:  client.BreakerCooldown,
+	}, zap.NewNop())
+}
+
+// newEmulator starts an ImageKit emulator storing files in a temporary directory
+func newEmulator(t *testing.T) (*imagekit.Server, *httptest.Server) {
+	t.Helper()
+	emulator, faulty := newFaultyEmulator(t)
+	return emulator, faulty.server
+}
+
+// faultyEmulator serves an ImageKit emulator that answers with a server error
+// while failures remain, counting the requests it receives
+type faultyEmulator struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	requests int
+}
+
+// newFaultyEmulator starts an emulator whose failures are injected with Fail
+func newFaultyEmulator(t *testing.T) (*imagekit.Server, *faultyEmulator) {
+	t.Helper()
+	emulator, err := imagekit.NewServer(t.TempDir(), imagekit.Options{PrivateKey: testPrivateKey})
+	if err != nil {
+		t.Fatalf("imagekit.NewServer() error = %v", err)
+	}
+
+	faulty := &faultyEmulator{}
+	faulty.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		faulty.mu.Lock()
+		faulty.requests++
+		fail := faulty.failures != 0
+		if faulty.failures > 0 {
+			faulty.failures--
+		}
+		faulty.mu.Unlock()
+
+		if fail {
+			http.Error(w, `{"message":"Service unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		emulator.ServeHTTP(w, r)
+	}))
+	t.Cleanup(faulty.server.Close)
+	emulator.SetPublicURL(faulty.server.URL)
+	return emulator, faulty
+}
+
+// Fail makes the next n requests fail, or every request when n is negative
+func (f *faultyEmulator) Fail(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+}
+
+// Requests returns the number of requests received and resets the count
+func (f *faultyEmulator) Requests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = 0
+	return requests
+}
+
+// testClock is a clock tests move forward by hand
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestUploadFileIntoFolderAndMove(t *testing.T) {
+	_, server := newEmulator(t)
+	service := newTestImageKit(server, config.ImageKitClientConfig{})
+	ctx := context.Background()
+
+	uploaded, err := service.UploadFile(ctx, strings.NewReader("logo"), "logo.png", "/brands/logos")
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	fileID := uploaded["fileId"].(string)
+
+	details, err := service.GetFileDetails(ctx, fileID)
+	if err != nil {
+		t.Fatalf("GetFileDetails() error = %v", err)
+	}
+	if !strings.HasPrefix(details.FilePath, "/brands/logos/") {
+		t.Fatalf("uploaded file path = %s, want it inside /brands/logos", details.FilePath)
+	}
+
+	if err := service.MoveFile(ctx, details.FilePath, "/archive"); err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+	moved, err := service.GetFileDetails(ctx, fileID)
+	if err != nil {
+		t.Fatalf("GetFileDetails() error = %v", err)
+	}
+	if want := "/archive/" + details.Name; moved.FilePath != want {
+		t.Errorf("moved file path = %s, want %s", moved.FilePath, want)
+	}
+	if want := server.URL + "/files/archive/" + details.Name; moved.URL != want {
+		t.Errorf("moved file URL = %s, want %s", moved.URL, want)
+	}
+
+	resp, err := http.Get(moved.URL)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", moved.URL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET moved file status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if err := service.MoveFile(ctx, details.FilePath, "/archive"); err == nil {
+		t.Error("MoveFile() of a path that no longer exists succeeded")
+	}
+}
+
+// uploadTestFile uploads a file through service and returns its ID
+func uploadTestFile(t *testing.T, service *ImageKitService) string {
+	t.Helper()
+	uploaded, err := service.UploadFile(context.Background(), strings.NewReader("photo"), "photo.png", "")
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	return uploaded["fileId"].(string)
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	_, faulty := newFaultyEmulator(t)
+	service := newTestImageKit(faulty.server, config.ImageKitClientConfig{
+		MaxRetries:     2,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  time.Millisecond,
+	})
+	ctx := context.Background()
+	fileID := uploadTestFile(t, service)
+	faulty.Requests()
+
+	// Idempotent calls are retried until they succeed
+	faulty.Fail(2)
+	details, err := service.GetFileDetails(ctx, fileID)
+	if err != nil {
+		t.Fatalf("GetFileDetails() error = %v", err)
+	}
+	if details.FileID != fileID {
+		t.Errorf("GetFileDetails() file = %s, want %s", details.FileID, fileID)
+	}
+	if requests := faulty.Requests(); requests != 3 {
+		t.Errorf("GetFileDetails() sent %d requests, want 2 failures and a success", requests)
+	}
+
+	// Retries stop at MaxRetries
+	faulty.Fail(3)
+	if _, err := service.GetFileDetails(ctx, fileID); err == nil {
+		t.Error("GetFileDetails() succeeded although every attempt failed")
+	}
+	if requests := faulty.Requests(); requests != 3 {
+		t.Errorf("GetFileDetails() sent %d requests, want 1 and 2 retries", requests)
+	}
+
+	// Uploads are not idempotent, so a server error is final
+	faulty.Fail(1)
+	if _, err := service.UploadFile(ctx, strings.NewReader("other"), "other.png", ""); err == nil {
+		t.Error("UploadFile() succeeded although ImageKit failed")
+	}
+	if requests := faulty.Requests(); requests != 1 {
+		t.Errorf("UploadFile() sent %d requests, want no retry", requests)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	_, faulty := newFaultyEmulator(t)
+	cooldown := time.Minute
+	service := newTestImageKit(faulty.server, config.ImageKitClientConfig{
+		BreakerThreshold: 3,
+		BreakerCooldown:  cooldown,
+	})
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	service.breaker.now = clock.Now
+	ctx := context.Background()
+	fileID := uploadTestFile(t, service)
+	faulty.Requests()
+
+	// The breaker opens after threshold consecutive failures
+	faulty.Fail(-1)
+	for i := 0; i < 3; i++ {
+		if _, err := service.GetFileDetails(ctx, fileID); err == nil || errors.Is(err, ErrServiceUnavailable) {
+			t.Fatalf("failing call %d error = %v, want ImageKit's error", i+1, err)
+		}
+	}
+	if requests := faulty.Requests(); requests != 3 {
+		t.Fatalf("failing calls sent %d requests, want 3", requests)
+	}
+
+	// While open, calls are rejected without reaching ImageKit, telling when to retry
+	clock.Advance(cooldown - time.Second)
+	_, err := service.GetFileDetails(ctx, fileID)
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("call on an open breaker error = %v, want ErrServiceUnavailable", err)
+	}
+	var unavailable *UnavailableError
+	if !errors.As(err, &unavailable) || unavailable.RetryAfter != time.Second {
+		t.Errorf("call on an open breaker error = %#v, want the remaining second of cooldown", err)
+	}
+	if requests := faulty.Requests(); requests != 0 {
+		t.Fatalf("open breaker let %d requests through", requests)
+	}
+
+	// After the cooldown a failed probe opens it again
+	clock.Advance(time.Second)
+	if _, err := service.GetFileDetails(ctx, fileID); err == nil || errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("probe error = %v, want ImageKit's error", err)
+	}
+	if _, err := service.GetFileDetails(ctx, fileID); !errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("call after a failed probe error = %v, want ErrServiceUnavailable", err)
+	}
+	if requests := faulty.Requests(); requests != 1 {
+		t.Fatalf("failed probe sent %d requests, want 1", requests)
+	}
+
+	// Once a probe succeeds the breaker closes
+	faulty.Fail(0)
+	clock.Advance(cooldown)
+	for i := 0; i < 3; i++ {
+		if _, err := service.GetFileDetails(ctx, fileID); err != nil {
+			t.Fatalf("call %d after a successful probe error = %v", i+1, err)
+		}
+	}
+	if requests := faulty.Requests(); requests != 3 {
+		t.Errorf("closed breaker sent %d requests, want 3", requests)
+	}
+}
+
+func TestCircuitBreakerLetsOneProbeThrough(t *testing.T) {
+	breaker := newCircuitBreaker(1, time.Minute)
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	breaker.now = clock.Now
+
+	breaker.Failure()
+	if ok, wait := breaker.Allow(); ok || wait != time.Minute {
+		t.Fatalf("Allow() on an open breaker = %v, %v; want false, 1m", ok, wait)
+	}
+
+	clock.Advance(time.Minute)
+	if ok, _ := breaker.Allow(); !ok {
+		t.Fatal("Allow() after the cooldown rejected the probe")
+	}
+	if ok, _ := breaker.Allow(); ok {
+		t.Fatal("Allow() let a second call through while probing")
+	}
+
+	// A probe abandoned by its caller frees the slot for another
+	breaker.Abandon()
+	if ok, _ := breaker.Allow(); !ok {
+		t.Fatal("Allow() rejected a probe after the previous one was abandoned")
+	}
+	breaker.Success()
+	if ok, _ := breaker.Allow(); !ok {
+		t.Fatal("Allow() rejected a call after a successful probe")
+	}
+}
+
+func TestRetryAfterBeyondMaxDelayIsNotWaited(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, `{"message":"Too many requests"}`, http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+	service := newTestImageKit(server, config.ImageKitClientConfig{
+		MaxRetries:     3,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  10 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := service.GetFileDetails(context.Background(), "file-1")
+	var imageKitErr *Error
+	if !errors.As(err, &imageKitErr) || imageKitErr.Status != http.StatusTooManyRequests {
+		t.Fatalf("GetFileDetails() error = %v, want ImageKit's 429", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetFileDetails() took %v, want no wait for a Retry-After beyond the longest backoff", elapsed)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 {
+		t.Errorf("GetFileDetails() sent %d requests, want no retry", requests)
+	}
+}
+
+func TestFetchFile(t *testing.T) {
+	var mu sync.Mutex
+	size, fail := 16, false
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			http.Error(w, "Bad gateway", http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write(bytes.Repeat([]byte("x"), size))
+	}))
+	t.Cleanup(cdn.Close)
+	_, api := newEmulator(t)
+	service := newTestImageKit(api, config.ImageKitClientConfig{BreakerThreshold: 1, BreakerCooldown: time.Minute})
+	ctx := context.Background()
+
+	content, err := service.FetchFile(ctx, cdn.URL)
+	if err != nil || len(content) != 16 {
+		t.Fatalf("FetchFile() = %d bytes, %v; want the 16 served", len(content), err)
+	}
+
+	// Files over the limit are not read into memory, and say nothing about the CDN's health
+	mu.Lock()
+	size = MaxFetchSize + 1
+	mu.Unlock()
+	if content, err := service.FetchFile(ctx, cdn.URL); err == nil {
+		t.Errorf("FetchFile() of an oversized file = %d bytes, want an error", len(content))
+	}
+	if ok, _ := service.cdnBreaker.Allow(); !ok {
+		t.Error("an oversized file opened the breaker")
+	}
+
+	// A failing CDN opens its own breaker while the API stays reachable
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	if _, err := service.FetchFile(ctx, cdn.URL); err == nil || errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("FetchFile() from a failing CDN error = %v, want the CDN's error", err)
+	}
+	if _, err := service.FetchFile(ctx, cdn.URL); !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("FetchFile() after the CDN failed error = %v, want ErrServiceUnavailable", err)
+	}
+	uploadTestFile(t, service)
+}
@@ -0,0 +1,202 @@
+package implementations
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/requests"
+	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/ratelimit"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix marks our API keys so they are recognisable in logs and secret scanners
+const apiKeyPrefix = "bek_"
+
+// apiKeyTouchInterval bounds how often last_used_at is written for a busy key
+const apiKeyTouchInterval = time.Minute
+
+// ApiKeyService implements the ApiKeyService interface
+type ApiKeyService struct {
+	apiKeyRepo       interfaces.ApiKeyRepository
+	rateLimitStore   ratelimit.Store
+	defaultRateLimit int
+}
+
+// NewApiKeyService creates a new instance of ApiKeyService
+func NewApiKeyService(apiKeyRepo interfaces.ApiKeyRepository, rateLimitStore ratelimit.Store, cfg *config.Config) serviceInterfaces.ApiKeyService {
+	return &ApiKeyService{
+		apiKeyRepo:       apiKeyRepo,
+		rateLimitStore:   rateLimitStore,
+		defaultRateLimit: cfg.Auth().ApiKeyDefaultRateLimit,
+	}
+}
+
+// GetAllApiKeys retrieves every API key
+func (s *ApiKeyService) GetAllApiKeys(ctx context.Context) ([]dto.ApiKeyDTO, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.GetAllApiKeys")
+	defer span.End()
+
+	keys, err := s.apiKeyRepo.GetAllApiKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dto.TransformApiKeyCollection(keys), nil
+}
+
+// FindApiKey finds an API key by ID
+func (s *ApiKeyService) FindApiKey(ctx context.Context, id string) (dto.ApiKeyDTO, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.FindApiKey")
+	defer span.End()
+
+	key, err := s.apiKeyRepo.FindApiKey(ctx, id)
+	if err != nil {
+		return dto.ApiKeyDTO{}, err
+	}
+	return dto.FromApiKeyModel(key), nil
+}
+
+// CreateApiKey creates an API key limited to scopes the creator holds; the plain key is only returned here
+func (s *ApiKeyService) CreateApiKey(ctx context.Context, request requests.ApiKeyCreateRequest, creatorID *string, grantable []string) (dto.ApiKeyCreatedDTO, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.CreateApiKey")
+	defer span.End()
+
+	scopes, err := normalizePermissions(request.Scopes)
+	if err != nil {
+		return dto.ApiKeyCreatedDTO{}, err
+	}
+
+	// Keys can never hold more than their creator
+	held := make(map[string]bool, len(grantable))
+	for _, permission := range grantable {
+		held[permission] = true
+	}
+	for _, scope := range scopes {
+		if !held[scope] {
+			return dto.ApiKeyCreatedDTO{}, serviceInterfaces.ErrScopeNotGrantable
+		}
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return dto.ApiKeyCreatedDTO{}, serviceInterfaces.ErrExpiryInPast
+	}
+
+	prefix, secret, err := generateApiKey()
+	if err != nil {
+		return dto.ApiKeyCreatedDTO{}, err
+	}
+	plain := apiKeyPrefix + prefix + "_" + secret
+
+	key := models.ApiKey{
+		Name:        strings.TrimSpace(request.Name),
+		Prefix:      prefix,
+		KeyHash:     hashToken(plain),
+		RateLimit:   request.RateLimit,
+		ExpiresAt:   request.ExpiresAt,
+		CreatedByID: creatorID,
+	}
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, models.ApiKeyScope{Scope: scope})
+	}
+
+	key, err = s.apiKeyRepo.CreateApiKey(ctx, key)
+	if err != nil {
+		return dto.ApiKeyCreatedDTO{}, err
+	}
+
+	return dto.ApiKeyCreatedDTO{ApiKeyDTO: dto.FromApiKeyModel(key), Key: plain}, nil
+}
+
+// RevokeApiKey revokes an API key
+func (s *ApiKeyService) RevokeApiKey(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.RevokeApiKey")
+	defer span.End()
+
+	if _, err := s.apiKeyRepo.FindApiKey(ctx, id); err != nil {
+		return err
+	}
+	return s.apiKeyRepo.RevokeApiKey(ctx, id)
+}
+
+// Authenticate verifies an API key, applies its rate limit and records its use
+func (s *ApiKeyService) Authenticate(ctx context.Context, plain string) (dto.ApiKeyDTO, error) {
+	ctx, span := tracing.Start(ctx, "ApiKeyService.Authenticate")
+	defer span.End()
+
+	prefix, ok := parseApiKeyPrefix(plain)
+	if !ok {
+		return dto.ApiKeyDTO{}, serviceInterfaces.ErrInvalidApiKey
+	}
+
+	key, err := s.apiKeyRepo.FindApiKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ApiKeyDTO{}, serviceInterfaces.ErrInvalidApiKey
+		}
+		return dto.ApiKeyDTO{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(plain)), []byte(key.KeyHash)) != 1 {
+		return dto.ApiKeyDTO{}, serviceInterfaces.ErrInvalidApiKey
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)) {
+		return dto.ApiKeyDTO{}, serviceInterfaces.ErrInvalidApiKey
+	}
+
+	// Apply the per-key rate limit
+	limit := key.RateLimit
+	if limit <= 0 {
+		limit = s.defaultRateLimit
+	}
+	result, err := s.rateLimitStore.Take(ctx, "api_key:"+key.ID, ratelimit.PerMinute(limit))
+	if err != nil {
+		return dto.ApiKeyDTO{}, err
+	}
+	if !result.Allowed {
+		return dto.ApiKeyDTO{}, &serviceInterfaces.RateLimitedError{Limit: limit, RetryAfter: result.RetryAfter}
+	}
+
+	// Record the use at most once per interval so busy keys don't write on every request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeyRepo.TouchApiKey(ctx, key.ID, now, now.Add(-apiKeyTouchInterval)); err != nil {
+			return dto.ApiKeyDTO{}, err
+		}
+		key.LastUsedAt = &now
+	}
+
+	return dto.FromApiKeyModel(key), nil
+}
+
+// generateApiKey returns a random lookup prefix and secret
+func generateApiKey() (string, string, error) {
+	b := make([]byte, 6+32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(b[:6]), hex.EncodeToString(b[6:]), nil
+}
+
+// parseApiKeyPrefix extracts the lookup prefix from a "bek_<prefix>_<secret>" key
+func parseApiKeyPrefix(plain string) (string, bool) {
+	rest, ok := strings.CutPrefix(plain, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != 12 || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
@@ -0,0 +1,307 @@
+package implementations
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/requests"
+	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/jwt"
+	"beautyessentials.com/internal/utils/tracing"
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// minJWTSecretLength is the shortest accepted HS256 signing secret
+const minJWTSecretLength = 32
+
+// AuthService implements the AuthService interface
+type AuthService struct {
+	userRepo         interfaces.UserRepository
+	refreshTokenRepo interfaces.RefreshTokenRepository
+	secret           []byte
+	issuer           string
+	accessTTL        time.Duration
+	refreshTTL       time.Duration
+	bcryptCost       int
+	// dummyHash is compared against when the email is unknown so timing doesn't reveal accounts
+	dummyHash []byte
+}
+
+// NewAuthService creates a new instance of AuthService
+func NewAuthService(
+	userRepo interfaces.UserRepository,
+	refreshTokenRepo interfaces.RefreshTokenRepository,
+	cfg *config.Config,
+) (serviceInterfaces.AuthService, error) {
+	authConfig := cfg.Auth()
+	if len(authConfig.JWTSecret) < minJWTSecretLength {
+		return nil, fmt.Errorf("JWT_SECRET must be at least %d characters", minJWTSecretLength)
+	}
+
+	cost := authConfig.BcryptCost
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), cost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		secret:           []byte(authConfig.JWTSecret),
+		issuer:           authConfig.Issuer,
+		accessTTL:        authConfig.AccessTTL,
+		refreshTTL:       authConfig.RefreshTTL,
+		bcryptCost:       cost,
+		dummyHash:        dummyHash,
+	}, nil
+}
+
+// Register creates a user account and signs it in
+func (s *AuthService) Register(ctx context.Context, request requests.RegisterRequest) (dto.AuthTokensDTO, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
+	email := normalizeEmail(request.Email)
+
+	// Emails are unique across accounts
+	_, err := s.userRepo.FindUserByEmail(ctx, email)
+	if err == nil {
+		return dto.AuthTokensDTO{}, serviceInterfaces.ErrEmailTaken
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.AuthTokensDTO{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), s.bcryptCost)
+	if err != nil {
+		return dto.AuthTokensDTO{}, err
+	}
+
+	user, err := s.userRepo.CreateUser(ctx, models.User{
+		Name:     strings.TrimSpace(request.Name),
+		Email:    email,
+		Password: string(hash),
+	})
+	if err != nil {
+		return dto.AuthTokensDTO{}, err
+	}
+
+	return s.issueTokens(ctx, user, ulid.Make().String())
+}
+
+// Login verifies the credentials and issues a new token pair
+func (s *AuthService) Login(ctx context.Context, request requests.LoginRequest) (dto.AuthTokensDTO, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	user, err := s.userRepo.FindUserByEmail(ctx, normalizeEmail(request.Email))
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.AuthTokensDTO{}, err
+		}
+		// Spend the same time as a real comparison
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(request.Password))
+		return dto.AuthTokensDTO{}, serviceInterfaces.ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+		return dto.AuthTokensDTO{}, serviceInterfaces.ErrInvalidCredentials
+	}
+
+	// Each login starts a new refresh token family
+	return s.issueTokens(ctx, user, ulid.Make().String())
+}
+
+// RefreshTokens exchanges a refresh token for a new token pair, revoking the old refresh token.
+// Presenting an already rotated token revokes its whole family, since it has likely leaked.
+func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string) (dto.AuthTokensDTO, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RefreshTokens")
+	defer span.End()
+
+	current, err := s.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return dto.AuthTokensDTO{}, err
+	}
+
+	if current.RevokedAt != nil {
+		if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			return dto.AuthTokensDTO{}, err
+		}
+		return dto.AuthTokensDTO{}, serviceInterfaces.ErrInvalidToken
+	}
+	if !time.Now().Before(current.ExpiresAt) {
+		return dto.AuthTokensDTO{}, serviceInterfaces.ErrInvalidToken
+	}
+
+	user, err := s.userRepo.FindUser(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.AuthTokensDTO{}, serviceInterfaces.ErrInvalidToken
+		}
+		return dto.AuthTokensDTO{}, err
+	}
+
+	accessToken, err := s.signAccessToken(user)
+	if err != nil {
+		return dto.AuthTokensDTO{}, err
+	}
+
+	value, replacement, err := s.newRefreshToken(user.ID, current.FamilyID)
+	if err != nil {
+		return dto.AuthTokensDTO{}, err
+	}
+
+	if _, err := s.refreshTokenRepo.RotateRefreshToken(ctx, current.ID, replacement); err != nil {
+		// Lost a race with another refresh of the same token; treat it as reuse
+		if errors.Is(err, interfaces.ErrRefreshTokenRevoked) {
+			if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				return dto.AuthTokensDTO{}, err
+			}
+			return dto.AuthTokensDTO{}, serviceInterfaces.ErrInvalidToken
+		}
+		return dto.AuthTokensDTO{}, err
+	}
+
+	return s.tokensDTO(user, accessToken, value), nil
+}
+
+// Logout revokes a refresh token
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer span.End()
+
+	token, err := s.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.RevokeRefreshToken(ctx, token.ID)
+}
+
+// LogoutAll revokes every refresh token of a user
+func (s *AuthService) LogoutAll(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.LogoutAll")
+	defer span.End()
+
+	return s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, userID)
+}
+
+// Authenticate verifies an access token and returns the user it was issued to
+func (s *AuthService) Authenticate(ctx context.Context, accessToken string) (dto.UserDTO, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Authenticate")
+	defer span.End()
+
+	claims, err := jwt.Parse(accessToken, s.secret, s.issuer, time.Now())
+	if err != nil {
+		return dto.UserDTO{}, serviceInterfaces.ErrInvalidToken
+	}
+
+	// Load the user so deleted accounts lose access immediately
+	user, err := s.userRepo.FindUser(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.UserDTO{}, serviceInterfaces.ErrInvalidToken
+		}
+		return dto.UserDTO{}, err
+	}
+
+	return dto.FromUserModel(user), nil
+}
+
+// issueTokens signs an access token and stores a new refresh token in the given family
+func (s *AuthService) issueTokens(ctx context.Context, user models.User, familyID string) (dto.AuthTokensDTO, error) {
+	accessToken, err := s.signAccessToken(user)
+	if err != nil {
+		return dto.AuthTokensDTO{}, err
+	}
+
+	value, refreshToken, err := s.newRefreshToken(user.ID, familyID)
+	if err != nil {
+		return dto.AuthTokensDTO{}, err
+	}
+	if _, err := s.refreshTokenRepo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return dto.AuthTokensDTO{}, err
+	}
+
+	return s.tokensDTO(user, accessToken, value), nil
+}
+
+// signAccessToken signs a short-lived access token for the user
+func (s *AuthService) signAccessToken(user models.User) (string, error) {
+	now := time.Now()
+	return jwt.Sign(jwt.Claims{
+		ID:        ulid.Make().String(),
+		Subject:   user.ID,
+		Issuer:    s.issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.accessTTL).Unix(),
+	}, s.secret)
+}
+
+// newRefreshToken generates an opaque refresh token and the record storing its hash
+func (s *AuthService) newRefreshToken(userID string, familyID string) (string, models.RefreshToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", models.RefreshToken{}, err
+	}
+	value := base64.RawURLEncoding.EncodeToString(b)
+
+	return value, models.RefreshToken{
+		ID:        ulid.Make().String(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(value),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}, nil
+}
+
+// findRefreshToken looks up a refresh token by its value
+func (s *AuthService) findRefreshToken(ctx context.Context, value string) (models.RefreshToken, error) {
+	token, err := s.refreshTokenRepo.FindRefreshTokenByHash(ctx, hashToken(value))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.RefreshToken{}, serviceInterfaces.ErrInvalidToken
+		}
+		return models.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+// tokensDTO assembles the token response
+func (s *AuthService) tokensDTO(user models.User, accessToken string, refreshToken string) dto.AuthTokensDTO {
+	return dto.AuthTokensDTO{
+		TokenType:        "Bearer",
+		AccessToken:      accessToken,
+		ExpiresIn:        int64(s.accessTTL.Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresIn: int64(s.refreshTTL.Seconds()),
+		User:             dto.FromUserModel(user),
+	}
+}
+
+// hashToken returns the hex SHA-256 of a refresh token value
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail lower-cases and trims an email so lookups are case-insensitive
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -2,28 +2,36 @@ package implementations
 
 import (
 	"context"
+	"errors"
 
 	"beautyessentials.com/internal/dto"
 	"beautyessentials.com/internal/models"
 	"beautyessentials.com/internal/repository/interfaces"
 	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
 	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/utils/cache"
+	"beautyessentials.com/internal/utils/tracing"
 )
 
 // BrandService implements the BrandService interface
 type BrandService struct {
 	brandRepo interfaces.BrandRepository
+	cache     *cache.Cache
 }
 
 // NewBrandService creates a new instance of BrandService
-func NewBrandService(brandRepo interfaces.BrandRepository) serviceInterfaces.BrandService {
+func NewBrandService(brandRepo interfaces.BrandRepository, queryCache *cache.Cache) serviceInterfaces.BrandService {
 	return &BrandService{
 		brandRepo: brandRepo,
+		cache:     queryCache,
 	}
 }
 
 // GetAllBrands retrieves all brands with filtering and pagination
 func (s *BrandService) GetAllBrands(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "BrandService.GetAllBrands")
+	defer span.End()
+
 	result, err := s.brandRepo.GetAllBrands(ctx, filters, appends)
 	if err != nil {
 		return nil, err
@@ -45,32 +53,45 @@ func (s *BrandService) GetAllBrands(ctx context.Context, filters map[string]inte
 
 // GetActiveBrands retrieves all active brands
 func (s *BrandService) GetActiveBrands(ctx context.Context) ([]dto.BrandDTO, error) {
-	brands, err := s.brandRepo.GetActiveBrands(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return dto.TransformBrandCollection(brands), nil
+	ctx, span := tracing.Start(ctx, "BrandService.GetActiveBrands")
+	defer span.End()
+
+	return cache.Remember(ctx, s.cache, brandsCacheNamespace, "active", func(ctx context.Context) ([]dto.BrandDTO, error) {
+		brands, err := s.brandRepo.GetActiveBrands(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return dto.TransformBrandCollection(brands), nil
+	})
 }
 
 // GetGroupedBrands retrieves brands grouped by first letter
-func (s *BrandService) GetGroupedBrands(ctx context.Context) (map[string][]dto.BrandDTO, error) {
-	groupedBrands, err := s.brandRepo.GetGroupedBrands(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	// Transform each group
-	result := make(map[string][]dto.BrandDTO)
-	for letter, brands := range groupedBrands {
-		result[letter] = dto.TransformBrandCollection(brands)
-	}
-
-	return result, nil
+func (s *BrandService) GetGroupedBrands(ctx context.Context, appends map[string]interface{}) (map[string][]dto.BrandDTO, error) {
+	ctx, span := tracing.Start(ctx, "BrandService.GetGroupedBrands")
+	defer span.End()
+
+	return cache.Remember(ctx, s.cache, brandsCacheNamespace, cacheKey("grouped", appends), func(ctx context.Context) (map[string][]dto.BrandDTO, error) {
+		groupedBrands, err := s.brandRepo.GetGroupedBrands(ctx, appends)
+		if err != nil {
+			return nil, err
+		}
+
+		// Transform each group
+		result := make(map[string][]dto.BrandDTO)
+		for letter, brands := range groupedBrands {
+			result[letter] = dto.TransformBrandCollection(brands)
+		}
+
+		return result, nil
+	})
 }
 
 // FindBrand finds a brand by ID
-func (s *BrandService) FindBrand(ctx context.Context, id string) (dto.BrandDTO, error) {
-	brand, err := s.brandRepo.FindBrand(ctx, id)
+func (s *BrandService) FindBrand(ctx context.Context, id string, appends map[string]interface{}) (dto.BrandDTO, error) {
+	ctx, span := tracing.Start(ctx, "BrandService.FindBrand")
+	defer span.End()
+
+	brand, err := s.brandRepo.FindBrand(ctx, id, appends)
 	if err != nil {
 		return dto.BrandDTO{}, err
 	}
@@ -80,6 +101,9 @@ func (s *BrandService) FindBrand(ctx context.Context, id string) (dto.BrandDTO,
 // CreateBrand creates a new brand
 // Then update the method signature
 func (s *BrandService) CreateBrand(ctx context.Context, request requests.BrandCreateRequest) (dto.BrandDTO, error) {
+	ctx, span := tracing.Start(ctx, "BrandService.CreateBrand")
+	defer span.End()
+
 	// Convert request to map for repository
 	data := map[string]interface{}{
 		"name": request.Name,
@@ -90,21 +114,58 @@ func (s *BrandService) CreateBrand(ctx context.Context, request requests.BrandCr
 	if err != nil {
 		return dto.BrandDTO{}, err
 	}
+	brandsCreatedTotal.Inc()
+	s.cache.Invalidate(ctx, brandsCacheNamespace)
 	
 	// Convert to DTO and return
 	return dto.FromModel(createdBrand), nil
 }
 
-// UpdateBrand updates an existing brand
-func (s *BrandService) UpdateBrand(ctx context.Context, data map[string]interface{}, id string) (dto.BrandDTO, error) {
-	brand, err := s.brandRepo.UpdateBrand(ctx, data, id)
+// UpdateBrand updates an existing brand based on version; zero skips the version check
+func (s *BrandService) UpdateBrand(ctx context.Context, data map[string]interface{}, id string, version int64) (dto.BrandDTO, error) {
+	ctx, span := tracing.Start(ctx, "BrandService.UpdateBrand")
+	defer span.End()
+
+	brand, err := s.brandRepo.UpdateBrand(ctx, data, id, version)
+	if errors.Is(err, interfaces.ErrVersionConflict) {
+		return dto.BrandDTO{}, s.versionConflict(ctx, id, err)
+	}
 	if err != nil {
 		return dto.BrandDTO{}, err
 	}
+	s.cache.Invalidate(ctx, brandsCacheNamespace)
 	return dto.FromModel(brand), nil
 }
 
 // DeleteBrand deletes a brand
 func (s *BrandService) DeleteBrand(ctx context.Context, id string) error {
-	return s.brandRepo.DeleteBrand(ctx, id)
+	ctx, span := tracing.Start(ctx, "BrandService.DeleteBrand")
+	defer span.End()
+
+	if err := s.brandRepo.DeleteBrand(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, brandsCacheNamespace)
+	return nil
+}
+
+// GetBrandsVersion returns the version of the brands table
+func (s *BrandService) GetBrandsVersion(ctx context.Context) (dto.VersionDTO, error) {
+	ctx, span := tracing.Start(ctx, "BrandService.GetBrandsVersion")
+	defer span.End()
+
+	version, err := s.brandRepo.GetVersion(ctx)
+	if err != nil {
+		return dto.VersionDTO{}, err
+	}
+	return dto.FromTableVersion(version), nil
+}
+
+// versionConflict reports a stale update along with the brand's current representation
+func (s *BrandService) versionConflict(ctx context.Context, id string, err error) error {
+	current, findErr := s.brandRepo.FindBrand(ctx, id, nil)
+	if findErr != nil {
+		return err
+	}
+	return &serviceInterfaces.VersionConflictError{Current: dto.FromModel(current), Version: current.Version}
 }
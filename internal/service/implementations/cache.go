@@ -0,0 +1,18 @@
+package implementations
+
+import "beautyessentials.com/internal/utils/fieldset"
+
+// Cache namespaces of service queries; writes invalidate every query of a namespace
+const (
+	brandsCacheNamespace     = "brands"
+	categoriesCacheNamespace = "categories"
+	mediaTagsCacheNamespace  = "media_tags"
+)
+
+// cacheKey qualifies key with the sparse fieldset requested in appends, which shapes the cached value
+func cacheKey(key string, appends map[string]interface{}) string {
+	if query, ok := appends["fieldset"].(fieldset.Query); ok && !query.IsZero() {
+		return key + "?" + query.Key()
+	}
+	return key
+}
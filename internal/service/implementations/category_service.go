@@ -2,29 +2,37 @@ package implementations
 
 import (
 	"context"
+	"errors"
 
 	"beautyessentials.com/internal/dto"
 	"beautyessentials.com/internal/models"
 	"beautyessentials.com/internal/repository/interfaces"
 	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
 	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/utils/cache"
+	"beautyessentials.com/internal/utils/tracing"
 	"github.com/oklog/ulid/v2"
 )
 
 // CategoryService implements the CategoryService interface
 type CategoryService struct {
 	categoryRepo interfaces.CategoryRepository
+	cache        *cache.Cache
 }
 
 // NewCategoryService creates a new instance of CategoryService
-func NewCategoryService(categoryRepo interfaces.CategoryRepository) serviceInterfaces.CategoryService {
+func NewCategoryService(categoryRepo interfaces.CategoryRepository, queryCache *cache.Cache) serviceInterfaces.CategoryService {
 	return &CategoryService{
 		categoryRepo: categoryRepo,
+		cache:        queryCache,
 	}
 }
 
 // GetAllCategories retrieves all categories with filtering and pagination
 func (s *CategoryService) GetAllCategories(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.GetAllCategories")
+	defer span.End()
+
 	result, err := s.categoryRepo.GetAllCategories(ctx, filters, appends)
 	if err != nil {
 		return nil, err
@@ -45,8 +53,11 @@ func (s *CategoryService) GetAllCategories(ctx context.Context, filters map[stri
 }
 
 // FindCategory finds a category by ID
-func (s *CategoryService) FindCategory(ctx context.Context, id string) (dto.CategoryDTO, error) {
-	category, err := s.categoryRepo.FindCategory(ctx, id)
+func (s *CategoryService) FindCategory(ctx context.Context, id string, appends map[string]interface{}) (dto.CategoryDTO, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.FindCategory")
+	defer span.End()
+
+	category, err := s.categoryRepo.FindCategory(ctx, id, appends)
 	if err != nil {
 		return dto.CategoryDTO{}, err
 	}
@@ -55,6 +66,9 @@ func (s *CategoryService) FindCategory(ctx context.Context, id string) (dto.Cate
 
 // CreateCategory creates a new category
 func (s *CategoryService) CreateCategory(ctx context.Context, request requests.CategoryCreateRequest) (dto.CategoryDTO, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.CreateCategory")
+	defer span.End()
+
 	// Convert request to data map
 	data := map[string]interface{}{
 		"name":        request.Name,
@@ -72,44 +86,88 @@ func (s *CategoryService) CreateCategory(ctx context.Context, request requests.C
 	if err != nil {
 		return dto.CategoryDTO{}, err
 	}
+	s.cache.Invalidate(ctx, categoriesCacheNamespace)
 	
 	return dto.FromCategoryModel(category), nil
 }
 
-// UpdateCategory updates an existing category
-func (s *CategoryService) UpdateCategory(ctx context.Context, data map[string]interface{}, id string) (dto.CategoryDTO, error) {
+// UpdateCategory updates an existing category based on version; zero skips the version check
+func (s *CategoryService) UpdateCategory(ctx context.Context, data map[string]interface{}, id string, version int64) (dto.CategoryDTO, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.UpdateCategory")
+	defer span.End()
+
 	// Add mediable_id if media_id is provided
 	if _, ok := data["media_id"]; ok {
 		data["mediable_id"] = ulid.Make().String() // Generate a new ULID for the mediable relation
 	}
 	
-	category, err := s.categoryRepo.UpdateCategory(ctx, data, id)
+	category, err := s.categoryRepo.UpdateCategory(ctx, data, id, version)
+	if errors.Is(err, interfaces.ErrVersionConflict) {
+		return dto.CategoryDTO{}, s.versionConflict(ctx, id, err)
+	}
 	if err != nil {
 		return dto.CategoryDTO{}, err
 	}
+	s.cache.Invalidate(ctx, categoriesCacheNamespace)
 	
 	return dto.FromCategoryModel(category), nil
 }
 
 // DeleteCategory deletes a category
 func (s *CategoryService) DeleteCategory(ctx context.Context, id string) error {
-	return s.categoryRepo.DeleteCategory(ctx, id)
+	ctx, span := tracing.Start(ctx, "CategoryService.DeleteCategory")
+	defer span.End()
+
+	if err := s.categoryRepo.DeleteCategory(ctx, id); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, categoriesCacheNamespace)
+	return nil
 }
 
 // GetActiveCategories retrieves all active categories
-func (s *CategoryService) GetActiveCategories(ctx context.Context) ([]dto.CategoryDTO, error) {
-	categories, err := s.categoryRepo.GetActiveCategories(ctx)
+func (s *CategoryService) GetActiveCategories(ctx context.Context, appends map[string]interface{}) ([]dto.CategoryDTO, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.GetActiveCategories")
+	defer span.End()
+
+	return cache.Remember(ctx, s.cache, categoriesCacheNamespace, cacheKey("active", appends), func(ctx context.Context) ([]dto.CategoryDTO, error) {
+		categories, err := s.categoryRepo.GetActiveCategories(ctx, appends)
+		if err != nil {
+			return nil, err
+		}
+		return dto.TransformCategoryCollection(categories), nil
+	})
+}
+
+// FindCategoryBySlug finds categories by slug
+func (s *CategoryService) FindCategoryBySlug(ctx context.Context, slug string, appends map[string]interface{}) ([]dto.CategoryDTO, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.FindCategoryBySlug")
+	defer span.End()
+
+	categories, err := s.categoryRepo.FindCategoryBySlug(ctx, slug, appends)
 	if err != nil {
 		return nil, err
 	}
 	return dto.TransformCategoryCollection(categories), nil
 }
 
-// FindCategoryBySlug finds categories by slug
-func (s *CategoryService) FindCategoryBySlug(ctx context.Context, slug string) ([]dto.CategoryDTO, error) {
-	categories, err := s.categoryRepo.FindCategoryBySlug(ctx, slug)
+// GetCategoriesVersion returns the version of the categories table and their media
+func (s *CategoryService) GetCategoriesVersion(ctx context.Context) (dto.VersionDTO, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.GetCategoriesVersion")
+	defer span.End()
+
+	version, err := s.categoryRepo.GetVersion(ctx)
 	if err != nil {
-		return nil, err
+		return dto.VersionDTO{}, err
 	}
-	return dto.TransformCategoryCollection(categories), nil
-}
\ No newline at end of file
+	return dto.FromTableVersion(version), nil
+}
+
+// versionConflict reports a stale update along with the category's current representation
+func (s *CategoryService) versionConflict(ctx context.Context, id string, err error) error {
+	current, findErr := s.categoryRepo.FindCategory(ctx, id, nil)
+	if findErr != nil {
+		return err
+	}
+	return &serviceInterfaces.VersionConflictError{Current: dto.FromCategoryModel(current), Version: current.Version}
+}
@@ -5,6 +5,7 @@ import (
 
 	"beautyessentials.com/internal/repository/interfaces"
 	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
 )
 
 // HealthServiceImpl implements the HealthService interface
@@ -21,6 +22,9 @@ func NewHealthService(healthRepo interfaces.HealthRepository) serviceInterfaces.
 
 // CheckHealth checks if the service is healthy
 func (s *HealthServiceImpl) CheckHealth(ctx context.Context) (bool, error) {
+	ctx, span := tracing.Start(ctx, "HealthServiceImpl.CheckHealth")
+	defer span.End()
+
 	// Call the repository layer
 	return s.healthRepo.CheckHealth(ctx)
 }
\ No newline at end of file
@@ -0,0 +1,117 @@
+package implementations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+)
+
+// idempotencyLockTimeout is how long a key stays claimed by a request that never
+// completed, e.g. because the instance handling it died
+const idempotencyLockTimeout = time.Minute
+
+// IdempotencyService implements the IdempotencyService interface
+type IdempotencyService struct {
+	idempotencyKeyRepo interfaces.IdempotencyKeyRepository
+	ttl                time.Duration
+	now                func() time.Time
+}
+
+// NewIdempotencyService creates a new instance of IdempotencyService
+func NewIdempotencyService(idempotencyKeyRepo interfaces.IdempotencyKeyRepository, cfg *config.Config) serviceInterfaces.IdempotencyService {
+	return &IdempotencyService{
+		idempotencyKeyRepo: idempotencyKeyRepo,
+		ttl:                cfg.Idempotency().TTL,
+		now:                time.Now,
+	}
+}
+
+// Begin claims key for a request, or returns the stored response when the key already completed
+func (s *IdempotencyService) Begin(ctx context.Context, scope string, key string, fingerprint string) (*dto.IdempotentResponseDTO, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Begin")
+	defer span.End()
+
+	// A second attempt follows clearing an expired or abandoned claim
+	for attempt := 0; attempt < 2; attempt++ {
+		now := s.now()
+		created, err := s.idempotencyKeyRepo.CreateIdempotencyKey(ctx, models.IdempotencyKey{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(s.ttl),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return nil, nil
+		}
+
+		existing, err := s.idempotencyKeyRepo.FindIdempotencyKey(ctx, scope, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Released between our insert and lookup
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		abandoned := existing.StatusCode == 0 && now.Sub(existing.CreatedAt) > idempotencyLockTimeout
+		if now.After(existing.ExpiresAt) || abandoned {
+			if err := s.idempotencyKeyRepo.DeleteIdempotencyKey(ctx, existing.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if existing.Fingerprint != fingerprint {
+			return nil, serviceInterfaces.ErrIdempotencyKeyReused
+		}
+		if existing.StatusCode == 0 {
+			return nil, serviceInterfaces.ErrIdempotencyKeyInProgress
+		}
+		response := dto.FromIdempotencyKeyModel(existing)
+		return &response, nil
+	}
+
+	return nil, serviceInterfaces.ErrIdempotencyKeyInProgress
+}
+
+// Complete stores the response of the request holding key
+func (s *IdempotencyService) Complete(ctx context.Context, scope string, key string, response dto.IdempotentResponseDTO) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Complete")
+	defer span.End()
+
+	return s.idempotencyKeyRepo.CompleteIdempotencyKey(ctx, scope, key, response.StatusCode, response.ContentType, response.Body)
+}
+
+// Release frees key after a failure so the request can be retried
+func (s *IdempotencyService) Release(ctx context.Context, scope string, key string) error {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Release")
+	defer span.End()
+
+	existing, err := s.idempotencyKeyRepo.FindIdempotencyKey(ctx, scope, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.idempotencyKeyRepo.DeleteIdempotencyKey(ctx, existing.ID)
+}
+
+// PurgeExpired deletes expired keys
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.PurgeExpired")
+	defer span.End()
+
+	return s.idempotencyKeyRepo.DeleteExpiredIdempotencyKeys(ctx, s.now())
+}
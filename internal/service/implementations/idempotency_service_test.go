@@ -0,0 +1,151 @@
+package implementations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"gorm.io/gorm"
+)
+
+// memoryIdempotencyKeyRepository keeps idempotency keys in memory, stamped by now
+type memoryIdempotencyKeyRepository struct {
+	interfaces.IdempotencyKeyRepository
+	keys map[string]models.IdempotencyKey
+	now  func() time.Time
+}
+
+func (r *memoryIdempotencyKeyRepository) CreateIdempotencyKey(ctx context.Context, key models.IdempotencyKey) (bool, error) {
+	if _, ok := r.keys[key.Scope+" "+key.Key]; ok {
+		return false, nil
+	}
+	key.ID = key.Scope + " " + key.Key
+	key.CreatedAt = r.now()
+	r.keys[key.ID] = key
+	return true, nil
+}
+
+func (r *memoryIdempotencyKeyRepository) FindIdempotencyKey(ctx context.Context, scope string, key string) (models.IdempotencyKey, error) {
+	existing, ok := r.keys[scope+" "+key]
+	if !ok {
+		return models.IdempotencyKey{}, gorm.ErrRecordNotFound
+	}
+	return existing, nil
+}
+
+func (r *memoryIdempotencyKeyRepository) CompleteIdempotencyKey(ctx context.Context, scope string, key string, statusCode int, contentType string, body []byte) error {
+	existing := r.keys[scope+" "+key]
+	existing.StatusCode, existing.ContentType, existing.Body = statusCode, contentType, body
+	r.keys[scope+" "+key] = existing
+	return nil
+}
+
+func (r *memoryIdempotencyKeyRepository) DeleteIdempotencyKey(ctx context.Context, id string) error {
+	delete(r.keys, id)
+	return nil
+}
+
+func TestIdempotencyServiceBegin(t *testing.T) {
+	const scope, key = "user:1 POST /api/v1/media", "key-1"
+	created := dto.IdempotentResponseDTO{StatusCode: 201, ContentType: "application/json", Body: []byte(`{"id":"1"}`)}
+
+	tests := []struct {
+		name        string
+		prepare     func(s *IdempotencyService)
+		elapsed     time.Duration
+		fingerprint string
+		wantReplay  bool
+		wantErr     error
+	}{
+		{
+			name:        "new key is claimed",
+			fingerprint: "a",
+		},
+		{
+			name: "completed key is replayed",
+			prepare: func(s *IdempotencyService) {
+				_, _ = s.Begin(context.Background(), scope, key, "a")
+				_ = s.Complete(context.Background(), scope, key, created)
+			},
+			fingerprint: "a",
+			wantReplay:  true,
+		},
+		{
+			name: "completed key with another body is rejected",
+			prepare: func(s *IdempotencyService) {
+				_, _ = s.Begin(context.Background(), scope, key, "a")
+				_ = s.Complete(context.Background(), scope, key, created)
+			},
+			fingerprint: "b",
+			wantErr:     serviceInterfaces.ErrIdempotencyKeyReused,
+		},
+		{
+			name: "claimed key is in progress",
+			prepare: func(s *IdempotencyService) {
+				_, _ = s.Begin(context.Background(), scope, key, "a")
+			},
+			fingerprint: "a",
+			wantErr:     serviceInterfaces.ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "released key is claimed again",
+			prepare: func(s *IdempotencyService) {
+				_, _ = s.Begin(context.Background(), scope, key, "a")
+				_ = s.Release(context.Background(), scope, key)
+			},
+			fingerprint: "b",
+		},
+		{
+			name: "abandoned claim is taken over",
+			prepare: func(s *IdempotencyService) {
+				_, _ = s.Begin(context.Background(), scope, key, "a")
+			},
+			elapsed:     idempotencyLockTimeout + time.Second,
+			fingerprint: "a",
+		},
+		{
+			name: "expired response is not replayed",
+			prepare: func(s *IdempotencyService) {
+				_, _ = s.Begin(context.Background(), scope, key, "a")
+				_ = s.Complete(context.Background(), scope, key, created)
+			},
+			elapsed:     25 * time.Hour,
+			fingerprint: "b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			clock := func() time.Time { return now }
+			service := &IdempotencyService{
+				idempotencyKeyRepo: &memoryIdempotencyKeyRepository{keys: make(map[string]models.IdempotencyKey), now: clock},
+				ttl:                24 * time.Hour,
+				now:                clock,
+			}
+			if tt.prepare != nil {
+				tt.prepare(service)
+			}
+			now = now.Add(tt.elapsed)
+
+			stored, err := service.Begin(context.Background(), scope, key, tt.fingerprint)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Begin() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantReplay {
+				if stored == nil || stored.StatusCode != created.StatusCode || string(stored.Body) != string(created.Body) {
+					t.Errorf("Begin() = %+v, want the stored response", stored)
+				}
+				return
+			}
+			if stored != nil {
+				t.Errorf("Begin() replayed %+v, want the key claimed", stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package implementations
+
+import (
+	"context"
+	"strings"
+
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/service/external"
+	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils"
+	"beautyessentials.com/internal/utils/tracing"
+)
+
+// MediaFolderService implements the MediaFolderService interface
+type MediaFolderService struct {
+	folderRepo      interfaces.MediaFolderRepository
+	imageKitService *external.ImageKitService
+}
+
+// NewMediaFolderService creates a new instance of MediaFolderService
+func NewMediaFolderService(folderRepo interfaces.MediaFolderRepository, imageKitService *external.ImageKitService) serviceInterfaces.MediaFolderService {
+	return &MediaFolderService{
+		folderRepo:      folderRepo,
+		imageKitService: imageKitService,
+	}
+}
+
+// GetAllFolders retrieves media folders
+func (s *MediaFolderService) GetAllFolders(ctx context.Context, filters map[string]interface{}) ([]dto.MediaFolderDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaFolderService.GetAllFolders")
+	defer span.End()
+
+	folders, err := s.folderRepo.GetAllFolders(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	return dto.TransformMediaFolderCollection(folders), nil
+}
+
+// CreateFolder creates a folder and mirrors it to the storage provider
+func (s *MediaFolderService) CreateFolder(ctx context.Context, request requests.MediaFolderCreateRequest) (dto.MediaFolderDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaFolderService.CreateFolder")
+	defer span.End()
+
+	// Resolve the parent path
+	parentPath := ""
+	if request.ParentID != "" {
+		parent, err := s.folderRepo.FindFolder(ctx, request.ParentID)
+		if err != nil {
+			return dto.MediaFolderDTO{}, err
+		}
+		parentPath = parent.Path
+	}
+
+	// Folder paths use slugs so they are valid on the storage provider
+	segment := utils.GenerateSlug(request.Name)
+	path := parentPath + "/" + segment
+
+	// Mirror the folder remotely before recording it
+	if err := s.imageKitService.CreateFolder(ctx, segment, parentPath); err != nil {
+		return dto.MediaFolderDTO{}, err
+	}
+
+	data := map[string]interface{}{
+		"name":      strings.TrimSpace(request.Name),
+		"path":      path,
+		"parent_id": request.ParentID,
+	}
+
+	folder, err := s.folderRepo.CreateFolder(ctx, data)
+	if err != nil {
+		return dto.MediaFolderDTO{}, err
+	}
+
+	return dto.FromMediaFolderModel(folder), nil
+}
+
+// DeleteFolder deletes an empty folder locally and on the storage provider
+func (s *MediaFolderService) DeleteFolder(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "MediaFolderService.DeleteFolder")
+	defer span.End()
+
+	folder, err := s.folderRepo.FindFolder(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Only empty folders may be deleted so no media is orphaned
+	empty, err := s.folderRepo.IsFolderEmpty(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return serviceInterfaces.ErrFolderNotEmpty
+	}
+
+	if err := s.imageKitService.DeleteFolder(ctx, folder.Path); err != nil {
+		return err
+	}
+
+	return s.folderRepo.DeleteFolder(ctx, id)
+}
@@ -1,7 +1,16 @@
 package implementations
 
 import (
+	"cmp"
 	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
 
 	"beautyessentials.com/internal/dto"
 	"beautyessentials.com/internal/models"
@@ -9,24 +18,45 @@ import (
 	"beautyessentials.com/internal/requests"
 	"beautyessentials.com/internal/service/external"
 	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/cache"
+	"beautyessentials.com/internal/utils/imagehash"
+	"beautyessentials.com/internal/utils/logging"
+	"beautyessentials.com/internal/utils/tracing"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // MediaService implements the MediaService interface
 type MediaService struct {
 	mediaRepo       interfaces.MediaRepository
+	folderRepo      interfaces.MediaFolderRepository
 	imageKitService *external.ImageKitService
+	cache           *cache.Cache
+	logger          *zap.Logger
 }
 
 // NewMediaService creates a new instance of MediaService
-func NewMediaService(mediaRepo interfaces.MediaRepository, imageKitService *external.ImageKitService) serviceInterfaces.MediaService {
+func NewMediaService(
+	mediaRepo interfaces.MediaRepository,
+	folderRepo interfaces.MediaFolderRepository,
+	imageKitService *external.ImageKitService,
+	queryCache *cache.Cache,
+	logger *zap.Logger,
+) serviceInterfaces.MediaService {
 	return &MediaService{
 		mediaRepo:       mediaRepo,
+		folderRepo:      folderRepo,
 		imageKitService: imageKitService,
+		cache:           queryCache,
+		logger:          logger,
 	}
 }
 
 // GetAllMedia retrieves all media with filtering and pagination
 func (s *MediaService) GetAllMedia(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.GetAllMedia")
+	defer span.End()
+
 	result, err := s.mediaRepo.GetAllMedia(ctx, filters, appends)
 	if err != nil {
 		return nil, err
@@ -48,6 +78,9 @@ func (s *MediaService) GetAllMedia(ctx context.Context, filters map[string]inter
 
 // FindMedia finds a media by ID
 func (s *MediaService) FindMedia(ctx context.Context, id string) (dto.MediaDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.FindMedia")
+	defer span.End()
+
 	media, err := s.mediaRepo.FindMedia(ctx, id)
 	if err != nil {
 		return dto.MediaDTO{}, err
@@ -57,11 +90,22 @@ func (s *MediaService) FindMedia(ctx context.Context, id string) (dto.MediaDTO,
 
 // CreateMedia creates a new media
 func (s *MediaService) CreateMedia(ctx context.Context, request requests.MediaCreateRequest) (dto.MediaDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.CreateMedia")
+	defer span.End()
+
 	// Convert request to data map
 	data := map[string]interface{}{
 		"file_id":   request.FileID,
 		"url":       request.URL,
 		"thumb_url": request.ThumbURL,
+		"folder_id": request.FolderID,
+	}
+
+	// Make sure the target folder exists
+	if request.FolderID != "" {
+		if _, err := s.folderRepo.FindFolder(ctx, request.FolderID); err != nil {
+			return dto.MediaDTO{}, err
+		}
 	}
 
 	media, err := s.mediaRepo.CreateMedia(ctx, data)
@@ -72,21 +116,501 @@ func (s *MediaService) CreateMedia(ctx context.Context, request requests.MediaCr
 	return dto.FromMediaModel(media), nil
 }
 
-// DeleteMedia deletes a media
-func (s *MediaService) DeleteMedia(ctx context.Context, id string) error {
-	// Find the media first to get the file ID
-	media, err := s.mediaRepo.FindMedia(ctx, id)
+// DeleteMedia deletes a media, refusing while it is attached unless force is set
+func (s *MediaService) DeleteMedia(ctx context.Context, id string, force bool) error {
+	ctx, span := tracing.Start(ctx, "MediaService.DeleteMedia")
+	defer span.End()
+
+	// Usages are checked and the media deleted in one transaction
+	media, usages, err := s.mediaRepo.DeleteMedia(ctx, id, force)
+	if errors.Is(err, interfaces.ErrMediaInUse) {
+		return &serviceInterfaces.MediaInUseError{Usages: dto.TransformMediaUsageCollection(usages)}
+	}
 	if err != nil {
 		return err
 	}
+	if len(usages) > 0 {
+		// Detaching changes the media of categories; brands embed none
+		s.cache.Invalidate(ctx, categoriesCacheNamespace)
+	}
 
-	// Delete the file from ImageKit if file_id exists
+	// Only drop the file once the record is gone; a leftover file is merely orphaned
 	if media.FileID != "" {
-		if err := s.imageKitService.DeleteFile(media.FileID); err != nil {
+		if err := s.imageKitService.DeleteFile(ctx, media.FileID); err != nil {
+			logging.For(ctx, s.logger).Warn("failed to delete media file",
+				zap.String("media_id", media.ID),
+				zap.String("file_id", media.FileID),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// GetMediaUsages retrieves the entities a media is attached to
+func (s *MediaService) GetMediaUsages(ctx context.Context, id string) ([]dto.MediaUsageDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.GetMediaUsages")
+	defer span.End()
+
+	// Make sure the media exists
+	if _, err := s.mediaRepo.FindMedia(ctx, id); err != nil {
+		return nil, err
+	}
+
+	usages, err := s.mediaRepo.GetMediaUsages(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.TransformMediaUsageCollection(usages), nil
+}
+
+// GetUploadAuth issues short-lived credentials for a direct browser upload into a
+// folder, or the library root when folderID is empty
+func (s *MediaService) GetUploadAuth(ctx context.Context, folderID string) (dto.MediaUploadAuthDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.GetUploadAuth")
+	defer span.End()
+
+	folderPath, err := s.folderPath(ctx, folderID)
+	if err != nil {
+		return dto.MediaUploadAuthDTO{}, err
+	}
+
+	params, err := s.imageKitService.GetAuthenticationParameters()
+	if err != nil {
+		return dto.MediaUploadAuthDTO{}, err
+	}
+
+	return dto.MediaUploadAuthDTO{
+		Token:       params.Token,
+		Expire:      params.Expire,
+		Signature:   params.Signature,
+		PublicKey:   s.imageKitService.PublicKey(),
+		URLEndpoint: s.imageKitService.URLEndpoint(),
+		Folder:      folderPath,
+		Receipt:     s.imageKitService.UploadReceipt(params.Token, params.Expire, folderID),
+	}, nil
+}
+
+// uploadClockSkew is the leeway between our clock and ImageKit's when checking upload times
+const uploadClockSkew = time.Minute
+
+// ConfirmUpload registers a file that was uploaded directly to ImageKit with
+// credentials issued by GetUploadAuth: the file must have been uploaded while the
+// credentials were valid, into the folder they were issued for.
+// When the content duplicates an existing media, the new remote file is removed
+// and the existing media is returned instead.
+func (s *MediaService) ConfirmUpload(ctx context.Context, request requests.MediaConfirmUploadRequest) (dto.MediaDTO, bool, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.ConfirmUpload")
+	defer span.End()
+
+	// Only credentials we issued for this folder carry a matching receipt
+	receipt := s.imageKitService.UploadReceipt(request.Token, request.Expire, request.FolderID)
+	if !hmac.Equal([]byte(receipt), []byte(strings.ToLower(request.Receipt))) {
+		return dto.MediaDTO{}, false, serviceInterfaces.ErrUploadNotAuthorized
+	}
+
+	// Return the existing record if this file was already confirmed
+	existing, err := s.mediaRepo.FindMediaByFileID(ctx, request.FileID)
+	if err == nil {
+		return dto.FromMediaModel(existing), true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.MediaDTO{}, false, err
+	}
+
+	// Make sure the target folder exists
+	folderPath, err := s.folderPath(ctx, request.FolderID)
+	if err != nil {
+		return dto.MediaDTO{}, false, err
+	}
+
+	// Verify the file actually exists remotely
+	details, err := s.imageKitService.GetFileDetails(ctx, request.FileID)
+	if external.IsNotFound(err) {
+		return dto.MediaDTO{}, false, serviceInterfaces.ErrUploadNotFound.Wrap(err)
+	}
+	if err != nil {
+		return dto.MediaDTO{}, false, err
+	}
+
+	// The file must have been uploaded with the credentials, into their folder
+	expire := time.Unix(request.Expire, 0)
+	issued := expire.Add(-s.imageKitService.UploadTTL())
+	if details.CreatedAt.Before(issued.Add(-uploadClockSkew)) || details.CreatedAt.After(expire.Add(uploadClockSkew)) {
+		return dto.MediaDTO{}, false, serviceInterfaces.ErrUploadMismatch.WithDetail("The file was uploaded outside the validity of the credentials")
+	}
+	if path.Dir(details.FilePath) != cmp.Or(folderPath, "/") {
+		return dto.MediaDTO{}, false, serviceInterfaces.ErrUploadMismatch.WithDetail("The file was not uploaded into the folder of the credentials")
+	}
+
+	data := map[string]interface{}{
+		"file_id":   details.FileID,
+		"url":       details.URL,
+		"thumb_url": details.ThumbnailURL,
+		"folder_id": request.FolderID,
+	}
+
+	// Fingerprint the uploaded content; files we cannot fetch are registered without one
+	if content, err := s.imageKitService.FetchFile(ctx, details.URL); err == nil {
+		contentHash, perceptualHash := fingerprint(content)
+
+		data["content_hash"] = contentHash
+		data["perceptual_hash"] = perceptualHash
+	}
+
+	media, err := s.mediaRepo.CreateMedia(ctx, data)
+	if errors.Is(err, interfaces.ErrDuplicateContent) {
+		return s.reuseDuplicate(ctx, details.FileID, data["content_hash"].(string))
+	}
+	if err != nil {
+		return dto.MediaDTO{}, false, err
+	}
+	mediaUploadedTotal.Inc("direct")
+
+	return dto.FromMediaModel(media), false, nil
+}
+
+// UploadMedia uploads a file to ImageKit and registers it, returning the existing
+// media instead when identical content was uploaded before
+func (s *MediaService) UploadMedia(ctx context.Context, request requests.MediaUploadRequest) (dto.MediaDTO, bool, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.UploadMedia")
+	defer span.End()
+
+	// Make sure the target folder exists; the file is stored under its path
+	folderPath, err := s.folderPath(ctx, request.FolderID)
+	if err != nil {
+		return dto.MediaDTO{}, false, err
+	}
+
+	src, err := request.File.Open()
+	if err != nil {
+		return dto.MediaDTO{}, false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	// Upload the file to ImageKit, fingerprinting it on the way
+	hasher := imagehash.NewHasher()
+	uploaded, err := s.imageKitService.UploadFile(ctx, io.TeeReader(src, hasher), request.File.Filename, folderPath)
+	contentHash, perceptualHash := hasher.Sum()
+	if err != nil {
+		return dto.MediaDTO{}, false, err
+	}
+
+	data := map[string]interface{}{
+		"file_id":         uploaded["fileId"],
+		"url":             uploaded["url"],
+		"thumb_url":       uploaded["thumbUrl"],
+		"folder_id":       request.FolderID,
+		"content_hash":    contentHash,
+		"perceptual_hash": perceptualHash,
+	}
+
+	media, err := s.mediaRepo.CreateMedia(ctx, data)
+	if errors.Is(err, interfaces.ErrDuplicateContent) {
+		return s.reuseDuplicate(ctx, uploaded["fileId"].(string), contentHash)
+	}
+	if err != nil {
+		return dto.MediaDTO{}, false, err
+	}
+	mediaUploadedTotal.Inc("server")
+
+	return dto.FromMediaModel(media), false, nil
+}
+
+// reuseDuplicate drops a file uploaded with content that is already stored and
+// returns the media holding that content instead
+func (s *MediaService) reuseDuplicate(ctx context.Context, fileID string, contentHash string) (dto.MediaDTO, bool, error) {
+	if err := s.imageKitService.DeleteFile(ctx, fileID); err != nil {
+		logging.For(ctx, s.logger).Warn("failed to delete duplicate file",
+			zap.String("file_id", fileID),
+			zap.Error(err),
+		)
+	}
+
+	duplicate, err := s.mediaRepo.FindMediaByContentHash(ctx, contentHash)
+	if err != nil {
+		return dto.MediaDTO{}, false, err
+	}
+	return dto.FromMediaModel(duplicate), true, nil
+}
+
+// GetDuplicateReport groups media whose perceptual hashes are within the threshold
+func (s *MediaService) GetDuplicateReport(ctx context.Context, threshold int) ([]dto.MediaDuplicateGroupDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.GetDuplicateReport")
+	defer span.End()
+
+	counts, err := s.mediaRepo.GetPerceptualHashCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(counts))
+	for hash := range counts {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	// Union every pair of distinct hashes that look alike
+	parent := make([]int, len(hashes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	maxDistance := make(map[int]int)
+	for _, pair := range imagehash.SimilarPairs(hashes, threshold) {
+		root := find(pair.A)
+		other := find(pair.B)
+		if root != other {
+			parent[other] = root
+			maxDistance[root] = max(maxDistance[root], maxDistance[other])
+			delete(maxDistance, other)
+		}
+		maxDistance[root] = max(maxDistance[root], pair.Distance)
+	}
+
+	// Keep the groups of two or more media: several alike hashes, or one hash shared
+	sizes := make(map[int]int64)
+	for i, hash := range hashes {
+		sizes[find(i)] += counts[hash]
+	}
+	groupOf := make(map[string]int)
+	var duplicated []string
+	for i, hash := range hashes {
+		if root := find(i); sizes[root] >= 2 {
+			groupOf[hash] = root
+			duplicated = append(duplicated, hash)
+		}
+	}
+
+	groups := make([]dto.MediaDuplicateGroupDTO, 0)
+	if len(duplicated) == 0 {
+		return groups, nil
+	}
+	media, err := s.mediaRepo.FindMediaByPerceptualHashes(ctx, duplicated)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the members of each group, in upload order
+	members := make(map[int][]models.Media)
+	var roots []int
+	for _, item := range media {
+		root, ok := groupOf[item.PerceptualHash]
+		if !ok {
+			continue
+		}
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], item)
+	}
+
+	for _, root := range roots {
+		if len(members[root]) < 2 {
+			continue
+		}
+		groups = append(groups, dto.MediaDuplicateGroupDTO{
+			MaxDistance: maxDistance[root],
+			Media:       dto.TransformMediaCollection(members[root]),
+		})
+	}
+
+	return groups, nil
+}
+
+// SyncRemoteMedia imports files that exist in ImageKit but have no media record.
+// With dryRun set, nothing is written and the report lists what would be imported.
+func (s *MediaService) SyncRemoteMedia(ctx context.Context, dryRun bool) (dto.MediaSyncReportDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.SyncRemoteMedia")
+	defer span.End()
+
+	report := dto.MediaSyncReportDTO{
+		DryRun: dryRun,
+		Media:  make([]dto.MediaDTO, 0),
+		Errors: make([]dto.MediaSyncErrorDTO, 0),
+	}
+
+	for skip := 0; ; skip += external.ListFilesPageSize {
+		files, err := s.imageKitService.ListFiles(ctx, skip, external.ListFilesPageSize)
+		if err != nil {
+			return report, err
+		}
+
+		for _, file := range files {
+			report.Scanned++
+
+			// Skip files that already have a media record
+			_, err := s.mediaRepo.FindMediaByFileID(ctx, file.FileID)
+			if err == nil {
+				report.Skipped++
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				report.Failed++
+				report.Errors = append(report.Errors, dto.MediaSyncErrorDTO{FileID: file.FileID, Message: err.Error()})
+				continue
+			}
+
+			if dryRun {
+				report.Imported++
+				report.Media = append(report.Media, dto.FromMediaModel(models.Media{
+					FileID:   file.FileID,
+					URL:      file.URL,
+					ThumbURL: file.ThumbnailURL,
+				}))
+				continue
+			}
+
+			media, err := s.mediaRepo.CreateMedia(ctx, map[string]interface{}{
+				"file_id":   file.FileID,
+				"url":       file.URL,
+				"thumb_url": file.ThumbnailURL,
+			})
+			if err != nil {
+				report.Failed++
+				report.Errors = append(report.Errors, dto.MediaSyncErrorDTO{FileID: file.FileID, Message: err.Error()})
+				continue
+			}
+
+			report.Imported++
+			report.Media = append(report.Media, dto.FromMediaModel(media))
+		}
+
+		// A short page is the last one
+		if len(files) < external.ListFilesPageSize {
+			return report, nil
+		}
+	}
+}
+
+// fingerprint computes the content hash and, for decodable images, the perceptual hash
+func fingerprint(content []byte) (string, string) {
+	perceptualHash, err := imagehash.PerceptualHash(content)
+	if err != nil {
+		perceptualHash = ""
+	}
+	return imagehash.ContentHash(content), perceptualHash
+}
+
+// folderPath returns the storage path of a folder, or "" for the library root
+func (s *MediaService) folderPath(ctx context.Context, folderID string) (string, error) {
+	if folderID == "" {
+		return "", nil
+	}
+	folder, err := s.folderRepo.FindFolder(ctx, folderID)
+	if err != nil {
+		return "", err
+	}
+	return folder.Path, nil
+}
+
+// MoveMedia moves media into a folder, or to the library root when no folder is
+// given. Files are moved in storage first; when a move fails, the media moved so
+// far are still recorded so the records keep matching storage.
+func (s *MediaService) MoveMedia(ctx context.Context, request requests.MediaMoveRequest) error {
+	ctx, span := tracing.Start(ctx, "MediaService.MoveMedia")
+	defer span.End()
+
+	var folderID *string
+	destination := "/"
+	if request.FolderID != "" {
+		folder, err := s.folderRepo.FindFolder(ctx, request.FolderID)
+		if err != nil {
 			return err
 		}
+		folderID = &folder.ID
+		destination = folder.Path
+	}
+
+	media, err := s.mediaRepo.FindMediaByIDs(ctx, request.MediaIDs)
+	if err != nil {
+		return err
+	}
+
+	// ImageKit has no batch move, so files are moved one call each
+	moved := make([]models.Media, 0, len(media))
+	var moveErr error
+	for _, m := range media {
+		if m.FileID != "" {
+			details, err := s.moveFile(ctx, m.FileID, destination)
+			if err != nil {
+				moveErr = err
+				break
+			}
+			m.URL, m.ThumbURL = details.URL, details.ThumbnailURL
+		}
+		moved = append(moved, m)
+	}
+
+	if err := s.mediaRepo.MoveMedia(ctx, moved, folderID); err != nil {
+		return err
+	}
+	if len(moved) > 0 {
+		// Moving changes the URLs of the media embedded in categories
+		s.cache.Invalidate(ctx, categoriesCacheNamespace)
 	}
+	return moveErr
+}
+
+// moveFile moves a file into the folder at destination unless it is already there,
+// returning its details after the move
+func (s *MediaService) moveFile(ctx context.Context, fileID string, destination string) (*external.ImageKitFileDetails, error) {
+	details, err := s.imageKitService.GetFileDetails(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if path.Dir(details.FilePath) == destination {
+		return details, nil
+	}
+
+	if err := s.imageKitService.MoveFile(ctx, details.FilePath, destination); err != nil {
+		return nil, err
+	}
+	return s.imageKitService.GetFileDetails(ctx, fileID)
+}
+
+// TagMedia applies tags to media in bulk
+func (s *MediaService) TagMedia(ctx context.Context, request requests.MediaTagRequest) error {
+	ctx, span := tracing.Start(ctx, "MediaService.TagMedia")
+	defer span.End()
 
-	// Delete the media record from the database
-	return s.mediaRepo.DeleteMedia(ctx, id)
+	if err := s.mediaRepo.TagMedia(ctx, request.MediaIDs, request.Tags); err != nil {
+		return err
+	}
+	// Categories embed their media along with its tags
+	s.cache.Invalidate(ctx, mediaTagsCacheNamespace, categoriesCacheNamespace)
+	return nil
+}
+
+// UntagMedia removes tags from media in bulk
+func (s *MediaService) UntagMedia(ctx context.Context, request requests.MediaTagRequest) error {
+	ctx, span := tracing.Start(ctx, "MediaService.UntagMedia")
+	defer span.End()
+
+	if err := s.mediaRepo.UntagMedia(ctx, request.MediaIDs, request.Tags); err != nil {
+		return err
+	}
+	// Categories embed their media along with its tags
+	s.cache.Invalidate(ctx, mediaTagsCacheNamespace, categoriesCacheNamespace)
+	return nil
+}
+
+// GetAllTags retrieves all media tags
+func (s *MediaService) GetAllTags(ctx context.Context) ([]dto.MediaTagDTO, error) {
+	ctx, span := tracing.Start(ctx, "MediaService.GetAllTags")
+	defer span.End()
+
+	return cache.Remember(ctx, s.cache, mediaTagsCacheNamespace, "all", func(ctx context.Context) ([]dto.MediaTagDTO, error) {
+		tags, err := s.mediaRepo.GetAllTags(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return dto.TransformMediaTagCollection(tags), nil
+	})
 }
@@ -0,0 +1,428 @@
+package implementations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"beautyessentials.com/internal/config"
+	"beautyessentials.com/internal/domain"
+	"beautyessentials.com/internal/emulator/imagekit"
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/requests"
+	"beautyessentials.com/internal/service/external"
+	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/cache"
+	"beautyessentials.com/internal/utils/imagehash"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	testPrivateKey = "private_test"
+	testFolderID   = "01J00000000000000000FOLDER"
+)
+
+// fakeMediaRepository starts empty and keeps the media created through it
+type fakeMediaRepository struct {
+	interfaces.MediaRepository
+	created []map[string]interface{}
+}
+
+func (r *fakeMediaRepository) FindMediaByFileID(ctx context.Context, fileID string) (models.Media, error) {
+	return models.Media{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeMediaRepository) FindMediaByContentHash(ctx context.Context, hash string) (models.Media, error) {
+	return models.Media{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeMediaRepository) CreateMedia(ctx context.Context, data map[string]interface{}) (models.Media, error) {
+	r.created = append(r.created, data)
+	return models.Media{ID: "01J0000000000000000000MED1", FileID: data["file_id"].(string)}, nil
+}
+
+func (r *fakeMediaRepository) FindMediaByIDs(ctx context.Context, ids []string) ([]models.Media, error) {
+	media := make([]models.Media, len(ids))
+	for i, id := range ids {
+		media[i] = models.Media{ID: id}
+	}
+	return media, nil
+}
+
+func (r *fakeMediaRepository) MoveMedia(ctx context.Context, media []models.Media, folderID *string) error {
+	return nil
+}
+
+func (r *fakeMediaRepository) TagMedia(ctx context.Context, ids []string, tags []string) error {
+	return nil
+}
+
+func (r *fakeMediaRepository) UntagMedia(ctx context.Context, ids []string, tags []string) error {
+	return nil
+}
+
+func (r *fakeMediaRepository) DeleteMedia(ctx context.Context, id string, force bool) (models.Media, []models.Mediable, error) {
+	return models.Media{ID: id}, []models.Mediable{{MediaID: id, MediableType: "categories"}}, nil
+}
+
+// duplicateMediaRepository already stores the content of every media created through it
+type duplicateMediaRepository struct {
+	fakeMediaRepository
+	existing models.Media
+}
+
+func (r *duplicateMediaRepository) CreateMedia(ctx context.Context, data map[string]interface{}) (models.Media, error) {
+	r.created = append(r.created, data)
+	return models.Media{}, interfaces.ErrDuplicateContent
+}
+
+func (r *duplicateMediaRepository) FindMediaByContentHash(ctx context.Context, hash string) (models.Media, error) {
+	return r.existing, nil
+}
+
+// hashedMediaRepository stores media with perceptual hashes
+type hashedMediaRepository struct {
+	fakeMediaRepository
+	media []models.Media
+}
+
+func (r *hashedMediaRepository) GetPerceptualHashCounts(ctx context.Context) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, item := range r.media {
+		counts[item.PerceptualHash]++
+	}
+	return counts, nil
+}
+
+func (r *hashedMediaRepository) FindMediaByPerceptualHashes(ctx context.Context, hashes []string) ([]models.Media, error) {
+	var media []models.Media
+	for _, item := range r.media {
+		if slices.Contains(hashes, item.PerceptualHash) {
+			media = append(media, item)
+		}
+	}
+	return media, nil
+}
+
+// fakeFolderRepository holds a single folder
+type fakeFolderRepository struct {
+	interfaces.MediaFolderRepository
+	folder models.MediaFolder
+}
+
+func (r *fakeFolderRepository) FindFolder(ctx context.Context, id string) (models.MediaFolder, error) {
+	if id != r.folder.ID {
+		return models.MediaFolder{}, gorm.ErrRecordNotFound
+	}
+	return r.folder, nil
+}
+
+// newTestImageKit returns an ImageKit client for server that does not wait between retries
+func newTestImageKit(server *httptest.Server) *external.ImageKitService {
+	return external.NewImageKitService(&config.Config{
+		ImageKitPrivateKey:     testPrivateKey,
+		ImageKitURLEndpoint:    server.URL + "/files",
+		ImageKitUploadURL:      server.URL,
+		ImageKitAPIURL:         server.URL,
+		ImageKitMaxRetries:     1,
+		ImageKitRetryBaseDelay: time.Millisecond,
+		ImageKitRetryMaxDelay:  time.Millisecond,
+	}, zap.NewNop())
+}
+
+// uploadFile uploads content into folder and returns the file ID
+func uploadFile(t *testing.T, imageKit *external.ImageKitService, folder string) string {
+	t.Helper()
+	uploaded, err := imageKit.UploadFile(context.Background(), strings.NewReader("photo"), "photo.png", folder)
+	if err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	return uploaded["fileId"].(string)
+}
+
+func TestConfirmUpload(t *testing.T) {
+	emulator, err := imagekit.NewServer(t.TempDir(), imagekit.Options{PrivateKey: testPrivateKey})
+	if err != nil {
+		t.Fatalf("imagekit.NewServer() error = %v", err)
+	}
+	server := httptest.NewServer(emulator)
+	t.Cleanup(server.Close)
+	emulator.SetPublicURL(server.URL)
+
+	imageKit := newTestImageKit(server)
+	rootFile := uploadFile(t, imageKit, "")
+	folderFile := uploadFile(t, imageKit, "/brands")
+	folders := &fakeFolderRepository{folder: models.MediaFolder{ID: testFolderID, Path: "/brands"}}
+
+	// issued returns a confirmation with the credentials the service issues for folderID
+	issued := func(t *testing.T, service serviceInterfaces.MediaService, folderID, fileID string) requests.MediaConfirmUploadRequest {
+		t.Helper()
+		auth, err := service.GetUploadAuth(context.Background(), folderID)
+		if err != nil {
+			t.Fatalf("GetUploadAuth() error = %v", err)
+		}
+		return requests.MediaConfirmUploadRequest{FileID: fileID, FolderID: folderID, Token: auth.Token, Expire: auth.Expire, Receipt: auth.Receipt}
+	}
+
+	tests := []struct {
+		name    string
+		request func(t *testing.T, service serviceInterfaces.MediaService) requests.MediaConfirmUploadRequest
+		wantErr error
+	}{
+		{
+			name: "upload to the root",
+			request: func(t *testing.T, service serviceInterfaces.MediaService) requests.MediaConfirmUploadRequest {
+				return issued(t, service, "", rootFile)
+			},
+		},
+		{
+			name: "upload to the folder of the credentials",
+			request: func(t *testing.T, service serviceInterfaces.MediaService) requests.MediaConfirmUploadRequest {
+				return issued(t, service, testFolderID, folderFile)
+			},
+		},
+		{
+			name: "forged receipt",
+			request: func(t *testing.T, service serviceInterfaces.MediaService) requests.MediaConfirmUploadRequest {
+				request := issued(t, service, "", rootFile)
+				request.Token = "another-token"
+				return request
+			},
+			wantErr: serviceInterfaces.ErrUploadNotAuthorized,
+		},
+		{
+			name: "credentials of another folder",
+			request: func(t *testing.T, service serviceInterfaces.MediaService) requests.MediaConfirmUploadRequest {
+				request := issued(t, service, "", rootFile)
+				request.FolderID = testFolderID
+				return request
+			},
+			wantErr: serviceInterfaces.ErrUploadNotAuthorized,
+		},
+		{
+			name: "file uploaded outside the folder of the credentials",
+			request: func(t *testing.T, service serviceInterfaces.MediaService) requests.MediaConfirmUploadRequest {
+				return issued(t, service, testFolderID, rootFile)
+			},
+			wantErr: serviceInterfaces.ErrUploadMismatch,
+		},
+		{
+			name: "file uploaded before the credentials were issued",
+			request: func(t *testing.T, service serviceInterfaces.MediaService) requests.MediaConfirmUploadRequest {
+				expire := time.Now().Add(24 * time.Hour).Unix()
+				return requests.MediaConfirmUploadRequest{FileID: rootFile, Token: "token", Expire: expire, Receipt: imageKit.UploadReceipt("token", expire, "")}
+			},
+			wantErr: serviceInterfaces.ErrUploadMismatch,
+		},
+		{
+			name: "file that was never uploaded",
+			request: func(t *testing.T, service serviceInterfaces.MediaService) requests.MediaConfirmUploadRequest {
+				return issued(t, service, "", "missing-file")
+			},
+			wantErr: serviceInterfaces.ErrUploadNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			media := &fakeMediaRepository{}
+			service := NewMediaService(media, folders, imageKit, nil, zap.NewNop())
+
+			_, duplicate, err := service.ConfirmUpload(context.Background(), tt.request(t, service))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("ConfirmUpload() error = %v", err)
+				}
+				if duplicate || len(media.created) != 1 {
+					t.Errorf("ConfirmUpload() created %d media, duplicate %v; want one new media", len(media.created), duplicate)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmUpload() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(media.created) != 0 {
+				t.Errorf("rejected confirmation created %v", media.created)
+			}
+		})
+	}
+}
+
+func TestConfirmUploadStorageFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Internal error"}`, http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	imageKit := newTestImageKit(server)
+	service := NewMediaService(&fakeMediaRepository{}, &fakeFolderRepository{}, imageKit, nil, zap.NewNop())
+	expire := time.Now().Add(time.Minute).Unix()
+
+	_, _, err := service.ConfirmUpload(context.Background(), requests.MediaConfirmUploadRequest{
+		FileID:  "file-1",
+		Token:   "token",
+		Expire:  expire,
+		Receipt: imageKit.UploadReceipt("token", expire, ""),
+	})
+
+	// A failing ImageKit is not the client's fault, so it must not surface as a domain error
+	var storageErr *external.Error
+	if !errors.As(err, &storageErr) || storageErr.Status != http.StatusInternalServerError {
+		t.Fatalf("ConfirmUpload() error = %v, want ImageKit's 500", err)
+	}
+	if _, ok := domain.As(err); ok {
+		t.Errorf("ConfirmUpload() error %v is a domain error", err)
+	}
+}
+
+func TestUploadMediaReusesDuplicateContent(t *testing.T) {
+	emulator, err := imagekit.NewServer(t.TempDir(), imagekit.Options{PrivateKey: testPrivateKey})
+	if err != nil {
+		t.Fatalf("imagekit.NewServer() error = %v", err)
+	}
+	server := httptest.NewServer(emulator)
+	t.Cleanup(server.Close)
+	emulator.SetPublicURL(server.URL)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "photo.png")
+	_, _ = part.Write([]byte("photo"))
+	_ = writer.Close()
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("ReadForm() error = %v", err)
+	}
+
+	imageKit := newTestImageKit(server)
+	media := &duplicateMediaRepository{existing: models.Media{ID: "01J0000000000000000000MED1"}}
+	service := NewMediaService(media, &fakeFolderRepository{}, imageKit, nil, zap.NewNop())
+
+	uploaded, duplicate, err := service.UploadMedia(context.Background(), requests.MediaUploadRequest{File: form.File["file"][0]})
+	if err != nil {
+		t.Fatalf("UploadMedia() error = %v", err)
+	}
+	if !duplicate || uploaded.ID != media.existing.ID {
+		t.Errorf("UploadMedia() = %s, duplicate %v; want the existing media", uploaded.ID, duplicate)
+	}
+
+	// The content is hashed while it is uploaded
+	if len(media.created) != 1 || media.created[0]["content_hash"] != imagehash.ContentHash([]byte("photo")) {
+		t.Fatalf("UploadMedia() created %v, want the hash of the uploaded content", media.created)
+	}
+
+	// The redundant copy is dropped from storage
+	files, err := imageKit.ListFiles(context.Background(), 0, 10)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("storage holds %d files after a duplicate upload, want none", len(files))
+	}
+}
+
+func TestGetDuplicateReport(t *testing.T) {
+	media := &hashedMediaRepository{media: []models.Media{
+		{ID: "01J0000000000000000000MED1", PerceptualHash: "ff00000000000000"},
+		{ID: "01J0000000000000000000MED2", PerceptualHash: "0000000000000000"},
+		{ID: "01J0000000000000000000MED3", PerceptualHash: "ff00000000000003"},
+		{ID: "01J0000000000000000000MED4", PerceptualHash: "0000000000000000"},
+		{ID: "01J0000000000000000000MED5", PerceptualHash: "00000000ffffffff"},
+	}}
+	service := NewMediaService(media, &fakeFolderRepository{}, nil, nil, zap.NewNop())
+
+	groups, err := service.GetDuplicateReport(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetDuplicateReport() error = %v", err)
+	}
+
+	// Alike hashes form a group, and so does a hash shared by several media
+	want := [][]string{
+		{"01J0000000000000000000MED1", "01J0000000000000000000MED3"},
+		{"01J0000000000000000000MED2", "01J0000000000000000000MED4"},
+	}
+	wantDistances := []int{2, 0}
+	var got [][]string
+	for _, group := range groups {
+		var ids []string
+		for _, item := range group.Media {
+			ids = append(ids, item.ID)
+		}
+		got = append(got, ids)
+	}
+	slices.SortFunc(got, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("GetDuplicateReport() groups = %v, want %v", got, want)
+	}
+	for _, group := range groups {
+		i := slices.IndexFunc(want, func(ids []string) bool { return ids[0] == group.Media[0].ID })
+		if group.MaxDistance != wantDistances[i] {
+			t.Errorf("group of %s has max distance %d, want %d", group.Media[0].ID, group.MaxDistance, wantDistances[i])
+		}
+	}
+}
+
+func TestMediaWritesInvalidateCatalogCaches(t *testing.T) {
+	mediaIDs := []string{"01J0000000000000000000MED1"}
+	tests := []struct {
+		name            string
+		write           func(service serviceInterfaces.MediaService) error
+		wantInvalidated []string
+	}{
+		{"tag", func(service serviceInterfaces.MediaService) error {
+			return service.TagMedia(context.Background(), requests.MediaTagRequest{MediaIDs: mediaIDs, Tags: []string{"summer"}})
+		}, []string{categoriesCacheNamespace, mediaTagsCacheNamespace}},
+		{"untag", func(service serviceInterfaces.MediaService) error {
+			return service.UntagMedia(context.Background(), requests.MediaTagRequest{MediaIDs: mediaIDs, Tags: []string{"summer"}})
+		}, []string{categoriesCacheNamespace, mediaTagsCacheNamespace}},
+		{"move", func(service serviceInterfaces.MediaService) error {
+			return service.MoveMedia(context.Background(), requests.MediaMoveRequest{MediaIDs: mediaIDs})
+		}, []string{categoriesCacheNamespace}},
+		{"force delete of attached media", func(service serviceInterfaces.MediaService) error {
+			return service.DeleteMedia(context.Background(), mediaIDs[0], true)
+		}, []string{categoriesCacheNamespace}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryCache := cache.New(cache.NewMemoryStore(100), time.Minute)
+			service := NewMediaService(&fakeMediaRepository{}, &fakeFolderRepository{}, nil, queryCache, zap.NewNop())
+
+			loads := 0
+			load := func(ctx context.Context) (int, error) {
+				loads++
+				return loads, nil
+			}
+			namespaces := []string{brandsCacheNamespace, categoriesCacheNamespace, mediaTagsCacheNamespace}
+			for _, namespace := range namespaces {
+				_, _ = cache.Remember(context.Background(), queryCache, namespace, "all", load)
+			}
+
+			if err := tt.write(service); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+
+			// Brands embed no media, so their cache survives every media write
+			for _, namespace := range namespaces {
+				before := loads
+				if _, err := cache.Remember(context.Background(), queryCache, namespace, "all", load); err != nil {
+					t.Fatalf("Remember() error = %v", err)
+				}
+				wantReload := slices.Contains(tt.wantInvalidated, namespace)
+				if reloaded := loads != before; reloaded != wantReload {
+					t.Errorf("%s cache reloaded = %v, want %v", namespace, reloaded, wantReload)
+				}
+			}
+		})
+	}
+}
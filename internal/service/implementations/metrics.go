@@ -0,0 +1,16 @@
+package implementations
+
+import "beautyessentials.com/internal/utils/metrics"
+
+// Business counters exposed on the metrics endpoint
+var (
+	brandsCreatedTotal = metrics.NewCounterVec(
+		"brands_created_total",
+		"Number of brands created.",
+	)
+	mediaUploadedTotal = metrics.NewCounterVec(
+		"media_uploaded_total",
+		"Number of new media files stored, by upload path (server or direct).",
+		"source",
+	)
+)
@@ -0,0 +1,240 @@
+package implementations
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"beautyessentials.com/internal/constant"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/models"
+	"beautyessentials.com/internal/repository/interfaces"
+	"beautyessentials.com/internal/requests"
+	serviceInterfaces "beautyessentials.com/internal/service/interfaces"
+	"beautyessentials.com/internal/utils/tracing"
+	"gorm.io/gorm"
+)
+
+// RoleService implements the RoleService interface
+type RoleService struct {
+	roleRepo interfaces.RoleRepository
+	userRepo interfaces.UserRepository
+}
+
+// NewRoleService creates a new instance of RoleService
+func NewRoleService(roleRepo interfaces.RoleRepository, userRepo interfaces.UserRepository) serviceInterfaces.RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// GetAllRoles retrieves every role with its permissions
+func (s *RoleService) GetAllRoles(ctx context.Context) ([]dto.RoleDTO, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.GetAllRoles")
+	defer span.End()
+
+	roles, err := s.roleRepo.GetAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dto.TransformRoleCollection(roles), nil
+}
+
+// FindRole finds a role by ID
+func (s *RoleService) FindRole(ctx context.Context, id string) (dto.RoleDTO, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.FindRole")
+	defer span.End()
+
+	role, err := s.roleRepo.FindRole(ctx, id)
+	if err != nil {
+		return dto.RoleDTO{}, err
+	}
+	return dto.FromRoleModel(role), nil
+}
+
+// CreateRole creates a role granting the requested permissions
+func (s *RoleService) CreateRole(ctx context.Context, request requests.RoleCreateRequest) (dto.RoleDTO, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.CreateRole")
+	defer span.End()
+
+	permissions, err := normalizePermissions(request.Permissions)
+	if err != nil {
+		return dto.RoleDTO{}, err
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if err := s.ensureRoleNameAvailable(ctx, name, ""); err != nil {
+		return dto.RoleDTO{}, err
+	}
+
+	role := models.Role{
+		Name:        name,
+		Description: strings.TrimSpace(request.Description),
+	}
+	for _, permission := range permissions {
+		role.Permissions = append(role.Permissions, models.RolePermission{Permission: permission})
+	}
+
+	role, err = s.roleRepo.CreateRole(ctx, role)
+	if err != nil {
+		return dto.RoleDTO{}, err
+	}
+
+	return dto.FromRoleModel(role), nil
+}
+
+// UpdateRole updates a role's name, description and permissions
+func (s *RoleService) UpdateRole(ctx context.Context, id string, request requests.RoleUpdateRequest) (dto.RoleDTO, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.UpdateRole")
+	defer span.End()
+
+	role, err := s.roleRepo.FindRole(ctx, id)
+	if err != nil {
+		return dto.RoleDTO{}, err
+	}
+	if role.IsSystem {
+		return dto.RoleDTO{}, serviceInterfaces.ErrSystemRole
+	}
+
+	data := make(map[string]interface{})
+	if request.Name != nil {
+		name := strings.TrimSpace(*request.Name)
+		if err := s.ensureRoleNameAvailable(ctx, name, id); err != nil {
+			return dto.RoleDTO{}, err
+		}
+		data["name"] = name
+	}
+	if request.Description != nil {
+		data["description"] = strings.TrimSpace(*request.Description)
+	}
+
+	// A nil slice leaves the permissions untouched
+	var permissions []string
+	if request.Permissions != nil {
+		permissions, err = normalizePermissions(*request.Permissions)
+		if err != nil {
+			return dto.RoleDTO{}, err
+		}
+	}
+
+	role, err = s.roleRepo.UpdateRole(ctx, id, data, permissions)
+	if err != nil {
+		return dto.RoleDTO{}, err
+	}
+
+	return dto.FromRoleModel(role), nil
+}
+
+// DeleteRole deletes a role and removes it from every user
+func (s *RoleService) DeleteRole(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "RoleService.DeleteRole")
+	defer span.End()
+
+	role, err := s.roleRepo.FindRole(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return serviceInterfaces.ErrSystemRole
+	}
+	return s.roleRepo.DeleteRole(ctx, id)
+}
+
+// GetAllPermissions lists every permission that can be granted
+func (s *RoleService) GetAllPermissions(ctx context.Context) []dto.PermissionDTO {
+	ctx, span := tracing.Start(ctx, "RoleService.GetAllPermissions")
+	defer span.End()
+
+	permissions := make([]dto.PermissionDTO, 0, len(constant.Permissions))
+	for name, description := range constant.Permissions {
+		permissions = append(permissions, dto.PermissionDTO{Name: name, Description: description})
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].Name < permissions[j].Name
+	})
+	return permissions
+}
+
+// SetUserRoles replaces the roles assigned to a user
+func (s *RoleService) SetUserRoles(ctx context.Context, userID string, request requests.UserRolesRequest) (dto.UserDTO, error) {
+	ctx, span := tracing.Start(ctx, "RoleService.SetUserRoles")
+	defer span.End()
+
+	if _, err := s.userRepo.FindUser(ctx, userID); err != nil {
+		return dto.UserDTO{}, err
+	}
+
+	// Every role must exist
+	for _, roleID := range request.RoleIDs {
+		if _, err := s.roleRepo.FindRole(ctx, roleID); err != nil {
+			return dto.UserDTO{}, err
+		}
+	}
+
+	if err := s.roleRepo.SetUserRoles(ctx, userID, request.RoleIDs); err != nil {
+		return dto.UserDTO{}, err
+	}
+
+	user, err := s.userRepo.FindUser(ctx, userID)
+	if err != nil {
+		return dto.UserDTO{}, err
+	}
+	return dto.FromUserModel(user), nil
+}
+
+// GrantRoleByEmail adds a role to the user with the given email
+func (s *RoleService) GrantRoleByEmail(ctx context.Context, email string, roleName string) error {
+	ctx, span := tracing.Start(ctx, "RoleService.GrantRoleByEmail")
+	defer span.End()
+
+	user, err := s.userRepo.FindUserByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		return err
+	}
+
+	role, err := s.roleRepo.FindRoleByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	return s.roleRepo.AddUserRole(ctx, user.ID, role.ID)
+}
+
+// ensureRoleNameAvailable fails when another role already uses the name
+func (s *RoleService) ensureRoleNameAvailable(ctx context.Context, name string, exceptID string) error {
+	existing, err := s.roleRepo.FindRoleByName(ctx, name)
+	if err == nil && existing.ID != exceptID {
+		return serviceInterfaces.ErrRoleNameTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// normalizePermissions de-duplicates permissions and rejects unknown ones
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(permissions))
+	var unknown []string
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+
+		if _, ok := constant.Permissions[permission]; !ok {
+			unknown = append(unknown, permission)
+			continue
+		}
+		normalized = append(normalized, permission)
+	}
+
+	if len(unknown) > 0 {
+		return nil, &serviceInterfaces.UnknownPermissionError{Permissions: unknown}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,41 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"beautyessentials.com/internal/domain"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/requests"
+)
+
+var (
+	// ErrInvalidApiKey is returned when an API key is unknown, expired or revoked
+	ErrInvalidApiKey = errors.New("API key is invalid, expired or revoked")
+	// ErrScopeNotGrantable is returned when creating a key with scopes the creator doesn't hold
+	ErrScopeNotGrantable = errors.New("cannot grant scopes you do not hold")
+	// ErrExpiryInPast is returned when creating a key that would already be expired
+	ErrExpiryInPast = domain.Validation("API_KEY_EXPIRY_IN_PAST", "Expiry must be in the future")
+)
+
+// ApiKeyService defines the interface for API key management and authentication
+type ApiKeyService interface {
+	GetAllApiKeys(ctx context.Context) ([]dto.ApiKeyDTO, error)
+	FindApiKey(ctx context.Context, id string) (dto.ApiKeyDTO, error)
+	CreateApiKey(ctx context.Context, request requests.ApiKeyCreateRequest, creatorID *string, grantable []string) (dto.ApiKeyCreatedDTO, error)
+	RevokeApiKey(ctx context.Context, id string) error
+	Authenticate(ctx context.Context, key string) (dto.ApiKeyDTO, error)
+}
+
+// RateLimitedError is returned when a caller has exhausted its rate limit
+type RateLimitedError struct {
+	Limit      int
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limit of %d requests per minute exceeded", e.Limit)
+}
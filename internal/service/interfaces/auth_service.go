@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"beautyessentials.com/internal/domain"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/requests"
+)
+
+var (
+	// ErrEmailTaken is returned when registering with an email that already has an account
+	ErrEmailTaken = domain.Conflict("EMAIL_TAKEN", "An account with this email already exists")
+	// ErrInvalidCredentials is returned when an email and password don't match
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrInvalidToken is returned when an access or refresh token is invalid, expired or revoked
+	ErrInvalidToken = errors.New("token is invalid, expired or revoked")
+)
+
+// AuthService defines the interface for user authentication
+type AuthService interface {
+	Register(ctx context.Context, request requests.RegisterRequest) (dto.AuthTokensDTO, error)
+	Login(ctx context.Context, request requests.LoginRequest) (dto.AuthTokensDTO, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (dto.AuthTokensDTO, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, userID string) error
+	Authenticate(ctx context.Context, accessToken string) (dto.UserDTO, error)
+}
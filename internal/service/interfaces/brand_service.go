@@ -10,10 +10,11 @@ import (
 // BrandService defines the interface for brand business logic
 type BrandService interface {
 	GetAllBrands(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error)
-	FindBrand(ctx context.Context, id string) (dto.BrandDTO, error)
+	FindBrand(ctx context.Context, id string, appends map[string]interface{}) (dto.BrandDTO, error)
 	CreateBrand(ctx context.Context, request requests.BrandCreateRequest) (dto.BrandDTO, error)
-	UpdateBrand(ctx context.Context, data map[string]interface{}, id string) (dto.BrandDTO, error)
+	UpdateBrand(ctx context.Context, data map[string]interface{}, id string, version int64) (dto.BrandDTO, error)
 	DeleteBrand(ctx context.Context, id string) error
 	GetActiveBrands(ctx context.Context) ([]dto.BrandDTO, error)
-	GetGroupedBrands(ctx context.Context) (map[string][]dto.BrandDTO, error)
+	GetGroupedBrands(ctx context.Context, appends map[string]interface{}) (map[string][]dto.BrandDTO, error)
+	GetBrandsVersion(ctx context.Context) (dto.VersionDTO, error)
 }
\ No newline at end of file
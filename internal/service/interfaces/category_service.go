@@ -10,10 +10,11 @@ import (
 // CategoryService defines the interface for category service operations
 type CategoryService interface {
 	GetAllCategories(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error)
-	FindCategory(ctx context.Context, id string) (dto.CategoryDTO, error)
+	FindCategory(ctx context.Context, id string, appends map[string]interface{}) (dto.CategoryDTO, error)
 	CreateCategory(ctx context.Context, request requests.CategoryCreateRequest) (dto.CategoryDTO, error)
-	UpdateCategory(ctx context.Context, data map[string]interface{}, id string) (dto.CategoryDTO, error)
+	UpdateCategory(ctx context.Context, data map[string]interface{}, id string, version int64) (dto.CategoryDTO, error)
 	DeleteCategory(ctx context.Context, id string) error
-	GetActiveCategories(ctx context.Context) ([]dto.CategoryDTO, error)
-	FindCategoryBySlug(ctx context.Context, slug string) ([]dto.CategoryDTO, error)
+	GetActiveCategories(ctx context.Context, appends map[string]interface{}) ([]dto.CategoryDTO, error)
+	FindCategoryBySlug(ctx context.Context, slug string, appends map[string]interface{}) ([]dto.CategoryDTO, error)
+	GetCategoriesVersion(ctx context.Context) (dto.VersionDTO, error)
 }
@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"beautyessentials.com/internal/dto"
+)
+
+var (
+	// ErrIdempotencyKeyReused is returned when a key is sent again with a different request body
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+	// ErrIdempotencyKeyInProgress is returned while the first request made with a key is still being handled
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+// IdempotencyService defines the interface for replaying requests made with an idempotency key
+type IdempotencyService interface {
+	// Begin claims key for a request, or returns the stored response when the key already completed
+	Begin(ctx context.Context, scope string, key string, fingerprint string) (*dto.IdempotentResponseDTO, error)
+	// Complete stores the response of the request holding key
+	Complete(ctx context.Context, scope string, key string, response dto.IdempotentResponseDTO) error
+	// Release frees key after a failure so the request can be retried
+	Release(ctx context.Context, scope string, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
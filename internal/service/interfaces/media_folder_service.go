@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"beautyessentials.com/internal/domain"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/requests"
+)
+
+// ErrFolderNotEmpty is returned when deleting a folder that still has contents
+var ErrFolderNotEmpty = domain.Conflict("MEDIA_FOLDER_NOT_EMPTY", "Folder is not empty").WithDetail("It still contains sub-folders or media")
+
+// MediaFolderService defines the interface for media folder operations
+type MediaFolderService interface {
+	GetAllFolders(ctx context.Context, filters map[string]interface{}) ([]dto.MediaFolderDTO, error)
+	CreateFolder(ctx context.Context, request requests.MediaFolderCreateRequest) (dto.MediaFolderDTO, error)
+	DeleteFolder(ctx context.Context, id string) error
+}
@@ -2,7 +2,9 @@ package interfaces
 
 import (
 	"context"
+	"fmt"
 
+	"beautyessentials.com/internal/domain"
 	"beautyessentials.com/internal/dto"
 	"beautyessentials.com/internal/requests"
 )
@@ -11,5 +13,43 @@ import (
 type MediaService interface {
 	GetAllMedia(ctx context.Context, filters map[string]interface{}, appends map[string]interface{}) (interface{}, error)
 	CreateMedia(ctx context.Context, request requests.MediaCreateRequest) (dto.MediaDTO, error)
-	DeleteMedia(ctx context.Context, id string) error
-}
\ No newline at end of file
+	DeleteMedia(ctx context.Context, id string, force bool) error
+	GetMediaUsages(ctx context.Context, id string) ([]dto.MediaUsageDTO, error)
+	GetUploadAuth(ctx context.Context, folderID string) (dto.MediaUploadAuthDTO, error)
+	ConfirmUpload(ctx context.Context, request requests.MediaConfirmUploadRequest) (dto.MediaDTO, bool, error)
+	UploadMedia(ctx context.Context, request requests.MediaUploadRequest) (dto.MediaDTO, bool, error)
+	GetDuplicateReport(ctx context.Context, threshold int) ([]dto.MediaDuplicateGroupDTO, error)
+	SyncRemoteMedia(ctx context.Context, dryRun bool) (dto.MediaSyncReportDTO, error)
+	MoveMedia(ctx context.Context, request requests.MediaMoveRequest) error
+	TagMedia(ctx context.Context, request requests.MediaTagRequest) error
+	UntagMedia(ctx context.Context, request requests.MediaTagRequest) error
+	GetAllTags(ctx context.Context) ([]dto.MediaTagDTO, error)
+}
+
+// Errors of confirming direct uploads
+var (
+	// ErrUploadNotAuthorized is returned when the confirmation does not carry credentials issued for its folder
+	ErrUploadNotAuthorized = domain.Validation("UPLOAD_NOT_AUTHORIZED", "Upload credentials are invalid").
+				WithDetail("Send the token, expire and receipt issued by upload-auth for this folder")
+	// ErrUploadNotFound is returned when the confirmed file does not exist in storage
+	ErrUploadNotFound = domain.Validation("UPLOAD_NOT_FOUND", "Uploaded file does not exist")
+	// ErrUploadMismatch is returned when the confirmed file was not uploaded with the credentials
+	ErrUploadMismatch = domain.Validation("UPLOAD_MISMATCH", "File was not uploaded with these credentials")
+)
+
+// MediaInUseError is returned when deleting a media that is still attached to other entities
+type MediaInUseError struct {
+	Usages []dto.MediaUsageDTO
+}
+
+// Error implements the error interface
+func (e *MediaInUseError) Error() string {
+	return fmt.Sprintf("media is still used by %d entities", len(e.Usages))
+}
+
+// Unwrap returns the domain error answering the deletion with the usages
+func (e *MediaInUseError) Unwrap() error {
+	return domain.Conflict("MEDIA_IN_USE", "Media is in use").
+		WithDetail("Detach the media or pass force=true to delete it anyway").
+		WithData(e.Usages)
+}
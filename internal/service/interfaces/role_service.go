@@ -0,0 +1,40 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"beautyessentials.com/internal/domain"
+	"beautyessentials.com/internal/dto"
+	"beautyessentials.com/internal/requests"
+)
+
+var (
+	// ErrRoleNameTaken is returned when creating or renaming a role to an existing name
+	ErrRoleNameTaken = domain.Conflict("ROLE_NAME_TAKEN", "A role with this name already exists")
+	// ErrSystemRole is returned when modifying or deleting a built-in role
+	ErrSystemRole = domain.Conflict("SYSTEM_ROLE", "Built-in roles cannot be modified or deleted")
+)
+
+// RoleService defines the interface for role and permission management
+type RoleService interface {
+	GetAllRoles(ctx context.Context) ([]dto.RoleDTO, error)
+	FindRole(ctx context.Context, id string) (dto.RoleDTO, error)
+	CreateRole(ctx context.Context, request requests.RoleCreateRequest) (dto.RoleDTO, error)
+	UpdateRole(ctx context.Context, id string, request requests.RoleUpdateRequest) (dto.RoleDTO, error)
+	DeleteRole(ctx context.Context, id string) error
+	GetAllPermissions(ctx context.Context) []dto.PermissionDTO
+	SetUserRoles(ctx context.Context, userID string, request requests.UserRolesRequest) (dto.UserDTO, error)
+	GrantRoleByEmail(ctx context.Context, email string, roleName string) error
+}
+
+// UnknownPermissionError is returned when a request references permissions that don't exist
+type UnknownPermissionError struct {
+	Permissions []string
+}
+
+// Error implements the error interface
+func (e *UnknownPermissionError) Error() string {
+	return fmt.Sprintf("unknown permissions: %s", strings.Join(e.Permissions, ", "))
+}
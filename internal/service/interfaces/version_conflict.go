@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"fmt"
+
+	"beautyessentials.com/internal/domain"
+)
+
+// VersionConflictError is returned when an update is based on a stale version
+type VersionConflictError struct {
+	// Current is the latest representation, for the client to merge its changes into
+	Current interface{}
+	// Version is the current version
+	Version int64
+}
+
+// Error implements the error interface
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("record was modified by someone else and is now at version %d", e.Version)
+}
+
+// Unwrap returns the domain error answering the update with the current record
+func (e *VersionConflictError) Unwrap() error {
+	return domain.Conflict("VERSION_CONFLICT", "Record was modified by someone else").
+		WithDetail(e.Error()).
+		WithData(e.Current)
+}
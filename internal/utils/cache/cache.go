@@ -0,0 +1,120 @@
+// Package cache implements a read-through cache of JSON encoded values over a
+// pluggable Store, with namespaces that can be invalidated as a whole.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"beautyessentials.com/internal/utils/metrics"
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache lookups, by namespace
+var (
+	cacheHitsTotal = metrics.NewCounterVec(
+		"cache_hits_total",
+		"Number of reads served from the cache, by namespace.",
+		"namespace",
+	)
+	cacheMissesTotal = metrics.NewCounterVec(
+		"cache_misses_total",
+		"Number of reads that had to load the value, by namespace.",
+		"namespace",
+	)
+)
+
+// Store holds cached values. MemoryStore keeps them in process; implement Store
+// on a shared backend so instances share entries and see each other's invalidations.
+type Store interface {
+	// Get returns the value of key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key; a zero ttl keeps it until evicted
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Cache reads values through a Store, loading them on a miss
+type Cache struct {
+	store Store
+	ttl   time.Duration
+	// loads collapses concurrent misses of the same key into a single load
+	loads singleflight.Group
+}
+
+// New creates a cache whose entries expire after ttl
+func New(store Store, ttl time.Duration) *Cache {
+	return &Cache{store: store, ttl: ttl}
+}
+
+// Remember returns the value cached under key in namespace, calling load and
+// caching its result on a miss. A nil cache always loads. Store failures are
+// treated as misses so the cache never makes a read fail.
+func Remember[T any](ctx context.Context, c *Cache, namespace, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+
+	// Entries are keyed by the namespace generation so invalidation orphans them
+	fullKey := namespace + ":" + c.generation(ctx, namespace) + ":" + key
+
+	var value T
+	if payload, ok, err := c.store.Get(ctx, fullKey); err == nil && ok {
+		if err := json.Unmarshal(payload, &value); err == nil {
+			cacheHitsTotal.Inc(namespace)
+			return value, nil
+		}
+	}
+	cacheMissesTotal.Inc(namespace)
+
+	// The load is shared by concurrent callers, so one caller going away must not cancel it
+	payload, err, _ := c.loads.Do(fullKey, func() (interface{}, error) {
+		ctx := context.WithoutCancel(ctx)
+		loaded, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		payload, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, err
+		}
+		_ = c.store.Set(ctx, fullKey, payload, c.ttl)
+		return payload, nil
+	})
+	if err != nil {
+		return value, err
+	}
+
+	// Every caller decodes its own copy so cached values are never shared or mutated
+	err = json.Unmarshal(payload.([]byte), &value)
+	return value, err
+}
+
+// Invalidate drops every entry of the namespaces
+func (c *Cache) Invalidate(ctx context.Context, namespaces ...string) {
+	if c == nil {
+		return
+	}
+	for _, namespace := range namespaces {
+		_ = c.store.Set(ctx, generationKey(namespace), []byte(ulid.Make().String()), 0)
+	}
+}
+
+// generation returns the current generation of a namespace, starting one if there is none
+func (c *Cache) generation(ctx context.Context, namespace string) string {
+	generation, ok, err := c.store.Get(ctx, generationKey(namespace))
+	if err == nil && ok {
+		return string(generation)
+	}
+
+	// Concurrent starts may race; the loser's entries are merely orphaned
+	fresh := []byte(ulid.Make().String())
+	_ = c.store.Set(ctx, generationKey(namespace), fresh, 0)
+	return string(fresh)
+}
+
+// generationKey is the store key of a namespace's generation
+func generationKey(namespace string) string {
+	return "generation:" + namespace
+}
@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingStore counts the reads of cached values, i.e. every read but those of generations
+type countingStore struct {
+	*MemoryStore
+	reads atomic.Int32
+}
+
+func (s *countingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if !strings.HasPrefix(key, "generation:") {
+		s.reads.Add(1)
+	}
+	return s.MemoryStore.Get(ctx, key)
+}
+
+// counter is a load returning how many times it was called
+type counter struct {
+	calls atomic.Int32
+}
+
+func (c *counter) load(ctx context.Context) (int, error) {
+	return int(c.calls.Add(1)), nil
+}
+
+func TestRemember(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewMemoryStore(100), time.Minute)
+	brands, categories := &counter{}, &counter{}
+
+	remember := func(namespace string, load *counter) int {
+		t.Helper()
+		value, err := Remember(ctx, c, namespace, "all", load.load)
+		if err != nil {
+			t.Fatalf("Remember() error = %v", err)
+		}
+		return value
+	}
+
+	if got := remember("brands", brands); got != 1 {
+		t.Fatalf("first read = %d, want the loaded 1", got)
+	}
+	if got := remember("brands", brands); got != 1 {
+		t.Fatalf("second read = %d, want the cached 1", got)
+	}
+	remember("categories", categories)
+
+	// Invalidating a namespace reloads its entries only
+	c.Invalidate(ctx, "brands")
+	if got := remember("brands", brands); got != 2 {
+		t.Errorf("read after invalidation = %d, want the reloaded 2", got)
+	}
+	if got := remember("categories", categories); got != 1 {
+		t.Errorf("read of another namespace = %d, want the cached 1", got)
+	}
+
+	// Several namespaces are invalidated at once
+	c.Invalidate(ctx, "brands", "categories")
+	if got := remember("brands", brands); got != 3 {
+		t.Errorf("brands after invalidating both = %d, want 3", got)
+	}
+	if got := remember("categories", categories); got != 2 {
+		t.Errorf("categories after invalidating both = %d, want 2", got)
+	}
+}
+
+func TestRememberDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewMemoryStore(100), time.Minute)
+	failing := errors.New("database is down")
+
+	calls := 0
+	load := func(ctx context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", failing
+		}
+		return "loaded", nil
+	}
+
+	if _, err := Remember(ctx, c, "brands", "all", load); !errors.Is(err, failing) {
+		t.Fatalf("Remember() error = %v, want %v", err, failing)
+	}
+	if value, err := Remember(ctx, c, "brands", "all", load); err != nil || value != "loaded" {
+		t.Errorf("Remember() after a failed load = %q, %v; want a fresh load", value, err)
+	}
+}
+
+func TestRememberWithoutCache(t *testing.T) {
+	load := &counter{}
+	for i := 1; i <= 3; i++ {
+		if got, _ := Remember(context.Background(), nil, "brands", "all", load.load); got != i {
+			t.Errorf("read %d of a nil cache = %d, want a load every time", i, got)
+		}
+	}
+}
+
+func TestInvalidateDuringLoad(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewMemoryStore(100), time.Minute)
+
+	// The first load reads the catalog as it was before the write
+	started, release := make(chan struct{}), make(chan struct{})
+	stale := make(chan string)
+	go func() {
+		value, _ := Remember(ctx, c, "brands", "all", func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "before write", nil
+		})
+		stale <- value
+	}()
+	<-started
+
+	// The write lands and invalidates while that load is still running
+	c.Invalidate(ctx, "brands")
+
+	// A read after the invalidation does not wait for the stale load
+	fresh, err := Remember(ctx, c, "brands", "all", func(ctx context.Context) (string, error) {
+		return "after write", nil
+	})
+	if err != nil || fresh != "after write" {
+		t.Fatalf("read after invalidation = %q, %v; want the fresh value", fresh, err)
+	}
+
+	close(release)
+	if value := <-stale; value != "before write" {
+		t.Fatalf("in-flight read = %q, want its own load", value)
+	}
+
+	// The stale load finishing late must not replace the fresh entry
+	value, err := Remember(ctx, c, "brands", "all", func(ctx context.Context) (string, error) {
+		return "reloaded", nil
+	})
+	if err != nil || value != "after write" {
+		t.Errorf("read after the stale load finished = %q, %v; want %q", value, err, "after write")
+	}
+}
+
+func TestConcurrentMissesLoadOnce(t *testing.T) {
+	const callers = 20
+	store := &countingStore{MemoryStore: NewMemoryStore(100)}
+	c := New(store, time.Minute)
+
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "loaded", nil
+	}
+
+	var wg sync.WaitGroup
+	values := make([]string, callers)
+	errs := make([]error, callers)
+	remember := func(ctx context.Context, i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = Remember(ctx, c, "brands", "all", load)
+		}()
+	}
+
+	// The first caller starts the load, then goes away while the others wait on it
+	ctx, cancel := context.WithCancel(context.Background())
+	remember(ctx, 0)
+	for loads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 1; i < callers; i++ {
+		remember(context.Background(), i)
+	}
+
+	// Release the load once every caller missed and had a moment to join it
+	for store.reads.Load() < callers {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("%d concurrent misses ran %d loads, want 1", callers, n)
+	}
+	for i := range values {
+		if errs[i] != nil || values[i] != "loaded" {
+			t.Errorf("caller %d got %q, %v; want the shared value", i, values[i], errs[i])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// keys returns the keys of s from most to least recently used
+func keys(s *MemoryStore) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for element := s.order.Front(); element != nil; element = element.Next() {
+		keys = append(keys, element.Value.(*entry).key)
+	}
+	return keys
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  func(s *MemoryStore)
+		want []string
+	}{
+		{
+			name: "oldest entry is evicted",
+			ops: func(s *MemoryStore) {
+				set(s, "a", "b", "c", "d")
+			},
+			want: []string{"d", "c", "b"},
+		},
+		{
+			name: "reads keep an entry",
+			ops: func(s *MemoryStore) {
+				set(s, "a", "b", "c")
+				_, _, _ = s.Get(context.Background(), "a")
+				set(s, "d")
+			},
+			want: []string{"d", "a", "c"},
+		},
+		{
+			name: "writes keep an entry",
+			ops: func(s *MemoryStore) {
+				set(s, "a", "b", "c", "a", "d")
+			},
+			want: []string{"d", "a", "c"},
+		},
+		{
+			name: "missing keys do not change the order",
+			ops: func(s *MemoryStore) {
+				set(s, "a", "b", "c")
+				_, _, _ = s.Get(context.Background(), "z")
+				set(s, "d")
+			},
+			want: []string{"d", "c", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore(3)
+			tt.ops(store)
+			got := keys(store)
+			if len(got) != len(tt.want) {
+				t.Fatalf("store holds %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("store holds %v, want %v", got, tt.want)
+				}
+			}
+			for _, key := range tt.want {
+				if _, ok, _ := store.Get(context.Background(), key); !ok {
+					t.Errorf("Get(%q) missed", key)
+				}
+			}
+		})
+	}
+}
+
+// set stores each key with itself as the value
+func set(s *MemoryStore, keys ...string) {
+	for _, key := range keys {
+		_ = s.Set(context.Background(), key, []byte(key), 0)
+	}
+}
+
+func TestMemoryStoreExpires(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(10)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = store.Set(ctx, "short", []byte("1"), time.Minute)
+	_ = store.Set(ctx, "forever", []byte("2"), 0)
+
+	now = now.Add(time.Minute - time.Second)
+	if _, ok, _ := store.Get(ctx, "short"); !ok {
+		t.Error("entry expired before its ttl")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok, _ := store.Get(ctx, "short"); ok {
+		t.Error("entry outlived its ttl")
+	}
+	if _, ok, _ := store.Get(ctx, "forever"); !ok {
+		t.Error("entry without a ttl expired")
+	}
+	if got := keys(store); len(got) != 1 {
+		t.Errorf("expired entry is still held: %v", got)
+	}
+}
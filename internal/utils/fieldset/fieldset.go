@@ -0,0 +1,210 @@
+// Package fieldset implements sparse fieldsets (?fields[type]=a,b) and
+// relationship includes (?include=a,b) in the style of JSON:API.
+package fieldset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// Query holds the fields and relationships requested for a response. Names are snake_case.
+type Query struct {
+	// Fields maps a resource type to its requested fields; types without an entry get every field
+	Fields map[string][]string
+	// Include lists the relationships to embed
+	Include []string
+}
+
+// Parse reads the fields[type] and include parameters; names may be camelCase or snake_case
+func Parse(values url.Values) Query {
+	var q Query
+	for key, value := range values {
+		if !strings.HasPrefix(key, "fields[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		if q.Fields == nil {
+			q.Fields = make(map[string][]string)
+		}
+		// An empty list is kept, requesting no fields but id
+		resourceType := strcase.ToSnake(strings.TrimSuffix(strings.TrimPrefix(key, "fields["), "]"))
+		q.Fields[resourceType] = append([]string{}, splitNames(strings.Join(value, ","))...)
+	}
+	q.Include = splitNames(strings.Join(values["include"], ","))
+	return q
+}
+
+// Validate reports types, fields and relationships that are not in allowed, which maps
+// every resource type of the response to its fields, or in includes
+func (q Query) Validate(allowed map[string][]string, includes ...string) error {
+	for resourceType, fields := range q.Fields {
+		known, ok := allowed[resourceType]
+		if !ok {
+			return fmt.Errorf("unknown resource type %q in fields", resourceType)
+		}
+		for _, field := range fields {
+			if !contains(known, field) {
+				return fmt.Errorf("unknown field %q of %s; available fields: %s", field, resourceType, strings.Join(known, ", "))
+			}
+		}
+	}
+	for _, relationship := range q.Include {
+		if !contains(includes, relationship) {
+			if len(includes) == 0 {
+				return fmt.Errorf("unknown include %q; this resource has no relationships", relationship)
+			}
+			return fmt.Errorf("unknown include %q; available relationships: %s", relationship, strings.Join(includes, ", "))
+		}
+	}
+	return nil
+}
+
+// FieldsOf returns the fields requested for resourceType, nil when every field is
+func (q Query) FieldsOf(resourceType string) []string {
+	return q.Fields[resourceType]
+}
+
+// Includes reports whether relationship was requested
+func (q Query) Includes(relationship string) bool {
+	return contains(q.Include, relationship)
+}
+
+// IsZero reports whether q requests the full representation
+func (q Query) IsZero() bool {
+	return len(q.Fields) == 0 && len(q.Include) == 0
+}
+
+// Key returns a canonical encoding of q, for cache keys
+func (q Query) Key() string {
+	if q.IsZero() {
+		return ""
+	}
+	parts := make([]string, 0, len(q.Fields)+1)
+	for resourceType, fields := range q.Fields {
+		parts = append(parts, "fields["+resourceType+"]="+strings.Join(sorted(fields), ","))
+	}
+	sort.Strings(parts)
+	if len(q.Include) > 0 {
+		parts = append(parts, "include="+strings.Join(sorted(q.Include), ","))
+	}
+	return strings.Join(parts, "&")
+}
+
+// Columns returns the columns to select for fields: id and those of fields found in
+// columns. It returns nil, selecting every column, when fields is nil.
+func Columns(fields []string, columns ...string) []string {
+	if fields == nil {
+		return nil
+	}
+	selected := []string{"id"}
+	for _, field := range fields {
+		if field != "id" && contains(columns, field) {
+			selected = append(selected, field)
+		}
+	}
+	return selected
+}
+
+// Names returns the field names of a DTO, taken from its json tags in snake_case
+func Names(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, strcase.ToSnake(name))
+	}
+	return names
+}
+
+// Shape returns v, a DTO or a slice of them, with only the members requested for
+// resourceType; id is always kept. relationships maps the member holding each
+// includable relationship to its resource type: included ones are shaped with the
+// fields of that type and the others are dropped.
+func Shape(v interface{}, q Query, resourceType string, relationships map[string]string) (interface{}, error) {
+	if q.IsZero() {
+		return v, nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return shape(decoded, q, resourceType, relationships), nil
+}
+
+// shape filters a decoded resource or list of resources
+func shape(v interface{}, q Query, resourceType string, relationships map[string]string) interface{} {
+	switch value := v.(type) {
+	case []interface{}:
+		for i, item := range value {
+			value[i] = shape(item, q, resourceType, relationships)
+		}
+		return value
+	case map[string]interface{}:
+		fields := q.FieldsOf(resourceType)
+		for key, member := range value {
+			name := strcase.ToSnake(key)
+			if relatedType, ok := relationships[name]; ok {
+				if q.Includes(name) && member != nil {
+					value[key] = shape(member, q, relatedType, nil)
+				} else {
+					delete(value, key)
+				}
+				continue
+			}
+			if fields != nil && name != "id" && !contains(fields, name) {
+				delete(value, key)
+			}
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// splitNames splits a comma separated list, normalizing names to snake_case
+func splitNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, strcase.ToSnake(name))
+		}
+	}
+	return names
+}
+
+// sorted returns a sorted copy of names
+func sorted(names []string) []string {
+	copied := append([]string(nil), names...)
+	sort.Strings(copied)
+	return copied
+}
+
+// contains reports whether names holds name
+func contains(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package imagehash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"sync"
+)
+
+// Hasher computes the content and perceptual hashes of what is written to it,
+// so a file can be fingerprinted while it is streamed elsewhere. Sum must be
+// called once writing is done, even when it was abandoned.
+type Hasher struct {
+	content hash.Hash
+	pipe    *io.PipeWriter
+	done    chan struct{}
+	close   sync.Once
+
+	// perceptual is set by the decoding goroutine before done is closed
+	perceptual string
+}
+
+// NewHasher creates a Hasher, decoding the image written to it as it arrives
+func NewHasher() *Hasher {
+	reader, writer := io.Pipe()
+	h := &Hasher{
+		content: sha256.New(),
+		pipe:    writer,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(h.done)
+		perceptual, err := perceptualHash(reader)
+		if err == nil {
+			h.perceptual = perceptual
+		}
+		// Keep consuming after the decoder stops, e.g. on content that is no image,
+		// so writes never block
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+	return h
+}
+
+// Write implements io.Writer
+func (h *Hasher) Write(p []byte) (int, error) {
+	h.content.Write(p)
+	_, _ = h.pipe.Write(p)
+	return len(p), nil
+}
+
+// Sum finishes hashing and returns the hex encoded SHA-256 of the content and,
+// when it is a decodable image, its perceptual hash
+func (h *Hasher) Sum() (contentHash string, perceptualHash string) {
+	h.close.Do(func() {
+		_ = h.pipe.Close()
+	})
+	<-h.done
+	return hex.EncodeToString(h.content.Sum(nil)), h.perceptual
+}
@@ -0,0 +1,98 @@
+package imagehash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"math/bits"
+	"strconv"
+
+	// Register decoders for the formats we can fingerprint
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// ContentHash returns the hex encoded SHA-256 of the content
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// PerceptualHash returns a 64-bit difference hash (dHash) of an image as 16 hex characters.
+// Visually similar images produce hashes with a small Hamming distance.
+func PerceptualHash(content []byte) (string, error) {
+	return perceptualHash(bytes.NewReader(content))
+}
+
+// perceptualHash returns the difference hash of the image read from r
+func perceptualHash(r io.Reader) (string, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	// Shrink to a 9x8 grayscale grid
+	const width, height = 9, 8
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return "", fmt.Errorf("image has no pixels")
+	}
+	var grid [height][width]float64
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(bounds.Min.Y+(y+1)*bounds.Dy()/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(bounds.Min.X+(x+1)*bounds.Dx()/width, x0+1)
+			grid[y][x] = averageLuminance(img, x0, y0, x1, y1)
+		}
+	}
+
+	// Each bit records whether brightness increases left to right
+	var hash uint64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width-1; x++ {
+			hash <<= 1
+			if grid[y][x] < grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%016x", hash), nil
+}
+
+// Distance returns the Hamming distance between two perceptual hashes
+func Distance(a string, b string) (int, error) {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid perceptual hash %q: %w", a, err)
+	}
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid perceptual hash %q: %w", b, err)
+	}
+	return bits.OnesCount64(x ^ y), nil
+}
+
+// averageLuminance returns the mean luminance of the pixels in a rectangle
+func averageLuminance(img image.Image, x0, y0, x1, y1 int) float64 {
+	// Sample at most 16x16 points per cell to keep large images cheap
+	stepX := max((x1-x0)/16, 1)
+	stepY := max((y1-y0)/16, 1)
+
+	var sum float64
+	var count int
+	for y := y0; y < y1; y += stepY {
+		for x := x0; x < x1; x += stepX {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			count++
+		}
+	}
+	return sum / float64(count)
+}
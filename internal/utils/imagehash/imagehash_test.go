@@ -0,0 +1,84 @@
+package imagehash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSimilarPairsMatchesEveryPairComparison(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	hashes := []string{"not-a-hash"}
+	for i := 0; i < 200; i++ {
+		value := random.Uint64()
+		hashes = append(hashes, fmt.Sprintf("%016x", value))
+		// Add variants a few bits away, as near duplicates are
+		hashes = append(hashes, fmt.Sprintf("%016x", value^1<<random.IntN(64)^1<<random.IntN(64)))
+	}
+	hashes = append(hashes, hashes[1])
+
+	for _, threshold := range []int{0, 2, 5, 20} {
+		var want []Pair
+		for a := 0; a < len(hashes); a++ {
+			for b := a + 1; b < len(hashes); b++ {
+				if distance, err := Distance(hashes[a], hashes[b]); err == nil && distance <= threshold {
+					want = append(want, Pair{A: a, B: b, Distance: distance})
+				}
+			}
+		}
+
+		if got := SimilarPairs(hashes, threshold); !slices.Equal(got, want) {
+			t.Errorf("SimilarPairs(threshold %d) found %d pairs, want %d", threshold, len(got), len(want))
+		}
+	}
+}
+
+func TestHasher(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 32, 32))
+	for x := 0; x < 32; x++ {
+		for y := 0; y < 32; y++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(x * 8)})
+		}
+	}
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	wantPerceptual, err := PerceptualHash(encoded.Bytes())
+	if err != nil {
+		t.Fatalf("PerceptualHash() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		content        []byte
+		wantPerceptual string
+	}{
+		{"image", encoded.Bytes(), wantPerceptual},
+		{"content that is no image", bytes.Repeat([]byte("text"), 100_000), ""},
+		{"nothing", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := NewHasher()
+			// Write in small chunks, as content arrives while streaming
+			for content := tt.content; len(content) > 0; content = content[min(len(content), 1000):] {
+				_, _ = hasher.Write(content[:min(len(content), 1000)])
+			}
+
+			contentHash, perceptualHash := hasher.Sum()
+			if contentHash != ContentHash(tt.content) {
+				t.Errorf("Sum() content hash = %s, want %s", contentHash, ContentHash(tt.content))
+			}
+			if perceptualHash != tt.wantPerceptual {
+				t.Errorf("Sum() perceptual hash = %q, want %q", perceptualHash, tt.wantPerceptual)
+			}
+		})
+	}
+}
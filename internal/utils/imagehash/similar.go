@@ -0,0 +1,105 @@
+package imagehash
+
+import (
+	"math/bits"
+	"sort"
+	"strconv"
+)
+
+// maxChunks bounds how finely hashes are split; thresholds needing more chunks
+// than this compare every pair, as such small chunks would hardly narrow it down
+const maxChunks = 16
+
+// Pair is two hashes, by their index, within the distance searched for
+type Pair struct {
+	A        int
+	B        int
+	Distance int
+}
+
+// SimilarPairs returns the pairs of hashes whose Hamming distance is at most
+// threshold, skipping invalid hashes. Hashes are split into threshold+1 chunks
+// of bits, of which two hashes that close share at least one, so only hashes
+// sharing a chunk are compared instead of every pair.
+func SimilarPairs(hashes []string, threshold int) []Pair {
+	if threshold < 0 {
+		return nil
+	}
+
+	values := make(map[int]uint64, len(hashes))
+	for i, hash := range hashes {
+		if value, err := strconv.ParseUint(hash, 16, 64); err == nil {
+			values[i] = value
+		}
+	}
+
+	// Bucket hashes on the value of each of their chunks; a single chunk without
+	// bits puts every hash in the same bucket
+	chunks, width := threshold+1, 64
+	if chunks > maxChunks {
+		chunks, width = 1, 0
+	}
+	type bucket struct {
+		chunk int
+		value uint64
+	}
+	buckets := make(map[bucket][]int)
+	var order []bucket
+	for i := range hashes {
+		value, ok := values[i]
+		if !ok {
+			continue
+		}
+		for chunk := 0; chunk < chunks; chunk++ {
+			key := bucket{chunk, chunkOf(value, chunk, chunks, width)}
+			if _, ok := buckets[key]; !ok {
+				order = append(order, key)
+			}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	var pairs []Pair
+	for _, key := range order {
+		members := buckets[key]
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				a, b := values[members[x]], values[members[y]]
+				// Each pair is compared in the first chunk it shares only
+				if sharesEarlierChunk(a, b, key.chunk, chunks, width) {
+					continue
+				}
+				if distance := bits.OnesCount64(a ^ b); distance <= threshold {
+					pairs = append(pairs, Pair{A: members[x], B: members[y], Distance: distance})
+				}
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
+
+// chunkOf returns the bits of chunk out of chunks splitting width bits of value
+func chunkOf(value uint64, chunk int, chunks int, width int) uint64 {
+	start, end := chunk*width/chunks, (chunk+1)*width/chunks
+	if end-start == 64 {
+		return value
+	}
+	return value >> start & (1<<(end-start) - 1)
+}
+
+// sharesEarlierChunk reports whether a and b share a chunk before chunk
+func sharesEarlierChunk(a uint64, b uint64, chunk int, chunks int, width int) bool {
+	for earlier := 0; earlier < chunk; earlier++ {
+		if chunkOf(a, earlier, chunks, width) == chunkOf(b, earlier, chunks, width) {
+			return true
+		}
+	}
+	return false
+}
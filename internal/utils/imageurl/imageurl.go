@@ -0,0 +1,191 @@
+package imageurl
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Preset describes a named image transformation
+type Preset struct {
+	Name    string
+	Width   int
+	Height  int
+	Crop    string
+	Quality int
+	Format  string
+}
+
+// Variant is a single transformed rendition of an image
+type Variant struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// Builder builds ImageKit transformation URLs from presets
+type Builder struct {
+	urlEndpoint string
+	presets     []Preset
+}
+
+var (
+	mu             sync.RWMutex
+	defaultBuilder = &Builder{}
+)
+
+// NewBuilder creates a new Builder for the given URL endpoint and presets
+func NewBuilder(urlEndpoint string, presets []Preset) *Builder {
+	return &Builder{
+		urlEndpoint: strings.TrimRight(urlEndpoint, "/"),
+		presets:     presets,
+	}
+}
+
+// SetDefault replaces the builder used by the package-level helpers
+func SetDefault(b *Builder) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultBuilder = b
+}
+
+// Variants returns the variants of the source URL using the default builder
+func Variants(sourceURL string) map[string]Variant {
+	mu.RLock()
+	b := defaultBuilder
+	mu.RUnlock()
+	return b.Variants(sourceURL)
+}
+
+// ParsePresets parses presets in the form "card:w-400,h-400,c-at_max,q-80;zoom:w-1600"
+func ParsePresets(value string) ([]Preset, error) {
+	var presets []Preset
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, params, found := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid image preset %q", entry)
+		}
+
+		preset := Preset{Name: name, Format: "auto"}
+		for _, param := range strings.Split(params, ",") {
+			key, val, ok := strings.Cut(strings.TrimSpace(param), "-")
+			if !ok || val == "" {
+				return nil, fmt.Errorf("invalid parameter %q in image preset %q", param, name)
+			}
+
+			var err error
+			switch key {
+			case "w":
+				preset.Width, err = strconv.Atoi(val)
+			case "h":
+				preset.Height, err = strconv.Atoi(val)
+			case "q":
+				preset.Quality, err = strconv.Atoi(val)
+			case "c":
+				preset.Crop = val
+			case "f":
+				preset.Format = val
+			default:
+				err = fmt.Errorf("unsupported parameter %q", key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid image preset %q: %w", name, err)
+			}
+		}
+
+		presets = append(presets, preset)
+	}
+
+	return presets, nil
+}
+
+// Transformation returns the ImageKit transformation string for a preset
+func (p Preset) Transformation() string {
+	var parts []string
+	if p.Width > 0 {
+		parts = append(parts, "w-"+strconv.Itoa(p.Width))
+	}
+	if p.Height > 0 {
+		parts = append(parts, "h-"+strconv.Itoa(p.Height))
+	}
+	if p.Crop != "" {
+		parts = append(parts, "c-"+p.Crop)
+	}
+	if p.Quality > 0 {
+		parts = append(parts, "q-"+strconv.Itoa(p.Quality))
+	}
+	if p.Format != "" {
+		parts = append(parts, "f-"+p.Format)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Build returns the source URL with the preset's transformation applied
+func (b *Builder) Build(sourceURL string, preset Preset) string {
+	transformation := preset.Transformation()
+	if sourceURL == "" || transformation == "" {
+		return sourceURL
+	}
+
+	// Files served from our endpoint take the transformation as a path segment
+	if b.urlEndpoint != "" && strings.HasPrefix(sourceURL, b.urlEndpoint+"/") {
+		return b.urlEndpoint + "/tr:" + transformation + strings.TrimPrefix(sourceURL, b.urlEndpoint)
+	}
+
+	// Any other ImageKit URL accepts the transformation as a query parameter
+	parsed, err := url.Parse(sourceURL)
+	if err != nil {
+		return sourceURL
+	}
+	query := parsed.Query()
+	query.Del("tr")
+	parsed.RawQuery = query.Encode()
+	if parsed.RawQuery != "" {
+		parsed.RawQuery += "&"
+	}
+	parsed.RawQuery += "tr=" + transformation
+	return parsed.String()
+}
+
+// Variants returns every configured preset rendition of the source URL
+func (b *Builder) Variants(sourceURL string) map[string]Variant {
+	if b == nil || sourceURL == "" || len(b.presets) == 0 {
+		return nil
+	}
+
+	variants := make(map[string]Variant, len(b.presets))
+	for _, preset := range b.presets {
+		variants[preset.Name] = Variant{
+			URL:    b.Build(sourceURL, preset),
+			Width:  preset.Width,
+			Height: preset.Height,
+		}
+	}
+	return variants
+}
+
+// SrcSet builds a srcset attribute value from the width-bearing variants
+func SrcSet(variants map[string]Variant) string {
+	var items []Variant
+	for _, v := range variants {
+		if v.Width > 0 {
+			items = append(items, v)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Width < items[j].Width })
+
+	parts := make([]string, len(items))
+	for i, v := range items {
+		parts[i] = fmt.Sprintf("%s %dw", v.URL, v.Width)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,187 @@
+// Package keycase negotiates the casing of JSON object keys with clients and
+// rewrites the keys of encoded JSON documents. DTO json tags are snake_case,
+// the canonical casing; other casings are derived from them.
+package keycase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+)
+
+// Case is a casing of JSON object keys
+type Case string
+
+// Supported casings
+const (
+	Snake Case = "snake_case"
+	Camel Case = "camelCase"
+)
+
+// Default is the casing of clients that do not ask for one
+const Default = Camel
+
+// Header is the request header a client picks its casing with
+const Header = "Accept-Case"
+
+// ResponseHeader tells the client the casing of a response's keys
+const ResponseHeader = "Content-Case"
+
+// QueryParam is the query flag picking the casing; it takes precedence over Header
+const QueryParam = "case"
+
+// MaxRewriteSize bounds the documents whose keys are rewritten; larger ones are
+// passed through as they are
+const MaxRewriteSize = 4 << 20
+
+// contextKey is the context key holding the negotiated casing
+type contextKey struct{}
+
+// Parse reads a casing name such as "snake_case", "snake", "camelCase" or "camel"
+func Parse(value string) (Case, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "snake_case", "snake":
+		return Snake, true
+	case "camelcase", "camel":
+		return Camel, true
+	default:
+		return "", false
+	}
+}
+
+// Negotiate picks the casing asked for by the query flag, else the header, else Default
+func Negotiate(header string, query string) Case {
+	if c, ok := Parse(query); ok {
+		return c
+	}
+	if c, ok := Parse(header); ok {
+		return c
+	}
+	return Default
+}
+
+// NewContext returns a copy of ctx carrying the negotiated casing
+func NewContext(ctx context.Context, c Case) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the casing carried by ctx, or Default when there is none
+func FromContext(ctx context.Context) Case {
+	if ctx == nil {
+		return Default
+	}
+	if c, ok := ctx.Value(contextKey{}).(Case); ok {
+		return c
+	}
+	return Default
+}
+
+// Key converts a key to c. Only keys in the other casing are converted, so data
+// used as keys, e.g. the initials of grouped brands, is left alone.
+func Key(key string, c Case) string {
+	switch c {
+	case Camel:
+		if strings.Contains(key, "_") {
+			return strcase.ToLowerCamel(key)
+		}
+	case Snake:
+		if isCamel(key) {
+			return strcase.ToSnake(key)
+		}
+	}
+	return key
+}
+
+// Rewrite writes the JSON document src to dst with its object keys converted to c.
+// It scans src without decoding it, so numbers and member order are kept, and
+// writes unchanged runs straight through. src must be valid JSON.
+func Rewrite(dst io.Writer, src []byte, c Case) error {
+	flushed := 0
+	for i := 0; i < len(src); i++ {
+		if src[i] != '"' {
+			continue
+		}
+
+		end, escaped := stringEnd(src, i)
+		if !isKey(src, end) {
+			i = end - 1
+			continue
+		}
+
+		var key string
+		if escaped {
+			if err := json.Unmarshal(src[i:end], &key); err != nil {
+				return err
+			}
+		} else {
+			key = string(src[i+1 : end-1])
+		}
+		if converted := Key(key, c); converted != key {
+			quoted, err := json.Marshal(converted)
+			if err != nil {
+				return err
+			}
+			if _, err := dst.Write(src[flushed:i]); err != nil {
+				return err
+			}
+			if _, err := dst.Write(quoted); err != nil {
+				return err
+			}
+			flushed = end
+		}
+		i = end - 1
+	}
+	_, err := dst.Write(src[flushed:])
+	return err
+}
+
+// stringEnd returns the index just past the string starting at src[start] and
+// whether it holds escapes
+func stringEnd(src []byte, start int) (int, bool) {
+	escaped := false
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			escaped = true
+			i++
+		case '"':
+			return i + 1, escaped
+		}
+	}
+	return len(src), escaped
+}
+
+// isKey reports whether the string ending before src[end] is an object key
+func isKey(src []byte, end int) bool {
+	for i := end; i < len(src); i++ {
+		switch src[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// isCamel reports whether key is a camelCase identifier with more than one word
+func isCamel(key string) bool {
+	if key == "" || !unicode.IsLower(rune(key[0])) {
+		return false
+	}
+	for _, r := range key {
+		if unicode.IsUpper(r) {
+			return true
+		}
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return false
+}
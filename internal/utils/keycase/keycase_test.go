@@ -0,0 +1,153 @@
+package keycase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		camel string
+		snake string
+	}{
+		{key: "media_id", camel: "mediaId", snake: "media_id"},
+		{key: "mediaId", camel: "mediaId", snake: "media_id"},
+		{key: "mediaID", camel: "mediaID", snake: "media_id"},
+		{key: "thumbURL", camel: "thumbURL", snake: "thumb_url"},
+		{key: "api_key_id", camel: "apiKeyId", snake: "api_key_id"},
+		{key: "id", camel: "id", snake: "id"},
+		{key: "ID", camel: "ID", snake: "ID"},
+		{key: "url", camel: "url", snake: "url"},
+		// Data used as keys, such as the initials of grouped brands, keeps its case
+		{key: "A", camel: "A", snake: "A"},
+		{key: "", camel: "", snake: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := Key(tt.key, Camel); got != tt.camel {
+				t.Errorf("Key(%q, Camel) = %q, want %q", tt.key, got, tt.camel)
+			}
+			if got := Key(tt.key, Snake); got != tt.snake {
+				t.Errorf("Key(%q, Snake) = %q, want %q", tt.key, got, tt.snake)
+			}
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		c    Case
+		want string
+	}{
+		{
+			name: "flat object",
+			src:  `{"id":"01J","media_id":"01K","thumb_url":"https://x"}`,
+			c:    Camel,
+			want: `{"id":"01J","mediaId":"01K","thumbUrl":"https://x"}`,
+		},
+		{
+			name: "nested objects and arrays",
+			src:  `{"data":[{"media_id":"1","media":{"thumb_url":"a","tags":[{"tag_name":"b"}]}},{"media_id":null}],"per_page":15}`,
+			c:    Camel,
+			want: `{"data":[{"mediaId":"1","media":{"thumbUrl":"a","tags":[{"tagName":"b"}]}},{"mediaId":null}],"perPage":15}`,
+		},
+		{
+			name: "camel to snake",
+			src:  `{"mediaIds":["a","b"],"folderID":"f","nested":{"parentId":null}}`,
+			c:    Snake,
+			want: `{"media_ids":["a","b"],"folder_id":"f","nested":{"parent_id":null}}`,
+		},
+		{
+			name: "string values are left untouched",
+			src:  `{"errors":[{"field":"media_id","message":"mediaId: is required"}],"detail":"thumb_url\":1"}`,
+			c:    Camel,
+			want: `{"errors":[{"field":"media_id","message":"mediaId: is required"}],"detail":"thumb_url\":1"}`,
+		},
+		{
+			name: "string values are left untouched when converting to snake",
+			src:  `{"field":"mediaId","values":["folderId","thumbUrl"]}`,
+			c:    Snake,
+			want: `{"field":"mediaId","values":["folderId","thumbUrl"]}`,
+		},
+		{
+			name: "data keys keep their case",
+			src:  `{"A":[{"brand_name":"Avène"}],"B":[]}`,
+			c:    Camel,
+			want: `{"A":[{"brandName":"Avène"}],"B":[]}`,
+		},
+		{
+			name: "escaped keys",
+			src:  `{"media_id":"1","say \"hi\"":2}`,
+			c:    Camel,
+			want: `{"mediaId":"1","say \"hi\"":2}`,
+		},
+		{
+			name: "whitespace, numbers and member order are kept",
+			src:  "{\n  \"z_last\" : 12345678901234567890,\n  \"a_first\" : 1.50\n}",
+			c:    Camel,
+			want: "{\n  \"zLast\" : 12345678901234567890,\n  \"aFirst\" : 1.50\n}",
+		},
+		{
+			name: "documents without objects",
+			src:  `["media_id",1,true,null]`,
+			c:    Camel,
+			want: `["media_id",1,true,null]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst bytes.Buffer
+			if err := Rewrite(&dst, []byte(tt.src), tt.c); err != nil {
+				t.Fatalf("Rewrite() error = %v", err)
+			}
+			if dst.String() != tt.want {
+				t.Errorf("Rewrite() = %s, want %s", dst.String(), tt.want)
+			}
+			if !json.Valid(dst.Bytes()) {
+				t.Errorf("Rewrite() produced invalid JSON %s", dst.String())
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   Case
+	}{
+		{name: "nothing asked", want: Default},
+		{name: "header", header: "snake_case", want: Snake},
+		{name: "short header", header: " Snake ", want: Snake},
+		{name: "query", query: "camel", want: Camel},
+		{name: "query wins over header", header: "camelCase", query: "snake", want: Snake},
+		{name: "malformed header", header: "kebab-case", want: Default},
+		{name: "malformed query falls back to header", header: "snake", query: "SCREAMING", want: Snake},
+		{name: "header with parameters", header: "snake_case; q=1", want: Default},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.header, tt.query); got != tt.want {
+				t.Errorf("Negotiate(%q, %q) = %q, want %q", tt.header, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != Default {
+		t.Errorf("FromContext() without a casing = %q, want %q", got, Default)
+	}
+	if got := FromContext(NewContext(context.Background(), Snake)); got != Snake {
+		t.Errorf("FromContext() = %q, want %q", got, Snake)
+	}
+}
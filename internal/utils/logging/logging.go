@@ -0,0 +1,34 @@
+// Package logging holds helpers for the zap logger shared across layers.
+package logging
+
+import (
+	"context"
+
+	"beautyessentials.com/internal/utils/requestid"
+	"beautyessentials.com/internal/utils/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestIDField is the log field holding the request ID
+	RequestIDField = "request_id"
+	// TraceIDField is the log field holding the trace ID
+	TraceIDField = "trace_id"
+	// SpanIDField is the log field holding the current span ID
+	SpanIDField = "span_id"
+)
+
+// For returns the logger annotated with the request ID and trace carried by ctx, if any
+func For(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	var fields []zap.Field
+	if id := requestid.FromContext(ctx); id != "" {
+		fields = append(fields, zap.String(RequestIDField, id))
+	}
+	if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, zap.String(TraceIDField, sc.TraceID().String()), zap.String(SpanIDField, sc.SpanID().String()))
+	}
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
@@ -0,0 +1,52 @@
+// Package mergepatch applies JSON Merge Patches as defined by RFC 7396.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ContentType is the media type of merge patch documents
+const ContentType = "application/merge-patch+json"
+
+// Apply returns document with patch merged into it. Members of patch replace
+// those of document, objects are merged recursively and null removes a member.
+func Apply(document []byte, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := decode(document, &target); err != nil {
+		return nil, err
+	}
+	var changes interface{}
+	if err := decode(patch, &changes); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+// merge implements the MergePatch function of RFC 7396 section 2
+func merge(target interface{}, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = map[string]interface{}{}
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = merge(result[name], value)
+	}
+	return result
+}
+
+// decode unmarshals JSON keeping numbers as written
+func decode(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,67 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	// Cases from RFC 7396 appendix A, plus number preservation
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null deletes member", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"null deletes only that member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"array replaced", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"value replaced by array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"nested merge and delete", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"array of objects replaced", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"non object patch replaces document", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"existing null member kept", `{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{"object patch over non object", `["a","b"]`, `{"a":"b"}`, `{"a":"b"}`},
+		{"nested null in added object", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{"deleting missing member", `{"a":"b"}`, `{"c":null}`, `{"a":"b"}`},
+		{"numbers kept as written", `{"version":12345678901234567}`, `{"name":"x"}`, `{"name":"x","version":12345678901234567}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.document), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyInvalidJSON(t *testing.T) {
+	if _, err := Apply([]byte(`{"a":1}`), []byte(`{"a":`)); err == nil {
+		t.Error("Apply() with a malformed patch succeeded")
+	}
+	if _, err := Apply([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("Apply() with a malformed document succeeded")
+	}
+}
+
+// assertJSONEqual compares JSON documents ignoring member order
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := decode(got, &gotValue); err != nil {
+		t.Fatalf("result %s is not JSON: %v", got, err)
+	}
+	if err := decode([]byte(want), &wantValue); err != nil {
+		t.Fatalf("expectation %s is not JSON: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		wantJSON, _ := json.Marshal(wantValue)
+		t.Errorf("got %s, want %s", got, wantJSON)
+	}
+}
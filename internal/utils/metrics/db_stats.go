@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DBStatsCollector reports connection pool statistics of a database handle
+type DBStatsCollector struct {
+	db *sql.DB
+}
+
+// dbStats describes the pool statistics, read from sql.DBStats at scrape time
+var dbStats = []struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func(stats sql.DBStats) float64
+}{
+	{
+		prometheus.NewDesc("db_pool_max_open_connections", "Maximum number of open connections to the database.", nil, nil),
+		prometheus.GaugeValue, func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) },
+	},
+	{
+		prometheus.NewDesc("db_pool_open_connections", "Number of established connections, both in use and idle.", nil, nil),
+		prometheus.GaugeValue, func(s sql.DBStats) float64 { return float64(s.OpenConnections) },
+	},
+	{
+		prometheus.NewDesc("db_pool_in_use_connections", "Number of connections currently in use.", nil, nil),
+		prometheus.GaugeValue, func(s sql.DBStats) float64 { return float64(s.InUse) },
+	},
+	{
+		prometheus.NewDesc("db_pool_idle_connections", "Number of idle connections.", nil, nil),
+		prometheus.GaugeValue, func(s sql.DBStats) float64 { return float64(s.Idle) },
+	},
+	{
+		prometheus.NewDesc("db_pool_wait_count_total", "Total number of connections waited for.", nil, nil),
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return float64(s.WaitCount) },
+	},
+	{
+		prometheus.NewDesc("db_pool_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", nil, nil),
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() },
+	},
+	{
+		prometheus.NewDesc("db_pool_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.", nil, nil),
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) },
+	},
+	{
+		prometheus.NewDesc("db_pool_max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime.", nil, nil),
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) },
+	},
+	{
+		prometheus.NewDesc("db_pool_max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.", nil, nil),
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) },
+	},
+}
+
+// NewDBStatsCollector creates a collector for db's pool and registers it with the default registry
+func NewDBStatsCollector(db *sql.DB) *DBStatsCollector {
+	c := &DBStatsCollector{db: db}
+	Default.MustRegister(c)
+	return c
+}
+
+// Describe implements prometheus.Collector
+func (c *DBStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, stat := range dbStats {
+		ch <- stat.desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *DBStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+	for _, stat := range dbStats {
+		ch <- prometheus.MustNewConstMetric(stat.desc, stat.valueType, stat.value(stats))
+	}
+}
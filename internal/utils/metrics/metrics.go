@@ -0,0 +1,83 @@
+// Package metrics registers the application's Prometheus instrumentation: labelled
+// counters and histograms, connection pool statistics and the Go runtime and process
+// collectors, served in the Prometheus exposition formats.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultBuckets are latency buckets in seconds suited to HTTP and upstream calls
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default is the registry the application's collectors register with
+var Default = newRegistry()
+
+// newRegistry creates a registry holding the Go runtime and process collectors
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Handler serves the metrics of the default registry, negotiating the exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Default, promhttp.HandlerOpts{})
+}
+
+// CounterVec is a monotonically increasing value partitioned by labels
+type CounterVec struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounterVec creates a counter and registers it with the default registry
+func NewCounterVec(name string, help string, labelNames ...string) *CounterVec {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labelNames)
+	Default.MustRegister(vec)
+	// An unlabelled counter is reported as zero before its first update
+	if len(labelNames) == 0 {
+		vec.WithLabelValues()
+	}
+	return &CounterVec{vec: vec}
+}
+
+// Inc adds one to the counter for the given label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.vec.WithLabelValues(labelValues...).Inc()
+}
+
+// Add adds a non-negative delta to the counter for the given label values
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.vec.WithLabelValues(labelValues...).Add(delta)
+}
+
+// HistogramVec counts observations into buckets, partitioned by labels
+type HistogramVec struct {
+	vec *prometheus.HistogramVec
+}
+
+// NewHistogramVec creates a histogram and registers it with the default registry;
+// buckets are upper bounds in increasing order, and nil selects DefaultBuckets
+func NewHistogramVec(name string, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labelNames)
+	Default.MustRegister(vec)
+	return &HistogramVec{vec: vec}
+}
+
+// Observe records a value for the given label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(value)
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// scrape returns the lines of the text exposition of the default registry describing name
+func scrape(t *testing.T, name string) []string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "text/plain")
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d, want 200", w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the text exposition format", contentType)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, name) || strings.HasPrefix(line, "# HELP "+name+" ") || strings.HasPrefix(line, "# TYPE "+name+" ") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// assertLines fails unless got equals want line by line
+func assertLines(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("exposition =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCounterExposition(t *testing.T) {
+	counter := NewCounterVec("test_escaped_total", "Requests by \\ path\nand status.", "path", "status")
+	counter.Inc(`/a"b\c`+"\n", "200")
+	counter.Add(2, "/brands", "200")
+	counter.Add(-1, "/brands", "200")
+
+	assertLines(t, scrape(t, "test_escaped_total"),
+		`# HELP test_escaped_total Requests by \\ path\nand status.`,
+		`# TYPE test_escaped_total counter`,
+		`test_escaped_total{path="/a\"b\\c\n",status="200"} 1`,
+		`test_escaped_total{path="/brands",status="200"} 2`,
+	)
+}
+
+func TestUnlabelledCounterStartsAtZero(t *testing.T) {
+	NewCounterVec("test_unlabelled_total", "Unlabelled counter.")
+	assertLines(t, scrape(t, "test_unlabelled_total"),
+		`# HELP test_unlabelled_total Unlabelled counter.`,
+		`# TYPE test_unlabelled_total counter`,
+		`test_unlabelled_total 0`,
+	)
+}
+
+func TestHistogramBucketsAreCumulative(t *testing.T) {
+	histogram := NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "operation")
+	for _, value := range []float64{0.05, 0.1, 0.5, 5} {
+		histogram.Observe(value, "upload")
+	}
+
+	assertLines(t, scrape(t, "test_latency_seconds"),
+		`# HELP test_latency_seconds Latency.`,
+		`# TYPE test_latency_seconds histogram`,
+		`test_latency_seconds_bucket{operation="upload",le="0.1"} 2`,
+		`test_latency_seconds_bucket{operation="upload",le="1"} 3`,
+		`test_latency_seconds_bucket{operation="upload",le="+Inf"} 4`,
+		`test_latency_seconds_sum{operation="upload"} 5.65`,
+		`test_latency_seconds_count{operation="upload"} 4`,
+	)
+}
+
+func TestWrongLabelCountPanics(t *testing.T) {
+	counter := NewCounterVec("test_labels_total", "Labelled counter.", "operation")
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc() with a missing label value did not panic")
+		}
+	}()
+	counter.Inc()
+}
@@ -0,0 +1,125 @@
+// Package ratelimit implements token bucket rate limiting keyed by arbitrary strings,
+// backed by a pluggable Store.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate allows Limit requests per Period, refilled continuously
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// PerMinute returns a rate of n requests per minute
+func PerMinute(n int) Rate {
+	return Rate{Limit: n, Period: time.Minute}
+}
+
+// Result describes the outcome of taking a token
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining is the number of whole tokens left after this request
+	Remaining int
+	// RetryAfter is how long to wait before a token is available; zero when allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Store takes tokens from per-key buckets. MemoryStore keeps buckets in process;
+// implement Store on a shared backend to enforce limits across several instances.
+type Store interface {
+	Take(ctx context.Context, key string, rate Rate) (Result, error)
+}
+
+// bucket is the state of a single key
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// period is the refill period of the rate last applied, used to evict idle buckets
+	period time.Duration
+}
+
+// MemoryStore keeps buckets in process memory
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	// lastSweep is when idle buckets were last evicted
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take consumes a token from the bucket of key
+func (l *MemoryStore) Take(ctx context.Context, key string, rate Rate) (Result, error) {
+	if rate.Limit <= 0 || rate.Period <= 0 {
+		return Result{Allowed: true, Limit: rate.Limit}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Limit), updated: now}
+		l.buckets[key] = b
+	}
+	b.period = rate.Period
+
+	return take(b, rate, now), nil
+}
+
+// take refills a bucket for the elapsed time and consumes one token
+func take(b *bucket, rate Rate, now time.Time) Result {
+	perToken := rate.Period / time.Duration(rate.Limit)
+	capacity := float64(rate.Limit)
+
+	// Refill for the time elapsed since the last request
+	elapsed := now.Sub(b.updated)
+	if elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)/float64(perToken))
+		b.updated = now
+	}
+
+	result := Result{Limit: rate.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = time.Duration((capacity - b.tokens) * float64(perToken))
+	return result
+}
+
+// sweep evicts buckets idle long enough to have refilled completely; callers hold the lock
+func (l *MemoryStore) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) > b.period {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock is a clock tests move forward by hand
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestStore returns a store running on a clock tests control
+func newTestStore() (*MemoryStore, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestTake(t *testing.T) {
+	// 4 tokens a minute refill one every 15 seconds
+	rate := Rate{Limit: 4, Period: time.Minute}
+
+	type step struct {
+		advance time.Duration
+		want    Result
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "burst up to the limit",
+			steps: []step{
+				{want: Result{Allowed: true, Limit: 4, Remaining: 3, ResetAfter: 15 * time.Second}},
+				{want: Result{Allowed: true, Limit: 4, Remaining: 2, ResetAfter: 30 * time.Second}},
+				{want: Result{Allowed: true, Limit: 4, Remaining: 1, ResetAfter: 45 * time.Second}},
+				{want: Result{Allowed: true, Limit: 4, Remaining: 0, ResetAfter: time.Minute}},
+				{want: Result{Allowed: false, Limit: 4, Remaining: 0, RetryAfter: 15 * time.Second, ResetAfter: time.Minute}},
+			},
+		},
+		{
+			name: "retry after shrinks as tokens refill",
+			steps: []step{
+				{}, {}, {}, {},
+				{advance: 5 * time.Second, want: Result{Allowed: false, Limit: 4, RetryAfter: 10 * time.Second, ResetAfter: 55 * time.Second}},
+				{advance: 10 * time.Second, want: Result{Allowed: true, Limit: 4, ResetAfter: time.Minute}},
+				{want: Result{Allowed: false, Limit: 4, RetryAfter: 15 * time.Second, ResetAfter: time.Minute}},
+			},
+		},
+		{
+			name: "refill is capped at the limit",
+			steps: []step{
+				{}, {}, {}, {},
+				{advance: 10 * time.Minute, want: Result{Allowed: true, Limit: 4, Remaining: 3, ResetAfter: 15 * time.Second}},
+				{want: Result{Allowed: true, Limit: 4, Remaining: 2, ResetAfter: 30 * time.Second}},
+				{want: Result{Allowed: true, Limit: 4, Remaining: 1, ResetAfter: 45 * time.Second}},
+				{want: Result{Allowed: true, Limit: 4, Remaining: 0, ResetAfter: time.Minute}},
+				{want: Result{Allowed: false, Limit: 4, RetryAfter: 15 * time.Second, ResetAfter: time.Minute}},
+			},
+		},
+		{
+			name: "rejected requests do not consume tokens",
+			steps: []step{
+				{}, {}, {}, {},
+				{want: Result{Allowed: false, Limit: 4, RetryAfter: 15 * time.Second, ResetAfter: time.Minute}},
+				{want: Result{Allowed: false, Limit: 4, RetryAfter: 15 * time.Second, ResetAfter: time.Minute}},
+				{advance: 15 * time.Second, want: Result{Allowed: true, Limit: 4, ResetAfter: time.Minute}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestStore()
+			for i, s := range tt.steps {
+				clock.Advance(s.advance)
+				got, err := store.Take(context.Background(), "client", rate)
+				if err != nil {
+					t.Fatalf("Take() %d error = %v", i+1, err)
+				}
+				if s.want != (Result{}) && got != s.want {
+					t.Errorf("Take() %d = %+v, want %+v", i+1, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTakeKeysAreIndependent(t *testing.T) {
+	store, _ := newTestStore()
+	rate := Rate{Limit: 1, Period: time.Minute}
+	ctx := context.Background()
+
+	if result, _ := store.Take(ctx, "a", rate); !result.Allowed {
+		t.Fatal("first request of a was rejected")
+	}
+	if result, _ := store.Take(ctx, "a", rate); result.Allowed {
+		t.Fatal("second request of a was allowed")
+	}
+	if result, _ := store.Take(ctx, "b", rate); !result.Allowed {
+		t.Error("b was limited by the requests of a")
+	}
+}
+
+func TestTakeWithoutLimit(t *testing.T) {
+	store, _ := newTestStore()
+	for _, rate := range []Rate{{}, {Limit: 10}, {Period: time.Minute}} {
+		for i := 0; i < 100; i++ {
+			if result, _ := store.Take(context.Background(), "client", rate); !result.Allowed {
+				t.Fatalf("Take() with rate %+v rejected request %d", rate, i+1)
+			}
+		}
+	}
+}
+
+func TestSweepEvictsIdleBuckets(t *testing.T) {
+	store, clock := newTestStore()
+	rate := Rate{Limit: 2, Period: time.Minute}
+	ctx := context.Background()
+
+	_, _ = store.Take(ctx, "idle", rate)
+	clock.Advance(30 * time.Second)
+	_, _ = store.Take(ctx, "busy", rate)
+
+	// After a full period only the bucket of the idle key has refilled completely
+	clock.Advance(45 * time.Second)
+	_, _ = store.Take(ctx, "busy", rate)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("idle bucket was not evicted")
+	}
+	if _, ok := store.buckets["busy"]; !ok {
+		t.Error("busy bucket was evicted")
+	}
+}
+
+func TestTakeConcurrently(t *testing.T) {
+	store, _ := newTestStore()
+	rate := Rate{Limit: 50, Period: time.Hour}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	count := 0
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := store.Take(context.Background(), "client", rate)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result.Allowed {
+				mu.Lock()
+				count++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if count != rate.Limit {
+		t.Errorf("%d concurrent requests were allowed, want %d", count, rate.Limit)
+	}
+}
@@ -0,0 +1,50 @@
+// Package requestid carries the correlation ID of a request through its context.
+package requestid
+
+import (
+	"context"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Header is the HTTP header a request ID is read from and echoed in
+const Header = "X-Request-ID"
+
+// maxLength bounds client-supplied IDs so they can't bloat logs
+const maxLength = 128
+
+// contextKey is the context key holding the request ID
+type contextKey struct{}
+
+// New generates a request ID
+func New() string {
+	return ulid.Make().String()
+}
+
+// Valid reports whether a client-supplied request ID is safe to propagate
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		// Printable ASCII only, so IDs can't forge log lines or headers
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "" when there is none
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
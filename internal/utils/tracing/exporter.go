@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+)
+
+// NewWriterExporter creates an exporter writing each span to w, such as os.Stdout, as a line of JSON
+func NewWriterExporter(w io.Writer) (Exporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(w))
+}
+
+// NewFileExporter creates an exporter appending spans to the file at path as lines of JSON
+func NewFileExporter(path string) (Exporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+	exporter, err := NewWriterExporter(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &fileExporter{Exporter: exporter, file: file}, nil
+}
+
+// NewOTLPExporter creates an exporter posting protobuf encoded spans to endpoint,
+// e.g. http://localhost:4318/v1/traces
+func NewOTLPExporter(endpoint string, headers map[string]string) Exporter {
+	return otlptracehttp.NewUnstarted(
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithHeaders(headers),
+	)
+}
+
+// fileExporter closes its file once the exporter is shut down
+type fileExporter struct {
+	Exporter
+	file *os.File
+}
+
+// Shutdown implements Exporter
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.file.Close())
+}
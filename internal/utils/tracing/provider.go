@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporter sends batches of ended spans to a backend
+type Exporter = sdktrace.SpanExporter
+
+// Provider batches ended spans and hands them to an exporter in the background
+type Provider = sdktrace.TracerProvider
+
+// ProviderOptions configures a Provider
+type ProviderOptions struct {
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName string
+	// SampleRatio is the fraction of new traces recorded; traces continued from a
+	// remote parent follow the parent's sampling decision
+	SampleRatio float64
+	// BatchSize is the number of spans exported at once
+	BatchSize int
+	// BatchTimeout is the longest a span waits in the queue before export
+	BatchTimeout time.Duration
+	// QueueSize bounds the spans waiting for export; spans beyond it are dropped
+	QueueSize int
+	// OnError receives export failures
+	OnError func(error)
+}
+
+// NewProvider creates a provider exporting through exporter in batches
+func NewProvider(exporter Exporter, options ProviderOptions) *Provider {
+	var batch []sdktrace.BatchSpanProcessorOption
+	if options.BatchSize > 0 {
+		batch = append(batch, sdktrace.WithMaxExportBatchSize(options.BatchSize))
+	}
+	if options.BatchTimeout > 0 {
+		batch = append(batch, sdktrace.WithBatchTimeout(options.BatchTimeout))
+	}
+	if options.QueueSize > 0 {
+		batch = append(batch, sdktrace.WithMaxQueueSize(options.QueueSize))
+	}
+	if options.OnError != nil {
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(options.OnError))
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, batch...),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(String("service.name", options.ServiceName))),
+	)
+}
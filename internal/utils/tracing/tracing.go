@@ -0,0 +1,109 @@
+// Package tracing is the application's entry point to OpenTelemetry tracing: it
+// starts spans from the global tracer provider, propagates W3C trace context and
+// builds the SDK provider and exporters selected by configuration.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// scopeName identifies this instrumentation in exported spans
+const scopeName = "beautyessentials.com/internal/utils/tracing"
+
+// Span records one timed operation. Spans started while tracing is disabled or
+// not sampled record nothing, so callers never need to check.
+type Span = trace.Span
+
+// SpanContext is the part of a span that is propagated to children and other services
+type SpanContext = trace.SpanContext
+
+// SpanKind describes the relationship of a span to its parent
+type SpanKind = trace.SpanKind
+
+const (
+	KindInternal = trace.SpanKindInternal
+	KindServer   = trace.SpanKindServer
+	KindClient   = trace.SpanKindClient
+)
+
+// StatusCode is the outcome of a span
+type StatusCode = codes.Code
+
+const (
+	StatusUnset = codes.Unset
+	StatusOK    = codes.Ok
+	StatusError = codes.Error
+)
+
+// Attribute is a key/value pair attached to a span or event
+type Attribute = attribute.KeyValue
+
+// String creates a string attribute
+func String(key string, value string) Attribute {
+	return attribute.String(key, value)
+}
+
+// Int creates an integer attribute
+func Int(key string, value int) Attribute {
+	return attribute.Int(key, value)
+}
+
+// Int64 creates an integer attribute
+func Int64(key string, value int64) Attribute {
+	return attribute.Int64(key, value)
+}
+
+// Bool creates a boolean attribute
+func Bool(key string, value bool) Attribute {
+	return attribute.Bool(key, value)
+}
+
+// StartOption configures a span created by Start
+type StartOption = trace.SpanStartOption
+
+// WithKind sets the span kind; spans are internal by default
+func WithKind(kind SpanKind) StartOption {
+	return trace.WithSpanKind(kind)
+}
+
+// WithAttributes sets attributes on the new span
+func WithAttributes(attributes ...Attribute) StartOption {
+	return trace.WithAttributes(attributes...)
+}
+
+// SetProvider installs the provider used by Start; nil disables tracing
+func SetProvider(p *Provider) {
+	if p == nil {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return
+	}
+	otel.SetTracerProvider(p)
+}
+
+// Start creates a span as a child of the span or remote parent in ctx and returns
+// a context carrying it. Spans are only recorded when a provider is installed.
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, Span) {
+	return otel.Tracer(scopeName).Start(ctx, name, opts...)
+}
+
+// SpanFromContext returns the current span, a non-recording one when there is none
+func SpanFromContext(ctx context.Context) Span {
+	return trace.SpanFromContext(ctx)
+}
+
+// SpanContextFromContext returns the identity of the current span, falling back
+// to a remote parent extracted from an incoming request
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return trace.SpanContextFromContext(ctx)
+}
+
+// ContextWithRemoteSpanContext returns a context whose next span continues the remote trace
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
@@ -0,0 +1,252 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+// installProvider installs a provider sampling ratio of new traces into an in-memory exporter
+func installProvider(t *testing.T, ratio float64) (*Provider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(exporter, ProviderOptions{ServiceName: "test", SampleRatio: ratio})
+	SetProvider(provider)
+	t.Cleanup(func() {
+		SetProvider(nil)
+		_ = provider.Shutdown(context.Background())
+	})
+	return provider, exporter
+}
+
+// ended flushes provider and returns the spans it exported
+func ended(t *testing.T, provider *Provider, exporter *tracetest.InMemoryExporter) tracetest.SpanStubs {
+	t.Helper()
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+	return exporter.GetSpans()
+}
+
+func TestStartLinksChildrenToTheirParent(t *testing.T) {
+	provider, exporter := installProvider(t, 1)
+
+	ctx, parent := Start(context.Background(), "GET /brands", WithKind(KindServer))
+	childCtx, child := Start(ctx, "BrandRepository.GetAllBrands", WithAttributes(String("db.system", "postgresql")))
+	if got := SpanContextFromContext(childCtx); got.SpanID() != child.SpanContext().SpanID() {
+		t.Errorf("SpanContextFromContext() = %s, want the child %s", got.SpanID(), child.SpanContext().SpanID())
+	}
+	child.End()
+	parent.End()
+
+	spans := ended(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	childSpan, parentSpan := spans[0], spans[1]
+	if childSpan.SpanContext.TraceID() != parentSpan.SpanContext.TraceID() {
+		t.Error("child started a trace of its own")
+	}
+	if childSpan.Parent.SpanID() != parentSpan.SpanContext.SpanID() {
+		t.Errorf("child parent = %s, want %s", childSpan.Parent.SpanID(), parentSpan.SpanContext.SpanID())
+	}
+	if parentSpan.Parent.IsValid() {
+		t.Errorf("root span has parent %s", parentSpan.Parent.SpanID())
+	}
+	if parentSpan.SpanKind != KindServer || childSpan.SpanKind != KindInternal {
+		t.Errorf("kinds = %s and %s, want server and internal", parentSpan.SpanKind, childSpan.SpanKind)
+	}
+}
+
+func TestStartWithoutProviderRecordsNothing(t *testing.T) {
+	SetProvider(nil)
+	ctx, span := Start(context.Background(), "GET /brands")
+	defer span.End()
+	if span.IsRecording() {
+		t.Error("span is recorded without a provider")
+	}
+	if SpanContextFromContext(ctx).IsValid() {
+		t.Error("span without a provider has an identity to propagate")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		wantValid   bool
+		wantSampled bool
+	}{
+		{"sampled", "00-" + testTraceID + "-" + testSpanID + "-01", true, true},
+		{"not sampled", "00-" + testTraceID + "-" + testSpanID + "-00", true, false},
+		{"future version with more fields", "01-" + testTraceID + "-" + testSpanID + "-01-extra", true, true},
+		{"missing", "", false, false},
+		{"invalid version", "ff-" + testTraceID + "-" + testSpanID + "-01", false, false},
+		{"version 00 with more fields", "00-" + testTraceID + "-" + testSpanID + "-01-extra", false, false},
+		{"short trace ID", "00-" + testTraceID[2:] + "-" + testSpanID + "-01", false, false},
+		{"zero trace ID", "00-00000000000000000000000000000000-" + testSpanID + "-01", false, false},
+		{"zero span ID", "00-" + testTraceID + "-0000000000000000-01", false, false},
+		{"not hex", "00-" + testTraceID[:31] + "z-" + testSpanID + "-01", false, false},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01", false, false},
+		{"missing flags", "00-" + testTraceID + "-" + testSpanID, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.traceparent != "" {
+				header.Set(TraceParentHeader, tt.traceparent)
+			}
+			sc := SpanContextFromContext(Extract(context.Background(), header))
+			if sc.IsValid() != tt.wantValid {
+				t.Fatalf("Extract(%q) valid = %v, want %v", tt.traceparent, sc.IsValid(), tt.wantValid)
+			}
+			if !tt.wantValid {
+				return
+			}
+			if sc.TraceID().String() != testTraceID || sc.SpanID().String() != testSpanID {
+				t.Errorf("Extract() = %s %s, want %s %s", sc.TraceID(), sc.SpanID(), testTraceID, testSpanID)
+			}
+			if sc.IsSampled() != tt.wantSampled || !sc.IsRemote() {
+				t.Errorf("Extract() sampled = %v remote = %v, want %v and remote", sc.IsSampled(), sc.IsRemote(), tt.wantSampled)
+			}
+		})
+	}
+}
+
+func TestInjectContinuesTheIncomingTrace(t *testing.T) {
+	installProvider(t, 1)
+
+	incoming := http.Header{}
+	incoming.Set(TraceParentHeader, "00-"+testTraceID+"-"+testSpanID+"-01")
+	ctx, span := Start(Extract(context.Background(), incoming), "imagekit upload", WithKind(KindClient))
+	defer span.End()
+
+	outgoing := http.Header{}
+	Inject(ctx, outgoing)
+	want := "00-" + testTraceID + "-" + span.SpanContext().SpanID().String() + "-01"
+	if got := outgoing.Get(TraceParentHeader); got != want {
+		t.Errorf("traceparent = %q, want %q", got, want)
+	}
+
+	// Nothing is injected outside a trace
+	empty := http.Header{}
+	Inject(context.Background(), empty)
+	if got := empty.Get(TraceParentHeader); got != "" {
+		t.Errorf("traceparent without a span = %q, want none", got)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	remote := func(flags trace.TraceFlags) context.Context {
+		traceID, _ := trace.TraceIDFromHex(testTraceID)
+		spanID, _ := trace.SpanIDFromHex(testSpanID)
+		return ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID, SpanID: spanID, TraceFlags: flags, Remote: true,
+		}))
+	}
+
+	tests := []struct {
+		name          string
+		ratio         float64
+		ctx           context.Context
+		wantRecording bool
+	}{
+		{"new trace, everything sampled", 1, context.Background(), true},
+		{"new trace, nothing sampled", 0, context.Background(), false},
+		{"sampled caller overrides the ratio", 0, remote(trace.FlagsSampled), true},
+		{"unsampled caller overrides the ratio", 1, remote(0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := installProvider(t, tt.ratio)
+			_, span := Start(tt.ctx, "GET /brands")
+			if span.IsRecording() != tt.wantRecording || span.SpanContext().IsSampled() != tt.wantRecording {
+				t.Errorf("recording = %v sampled = %v, want %v", span.IsRecording(), span.SpanContext().IsSampled(), tt.wantRecording)
+			}
+			span.End()
+			if spans := ended(t, provider, exporter); (len(spans) == 1) != tt.wantRecording {
+				t.Errorf("exported %d spans, want recorded = %v", len(spans), tt.wantRecording)
+			}
+		})
+	}
+}
+
+func TestOTLPExporterPayload(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*collectortrace.ExportTraceServiceRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := &collectortrace.ExportTraceServiceRequest{}
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Authorization") != "Bearer token" || proto.Unmarshal(body, request) != nil {
+			http.Error(w, "bad export request", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL+"/v1/traces", map[string]string{"Authorization": "Bearer token"})
+	provider := NewProvider(exporter, ProviderOptions{ServiceName: "beautyessentials-api", SampleRatio: 1})
+	tracer := provider.Tracer(scopeName)
+	ctx, parent := tracer.Start(context.Background(), "GET /brands", WithKind(KindServer))
+	_, child := tracer.Start(ctx, "db.select", WithAttributes(Int("http.response.status_code", 200)))
+	child.RecordError(io.ErrUnexpectedEOF)
+	child.SetStatus(StatusError, "query failed")
+	child.End()
+	parent.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 1 || len(requests[0].ResourceSpans) != 1 {
+		t.Fatalf("collector received %d requests, want one with one resource", len(requests))
+	}
+	resource := requests[0].ResourceSpans[0]
+	if attrs := resource.Resource.Attributes; len(attrs) != 1 || attrs[0].Key != "service.name" || attrs[0].Value.GetStringValue() != "beautyessentials-api" {
+		t.Errorf("resource attributes = %v, want the service name", attrs)
+	}
+	if len(resource.ScopeSpans) != 1 || resource.ScopeSpans[0].Scope.Name != scopeName {
+		t.Fatalf("scope spans = %v, want one scope named %s", resource.ScopeSpans, scopeName)
+	}
+	spans := resource.ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	dbSpan, serverSpan := spans[0], spans[1]
+	if hex.EncodeToString(dbSpan.ParentSpanId) != hex.EncodeToString(serverSpan.SpanId) || len(serverSpan.ParentSpanId) != 0 {
+		t.Errorf("parent IDs = %x and %x, want the server span %x and none", dbSpan.ParentSpanId, serverSpan.ParentSpanId, serverSpan.SpanId)
+	}
+	if serverSpan.Kind.String() != "SPAN_KIND_SERVER" || serverSpan.Name != "GET /brands" {
+		t.Errorf("server span = %s %s, want SPAN_KIND_SERVER GET /brands", serverSpan.Kind, serverSpan.Name)
+	}
+	if attrs := dbSpan.Attributes; len(attrs) != 1 || attrs[0].Value.GetIntValue() != 200 {
+		t.Errorf("db span attributes = %v, want the status code as an integer", attrs)
+	}
+	if dbSpan.Status.Code.String() != "STATUS_CODE_ERROR" || dbSpan.Status.Message != "query failed" {
+		t.Errorf("db span status = %v, want the error", dbSpan.Status)
+	}
+	if len(dbSpan.Events) != 1 || dbSpan.Events[0].Name != "exception" {
+		t.Errorf("db span events = %v, want the recorded exception", dbSpan.Events)
+	}
+}